@@ -11,41 +11,76 @@ import (
 	"time"
 
 	"github.com/actatum/approved-ball-list/internal/balls"
+	"github.com/actatum/approved-ball-list/internal/config"
 	"github.com/actatum/approved-ball-list/internal/crdb"
 	"github.com/actatum/approved-ball-list/internal/log"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/actatum/approved-ball-list/internal/sqlite"
 )
 
 func main() {
 	var (
-		cockroachURL = flag.String("crdb-url", lookupEnv("COCKROACHDB_URL", ""), "cockroachdb url")
+		configPath   = flag.String("config", lookupEnv("CONFIG_FILE", ""), "path to a yaml or toml config file")
+		cockroachURL = flag.String("crdb-url", "", "database url, sqlite:// urls use sqlite and any other cockroachdb or postgres")
 		timeout      = flag.Duration("timeout", 1*time.Minute, "max duration before process shuts down")
 	)
 	flag.Parse()
 
 	logger := log.NewLogger(os.Stderr, log.WithFmtLog())
 
-	var db *pgxpool.Pool
-	{
-		var err error
-		db, err = crdb.NewDB(*cockroachURL)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		logger.Error("error loading config", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if *cockroachURL != "" {
+		cfg.Database.URL = *cockroachURL
+	}
+
+	var store balls.Store
+	switch cfg.Database.Driver() {
+	case config.StoreSQLite:
+		db, err := sqlite.NewDB(cfg.Database.URL)
+		if err != nil {
+			logger.Error("error opening sqlite database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		store = balls.NewSQLiteStore(db)
+
+	default:
+		db, err := crdb.NewDB(cfg.Database.URL)
 		if err != nil {
-			logger.Error("error connecting to cockroachdb", slog.Any("error", err))
+			logger.Error("error connecting to database", slog.Any("error", err))
 			os.Exit(1)
 		}
 		defer db.Close()
+
+		store = balls.NewCRDBStore(db)
 	}
 
-	store := balls.NewCRDBStore(db)
 	notifier := balls.LocalNotifier{}
-	usbcService := balls.NewHTTPUSBCService(&http.Client{}, logger)
-	service := balls.NewService(logger, store, usbcService, notifier)
+	canonicalizer, err := cfg.Names.Canonicalizer()
+	if err != nil {
+		logger.Error("error creating name canonicalizer", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	usbcService := balls.NewHTTPUSBCService(
+		&http.Client{Timeout: cfg.USBC.Timeout},
+		logger,
+		balls.WithUSBCCanonicalizer(canonicalizer),
+	)
+	service := balls.NewService(logger, store, usbcService, notifier,
+		balls.WithWorkers(cfg.Runs.Workers),
+		balls.WithCanonicalizer(canonicalizer),
+	)
 
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
 	start := time.Now()
-	err := service.CheckForNewlyApprovedBalls(ctx)
+	err = service.CheckForNewlyApprovedBalls(ctx)
 	if err != nil {
 		logger.ErrorContext(ctx, "error checking for newly approved balls", slog.Any("error", err))
 		os.Exit(1)
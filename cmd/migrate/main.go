@@ -0,0 +1,405 @@
+// Package main is the entrypoint for the migration utility, which applies the migrations embedded in the service to
+// its database.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/actatum/approved-ball-list/internal/config"
+	"github.com/actatum/approved-ball-list/internal/crdb"
+	"github.com/actatum/approved-ball-list/internal/sqlite"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+const usage = `Usage: %s [flags] <command>
+
+Commands:
+  status     print the current schema version and any pending migrations
+  up         apply all pending migrations
+  down N     roll back the last N migrations
+  goto V     migrate up or down to version V
+  force V    set the schema version to V without running any migrations, clearing the dirty flag
+
+With -dry-run up, down and goto print the migrations they would run without running them.
+
+Flags:
+`
+
+func main() {
+	var (
+		configPath  = flag.String("config", lookupEnv("CONFIG_FILE", ""), "path to a yaml or toml config file")
+		databaseURL = flag.String("database-url", "", "database url, sqlite:// urls use sqlite and any other cockroachdb or postgres")
+		dryRun      = flag.Bool("dry-run", false, "print the migrations up, down and goto would run without running them")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	if *databaseURL != "" {
+		cfg.Database.URL = *databaseURL
+	}
+
+	m, err := openMigrator(cfg.Database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	m.dryRun = *dryRun
+
+	err = run(m, os.Stdout, flag.Args())
+	m.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if errors.Is(err, errUsage) {
+			flag.Usage()
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+var errUsage = errors.New("invalid usage")
+
+// migrator applies the embedded migrations of a database.
+type migrator struct {
+	*migrate.Migrate
+	// src lists the embedded migrations.
+	src source.Driver
+	// latest is the schema version expected by this build.
+	latest uint
+	// dryRun prints the migrations up, down and goto would run instead of running them.
+	dryRun bool
+	close  func()
+}
+
+func (m *migrator) Close() {
+	m.src.Close()
+	m.Migrate.Close()
+	m.close()
+}
+
+func openMigrator(cfg config.DatabaseConfig) (*migrator, error) {
+	switch cfg.Driver() {
+	case config.StoreSQLite:
+		db, err := sqlite.Open(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		src, err := sqlite.MigrationSource()
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		m, err := sqlite.NewMigrator(db)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		return &migrator{
+			Migrate: m,
+			src:     src,
+			latest:  sqlite.MigrationVersion,
+			close:   func() {},
+		}, nil
+
+	case config.StoreCRDB:
+		ctx := context.Background()
+
+		db, err := crdb.Open(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		dialect, err := crdb.DetectDialect(ctx, db)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		src, err := crdb.MigrationSource(dialect)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		m, err := crdb.NewMigrator(ctx, db)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+
+		return &migrator{
+			Migrate: m,
+			src:     src,
+			latest:  crdb.MigrationVersion,
+			close:   db.Close,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("store %s has no migrations", cfg.Driver())
+	}
+}
+
+// run runs the command in args, writing its output to w.
+func run(m *migrator, w io.Writer, args []string) error {
+	cmd, args := args[0], args[1:]
+
+	switch cmd {
+	case "status":
+		if len(args) != 0 {
+			return fmt.Errorf("%w: status takes no arguments", errUsage)
+		}
+		return status(m, w)
+
+	case "up":
+		if len(args) != 0 {
+			return fmt.Errorf("%w: up takes no arguments", errUsage)
+		}
+		if m.dryRun {
+			return dryRun(m, w, func(versions, _ []uint) uint { return versions[len(versions)-1] })
+		}
+		return report(m, w, m.Up())
+
+	case "down":
+		n, err := intArg(cmd, args)
+		if err != nil {
+			return err
+		}
+		if n < 1 {
+			return fmt.Errorf("%w: down requires a positive number of migrations", errUsage)
+		}
+		if m.dryRun {
+			return dryRun(m, w, func(_, applied []uint) uint {
+				if n >= len(applied) {
+					return 0
+				}
+				return applied[len(applied)-n-1]
+			})
+		}
+		return report(m, w, m.Steps(-n))
+
+	case "goto":
+		v, err := intArg(cmd, args)
+		if err != nil {
+			return err
+		}
+		if v < 1 {
+			return fmt.Errorf("%w: goto requires a positive version, use down to roll back every migration", errUsage)
+		}
+		if m.dryRun {
+			return dryRun(m, w, func(_, _ []uint) uint { return uint(v) })
+		}
+		return report(m, w, m.Migrate.Migrate(uint(v)))
+
+	case "force":
+		v, err := intArg(cmd, args)
+		if err != nil {
+			return err
+		}
+		if v < -1 {
+			return fmt.Errorf("%w: force requires a version, or -1 for no version", errUsage)
+		}
+		return report(m, w, m.Force(v))
+
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, cmd)
+	}
+}
+
+func intArg(cmd string, args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%w: %s takes exactly one argument", errUsage, cmd)
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", errUsage, cmd, err)
+	}
+
+	return n, nil
+}
+
+// report prints the schema version after a command, treating no change as success.
+func report(m *migrator, w io.Writer, err error) error {
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Fprintln(w, "no change")
+	}
+
+	return status(m, w)
+}
+
+func status(m *migrator, w io.Writer) error {
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Fprintln(w, "version: none")
+	} else {
+		fmt.Fprintf(w, "version: %d\n", version)
+	}
+	fmt.Fprintf(w, "dirty: %t\n", dirty)
+	fmt.Fprintf(w, "expected: %d\n", m.latest)
+
+	pending, err := pendingMigrations(m.src, version)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "pending: %d\n", len(pending))
+	for _, p := range pending {
+		fmt.Fprintf(w, "  %s\n", p)
+	}
+
+	return nil
+}
+
+// dryRun prints the files of the migrations that would be run to reach the version returned by target, which is
+// passed every version and the versions already applied, in order.
+func dryRun(m *migrator, w io.Writer, target func(versions, applied []uint) uint) error {
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty, force a version first", version)
+	}
+
+	versions, err := migrationVersions(m.src)
+	if err != nil {
+		return err
+	}
+	var applied []uint
+	for _, v := range versions {
+		if v <= version {
+			applied = append(applied, v)
+		}
+	}
+
+	to := target(versions, applied)
+
+	var files []string
+	switch {
+	case to > version:
+		for _, v := range versions {
+			if v > version && v <= to {
+				files = append(files, migrationFile(m.src, v, "up"))
+			}
+		}
+	case to < version:
+		for i := len(applied) - 1; i >= 0 && applied[i] > to; i-- {
+			files = append(files, migrationFile(m.src, applied[i], "down"))
+		}
+	}
+
+	if len(files) == 0 {
+		fmt.Fprintln(w, "no change")
+		return nil
+	}
+	fmt.Fprintf(w, "would run %d migrations from version %d to %d:\n", len(files), version, to)
+	for _, f := range files {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+
+	return nil
+}
+
+// migrationVersions returns the versions of the embedded migrations in order.
+func migrationVersions(src source.Driver) ([]uint, error) {
+	next, err := src.First()
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	var versions []uint
+	for {
+		versions = append(versions, next)
+
+		next, err = src.Next(next)
+		if errors.Is(err, fs.ErrNotExist) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading migrations: %w", err)
+		}
+	}
+}
+
+// migrationFile returns the name of the file of migration version in direction, up or down, which is named
+// like 001_initial_setup.up.sql.
+func migrationFile(src source.Driver, version uint, direction string) string {
+	read := src.ReadUp
+	if direction == "down" {
+		read = src.ReadDown
+	}
+
+	r, identifier, err := read(version)
+	if err != nil {
+		return fmt.Sprintf("%03d (no %s migration)", version, direction)
+	}
+	r.Close()
+
+	return fmt.Sprintf("%03d_%s.%s.sql", version, identifier, direction)
+}
+
+// pendingMigrations returns the names of the migrations after version, every migration when version is 0.
+func pendingMigrations(src source.Driver, version uint) ([]string, error) {
+	next, err := src.First()
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	var pending []string
+	for {
+		if next > version {
+			r, identifier, err := src.ReadUp(next)
+			if err != nil {
+				return nil, fmt.Errorf("reading migration %d: %w", next, err)
+			}
+			r.Close()
+			pending = append(pending, fmt.Sprintf("%d_%s", next, identifier))
+		}
+
+		next, err = src.Next(next)
+		if errors.Is(err, fs.ErrNotExist) {
+			return pending, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading migrations: %w", err)
+		}
+	}
+}
+
+func lookupEnv(key string, defaultValue string) string {
+	if val, ok := os.LookupEnv(key); ok {
+		return val
+	}
+
+	return defaultValue
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/actatum/approved-ball-list/internal/config"
+	"github.com/actatum/approved-ball-list/internal/sqlite"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+func Test_run_dryRun(t *testing.T) {
+	m, err := openMigrator(config.DatabaseConfig{URL: "sqlite://" + filepath.Join(t.TempDir(), "abl.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(m.Close)
+
+	dryRun := func(args ...string) []string {
+		t.Helper()
+
+		m.dryRun = true
+		defer func() { m.dryRun = false }()
+
+		var out bytes.Buffer
+		if err := run(m, &out, args); err != nil {
+			t.Fatal(err)
+		}
+
+		return strings.Split(strings.TrimSpace(out.String()), "\n")
+	}
+
+	t.Run("up from nothing", func(t *testing.T) {
+		lines := dryRun("up")
+		if len(lines) != sqlite.MigrationVersion+1 || lines[1] != "  001_initial_setup.up.sql" {
+			t.Fatalf("expected every up migration got %q", lines)
+		}
+		if _, _, err := m.Version(); !errors.Is(err, migrate.ErrNilVersion) {
+			t.Fatalf("expected no migrations to be applied got %v", err)
+		}
+	})
+
+	if err := run(m, &bytes.Buffer{}, []string{"goto", "10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "goto later",
+			args: []string{"goto", "13"},
+			want: []string{"would run 3 migrations from version 10 to 13:", "  011_", "  012_", "  013_email_subscription_token_columns.up.sql"},
+		},
+		{
+			name: "down",
+			args: []string{"down", "2"},
+			want: []string{"would run 2 migrations from version 10 to 8:", "  010_", "  009_"},
+		},
+		{
+			name: "goto",
+			args: []string{"goto", "11"},
+			want: []string{"would run 1 migrations from version 10 to 11:", "  011_"},
+		},
+		{
+			name: "no change",
+			args: []string{"goto", "10"},
+			want: []string{"no change"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dryRun(tt.args...)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %q got %q", tt.want, got)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("line %d = %q, want prefix %q", i, got[i], tt.want[i])
+				}
+			}
+
+			version, _, err := m.Version()
+			if err != nil || version != 10 {
+				t.Fatalf("expected the schema to stay at version 10 got %d %v", version, err)
+			}
+		})
+	}
+}
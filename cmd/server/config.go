@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/actatum/approved-ball-list/internal/config"
+)
+
+// runConfigCommand runs the config subcommand and returns the process exit code.
+func runConfigCommand(cfg config.Config, args []string) int {
+	if len(args) != 1 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: config print")
+		return 2
+	}
+
+	data, err := cfg.Redacted().YAML()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error encoding config: %v\n", err)
+		return 1
+	}
+	fmt.Print(string(data))
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -2,71 +2,478 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/actatum/approved-ball-list/internal/balls"
+	"github.com/actatum/approved-ball-list/internal/config"
 	"github.com/actatum/approved-ball-list/internal/crdb"
 	"github.com/actatum/approved-ball-list/internal/log"
+	"github.com/actatum/approved-ball-list/internal/sqlite"
 	"github.com/bwmarrin/discordgo"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
 	var (
-		cockroachURL             = flag.String("crdb-url", lookupEnv("COCKROACHDB_URL", ""), "cockroachdb url")
-		discordChannels channels = strings.Split(lookupEnv("DISCORD_CHANNELS", ""), ",")
-		discordToken             = flag.String("discord-token", lookupEnv("DISCORD_TOKEN", ""), "discord bot token")
-		env                      = flag.String("env", lookupEnv("ENV", "local"), "environment service is running in")
-		port                     = flag.String("port", lookupEnv("PORT", "8080"), "http server port")
+		configPath      = flag.String("config", lookupEnv("CONFIG_FILE", ""), "path to a yaml or toml config file")
+		cockroachURL    = flag.String("crdb-url", "", "cockroachdb url")
+		databaseURL     = flag.String("database-url", "", "database url, sqlite:// urls use sqlite and any other cockroachdb or postgres")
+		autoMigrate     = flag.Bool("auto-migrate", true, "apply pending database migrations on startup")
+		storeKind       = flag.String("store", "", "store implementation, crdb, sqlite or memory, chosen by the database url when empty")
+		discordChannels channels
+		discordToken    = flag.String("discord-token", "", "discord bot token")
+		env             = flag.String("env", "", "environment service is running in")
+		gcpProject      = flag.String("gcp-project", "", "gcp project used to correlate logs with traces")
+		shutdownTimeout = flag.Duration("shutdown-timeout", 0, "max duration to wait for in flight requests to finish on shutdown")
+		maxRunAge       = flag.Duration("max-run-age", 0, "max age of the last successful run before the service reports as degraded, 0 disables")
+		port            = flag.String("port", "", "http server port")
+		schedule        = flag.String("schedule", "", "interval (e.g. 1h) or cron expression to check for newly approved balls in process, disabled when empty")
+		scheduleJitter  = flag.Duration("schedule-jitter", 0, "max random delay added to each scheduled run")
 	)
 	flag.Var(&discordChannels, "discord-channels", "discord channels to notify")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [config print | templates preview [digest | destination]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	logger := log.NewLogger(os.Stderr)
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
 
-	var db *pgxpool.Pool
-	{
-		var err error
-		db, err = crdb.NewDB(*cockroachURL)
+	// Flags set on the command line take precedence over the config file and environment.
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "crdb-url":
+			cfg.Database.URL = *cockroachURL
+		case "database-url":
+			cfg.Database.URL = *databaseURL
+		case "auto-migrate":
+			cfg.Database.AutoMigrate = *autoMigrate
+		case "store":
+			cfg.Database.Store = *storeKind
+		case "discord-channels":
+			cfg.Discord.Channels = discordChannels
+		case "discord-token":
+			cfg.Discord.Token = *discordToken
+		case "env":
+			cfg.Env = *env
+		case "gcp-project":
+			cfg.GCPProject = *gcpProject
+		case "shutdown-timeout":
+			cfg.HTTP.ShutdownTimeout = *shutdownTimeout
+		case "max-run-age":
+			cfg.Runs.MaxAge = *maxRunAge
+		case "port":
+			cfg.HTTP.Port = *port
+		case "schedule":
+			cfg.Runs.Schedule = *schedule
+		case "schedule-jitter":
+			cfg.Runs.ScheduleJitter = *scheduleJitter
+		}
+	})
+
+	switch flag.Arg(0) {
+	case "config":
+		os.Exit(runConfigCommand(cfg, flag.Args()[1:]))
+	case "templates":
+		os.Exit(runTemplatesCommand(cfg, flag.Args()[1:]))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	logger := log.NewLogger(os.Stderr, log.WithProjectID(cfg.GCPProject))
+
+	var (
+		store        balls.Store
+		healthChecks []balls.HealthCheck
+	)
+	switch cfg.Database.Driver() {
+	case config.StoreMemory:
+		store = balls.NewMemoryStore()
+
+	case config.StoreSQLite:
+		open := sqlite.Open
+		if cfg.Database.AutoMigrate {
+			open = sqlite.NewDB
+		}
+		db, err := open(cfg.Database.URL)
+		if err != nil {
+			logger.Error("error opening sqlite database", slog.Any("error", err))
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		if err := sqlite.VerifyMigrations(context.Background(), db); err != nil {
+			logger.Warn("database migrations not applied", slog.Any("error", err))
+		}
+
+		store = balls.NewSQLiteStore(db)
+		healthChecks = append(healthChecks,
+			balls.HealthCheck{
+				Name: "database",
+				Check: func(ctx context.Context) (string, error) {
+					return "", db.PingContext(ctx)
+				},
+			},
+			balls.HealthCheck{
+				Name: "migrations",
+				Check: func(ctx context.Context) (string, error) {
+					return "", sqlite.VerifyMigrations(ctx, db)
+				},
+			},
+		)
+
+	default:
+		open := crdb.Open
+		if cfg.Database.AutoMigrate {
+			open = crdb.NewDB
+		}
+		db, err := open(cfg.Database.URL)
 		if err != nil {
-			logger.Error("error connecting to cockroachdb", slog.Any("error", err))
+			logger.Error("error connecting to database", slog.Any("error", err))
 			os.Exit(1)
 		}
 		defer db.Close()
+
+		if err := crdb.VerifyMigrations(context.Background(), db); err != nil {
+			logger.Warn("database migrations not applied", slog.Any("error", err))
+		}
+
+		store = balls.NewCRDBStore(db)
+		healthChecks = append(healthChecks,
+			balls.HealthCheck{
+				Name: "database",
+				Check: func(ctx context.Context) (string, error) {
+					return "", db.Ping(ctx)
+				},
+			},
+			balls.HealthCheck{
+				Name: "migrations",
+				Check: func(ctx context.Context) (string, error) {
+					return "", crdb.VerifyMigrations(ctx, db)
+				},
+			},
+		)
 	}
 
-	store := balls.NewCRDBStore(db)
+	brandStyles, err := cfg.Brands.BrandStyles()
+	if err != nil {
+		logger.Error("error parsing brand styles", slog.Any("error", err))
+		os.Exit(1)
+	}
 
-	var notifier balls.Notifier
+	var (
+		notifier balls.Notifier
+		dg       *discordgo.Session
+		digests  []*balls.DigestNotifier
+	)
 	{
-		switch *env {
-		case "prod":
-			dg, err := discordgo.New(fmt.Sprintf("Bot %s", *discordToken))
+		// newNotifier returns a notifier sending to channels rendered with the templates of digest, empty for the
+		// instant notifications.
+		newNotifier := func(channels []string, digest string, opts ...balls.DiscordNotifierOption) balls.Notifier {
+			renderer, err := balls.NewRenderer(cfg.Discord.MessageTemplates(digest))
+			if err != nil {
+				logger.Error("error parsing templates", slog.String("digest", digest), slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			if dg == nil {
+				return balls.LocalNotifier{Renderer: renderer}
+			}
+			return balls.NewDiscordNotifier(dg, channels, append([]balls.DiscordNotifierOption{
+				balls.WithBatchSize(cfg.Discord.BatchSize),
+				balls.WithRenderer(renderer),
+				balls.WithDiscordLogger(logger),
+				balls.WithChannelStore(store),
+				balls.WithBrandStyles(brandStyles),
+			}, opts...)...)
+		}
+
+		if cfg.Env == "prod" && cfg.Discord.Token != "" {
+			dg, err = discordgo.New(fmt.Sprintf("Bot %s", cfg.Discord.Token))
 			if err != nil {
 				logger.Error("error creating discord client", slog.Any("error", err))
 				os.Exit(1)
 			}
 			defer dg.Close()
+		}
+
+		opts := []balls.DiscordNotifierOption{balls.WithThreadChannels(cfg.Discord.Threads)}
+		if cfg.Discord.Commands {
+			// Users subscribe with the slash commands, so there's nobody to message without them.
+			opts = append(opts, balls.WithDirectMessages())
+		}
+		notifier = newNotifier(cfg.Discord.Channels, "", opts...)
+
+		for _, d := range cfg.Discord.Digests {
+			sched, err := balls.ParseSchedule(d.Schedule)
+			if err != nil {
+				logger.Error("error parsing digest schedule", slog.String("digest", d.Name), slog.Any("error", err))
+				os.Exit(1)
+			}
+			digests = append(digests, balls.NewDigestNotifier(logger, d.Name, store, newNotifier(d.Channels, d.Name), sched))
+		}
+	}
+
+	notifiers := balls.MultiNotifier{notifier}
+	for _, d := range digests {
+		notifiers = append(notifiers, d)
+	}
+
+	// addDestination adds the notifier of a destination other than discord, accumulating its approvals in a digest
+	// when the destination has a digest schedule.
+	addDestination := func(destination string, digest config.DestinationDigestConfig, n balls.Notifier) {
+		if digest.Schedule == "" {
+			notifiers = append(notifiers, n)
+			return
+		}
+
+		name := digest.DigestName(destination)
+		sched, err := balls.ParseSchedule(digest.Schedule)
+		if err != nil {
+			logger.Error("error parsing digest schedule", slog.String("digest", name), slog.Any("error", err))
+			os.Exit(1)
+		}
+		d := balls.NewDigestNotifier(logger, name, store, n, sched)
+		digests = append(digests, d)
+		notifiers = append(notifiers, d)
+	}
+
+	// destinationRenderer returns the renderer of a destination other than discord.
+	destinationRenderer := func(path string, templates config.TemplatesConfig) *balls.Renderer {
+		d := config.Destination{Path: path, Templates: templates}
+		renderer, err := balls.NewRenderer(d.MessageTemplates(cfg.Discord))
+		if err != nil {
+			logger.Error("error parsing templates", slog.String("destination", path), slog.Any("error", err))
+			os.Exit(1)
+		}
+		return renderer
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	for i, w := range cfg.Webhooks {
+		opts := []balls.WebhookOption{
+			balls.WithWebhookBatchSize(w.BatchSize),
+			balls.WithRenderer(destinationRenderer(fmt.Sprintf("webhooks[%d]", i), w.Templates)),
+			balls.WithBrandStyles(brandStyles),
+		}
+		switch w.Type {
+		case config.WebhookTeams:
+			addDestination("", w.Digest, balls.NewTeamsNotifier(client, w.URL, opts...))
+		case config.WebhookGoogleChat:
+			addDestination("", w.Digest, balls.NewGoogleChatNotifier(client, w.URL, opts...))
+		}
+	}
+
+	var emailNotifier *balls.EmailNotifier
+	if cfg.Email.Enabled() {
+		smtp := cfg.Email.SMTP
+		opts := []balls.EmailOption{
+			balls.WithEmailRecipients(cfg.Email.To...),
+			balls.WithRenderer(destinationRenderer("email", cfg.Email.Templates)),
+			balls.WithBrandStyles(brandStyles),
+			balls.WithEmailLogger(logger),
+		}
+		if cfg.Email.Subscriptions {
+			opts = append(opts,
+				balls.WithEmailSubscriptions(store),
+				balls.WithEmailPublicURL(cfg.Email.PublicURL),
+			)
+		}
+		emailNotifier = balls.NewEmailNotifier(balls.SMTPServer{
+			Host:     smtp.Host,
+			Port:     smtp.Port,
+			Username: smtp.Username,
+			Password: smtp.Password,
+			Security: balls.SMTPSecurity(smtp.Security),
+		}, cfg.Email.From, opts...)
+		addDestination("email", cfg.Email.Digest, emailNotifier)
+	}
 
-			notifier = balls.NewDiscordNotifier(dg, discordChannels)
+	if cfg.Telegram.Enabled() {
+		addDestination("telegram", cfg.Telegram.Digest,
+			balls.NewTelegramNotifier(client, cfg.Telegram.Token, cfg.Telegram.ChatIDs,
+				balls.WithRenderer(destinationRenderer("telegram", cfg.Telegram.Templates)),
+				balls.WithBrandStyles(brandStyles),
+			))
+	}
+
+	if cfg.Matrix.Enabled() {
+		addDestination("matrix", cfg.Matrix.Digest, balls.NewMatrixNotifier(client,
+			cfg.Matrix.Homeserver, cfg.Matrix.AccessToken, cfg.Matrix.Rooms,
+			balls.WithRenderer(destinationRenderer("matrix", cfg.Matrix.Templates)),
+			balls.WithBrandStyles(brandStyles),
+		))
+	}
+
+	if cfg.Ntfy.Enabled() {
+		opts := []balls.NtfyOption{
+			balls.WithNtfyToken(cfg.Ntfy.Token),
+			balls.WithNtfyPriority(cfg.Ntfy.Priority),
+			balls.WithRenderer(destinationRenderer("ntfy", cfg.Ntfy.Templates)),
+			balls.WithBrandStyles(brandStyles),
+		}
+		if cfg.Ntfy.Server != "" {
+			opts = append(opts, balls.WithNtfyServer(cfg.Ntfy.Server))
+		}
+		addDestination("ntfy", cfg.Ntfy.Digest, balls.NewNtfyNotifier(client, cfg.Ntfy.Topic, opts...))
+	}
+
+	if cfg.Mastodon.Enabled() {
+		opts := []balls.MastodonOption{
+			balls.WithRenderer(destinationRenderer("mastodon", cfg.Mastodon.Templates)),
+			balls.WithBrandStyles(brandStyles),
+		}
+		if cfg.Mastodon.Visibility != "" {
+			opts = append(opts, balls.WithMastodonVisibility(cfg.Mastodon.Visibility))
+		}
+		addDestination("mastodon", cfg.Mastodon.Digest,
+			balls.NewMastodonNotifier(client, cfg.Mastodon.Server, cfg.Mastodon.AccessToken, opts...))
+	}
+
+	if cfg.Bluesky.Enabled() {
+		opts := []balls.BlueskyOption{
+			balls.WithRenderer(destinationRenderer("bluesky", cfg.Bluesky.Templates)),
+			balls.WithBrandStyles(brandStyles),
+		}
+		if cfg.Bluesky.Service != "" {
+			opts = append(opts, balls.WithBlueskyService(cfg.Bluesky.Service))
+		}
+		addDestination("bluesky", cfg.Bluesky.Digest,
+			balls.NewBlueskyNotifier(client, cfg.Bluesky.Identifier, cfg.Bluesky.AppPassword, opts...))
+	}
+
+	canonicalizer, err := cfg.Names.Canonicalizer()
+	if err != nil {
+		logger.Error("error creating name canonicalizer", slog.Any("error", err))
+		os.Exit(1)
+	}
 
-		default:
-			notifier = balls.LocalNotifier{}
+	usbcService := balls.NewHTTPUSBCService(
+		&http.Client{Timeout: cfg.USBC.Timeout},
+		logger,
+		balls.WithUSBCCanonicalizer(canonicalizer),
+	)
+	service := balls.NewService(logger, store, usbcService, notifiers,
+		balls.WithWorkers(cfg.Runs.Workers),
+		balls.WithCanonicalizer(canonicalizer),
+	)
+
+	go func() {
+		// Balls added before search existed are indexed in the background so they don't delay startup.
+		n, err := store.ReindexSearch(context.Background())
+		if err != nil {
+			logger.Error("error reindexing search", slog.Any("error", err))
+			return
 		}
+		if n > 0 {
+			logger.Info(fmt.Sprintf("indexed %d balls for search", n))
+		}
+	}()
+
+	// Command responses and the feeds render balls like the instant discord notifications.
+	renderer, err := balls.NewRenderer(cfg.Discord.MessageTemplates(""))
+	if err != nil {
+		logger.Error("error parsing templates", slog.Any("error", err))
+		os.Exit(1)
 	}
 
-	usbcService := balls.NewHTTPUSBCService(&http.Client{}, logger)
-	service := balls.NewService(logger, store, usbcService, notifier)
+	if dg != nil && cfg.Discord.Commands {
+		commands := balls.NewDiscordCommands(logger, service,
+			balls.WithCommandBrandStyles(brandStyles),
+			balls.WithCommandRenderer(renderer),
+			balls.WithCommandStore(store),
+		)
+		if err := commands.Register(dg); err != nil {
+			logger.Error("error registering discord commands", slog.Any("error", err))
+			os.Exit(1)
+		}
 
-	h := balls.NewHTTPHandler(logger, service, *env)
+		dg.Identify.Intents = discordgo.IntentsGuilds
+		if err := dg.Open(); err != nil {
+			logger.Error("error connecting to discord gateway", slog.Any("error", err))
+			os.Exit(1)
+		}
+		logger.Info("handling discord commands")
+	}
+
+	healthChecks = append(healthChecks,
+		balls.NotifierHealthCheck(notifier),
+		balls.LastRunHealthCheck(store, cfg.Runs.MaxAge),
+	)
+	handlerOpts := []balls.HandlerOption{
+		balls.WithHealthChecks(healthChecks...),
+		balls.WithDigests(digests...),
+		balls.WithDisabledChannelStore(store),
+		balls.WithBallAliasStore(store, canonicalizer),
+		balls.WithMessageTemplates("", cfg.Discord.MessageTemplates("")),
+	}
+	for _, d := range cfg.Discord.Digests {
+		handlerOpts = append(handlerOpts, balls.WithMessageTemplates(d.Name, cfg.Discord.MessageTemplates(d.Name)))
+	}
+	for _, d := range cfg.Destinations() {
+		handlerOpts = append(handlerOpts, balls.WithMessageTemplates(d.Path, d.MessageTemplates(cfg.Discord)))
+	}
+	if cfg.HTTP.AdminToken != "" {
+		handlerOpts = append(handlerOpts, balls.WithAdminToken(cfg.HTTP.AdminToken))
+	}
+	// Subscriptions are only taken where confirmations can be sent.
+	if emailNotifier != nil && cfg.Email.Subscriptions {
+		handlerOpts = append(handlerOpts, balls.WithEmailSubscriptionStore(store, emailNotifier))
+	}
+	handlerOpts = append(handlerOpts, balls.WithFeeds(store, renderer, brandStyles))
+	h := balls.NewHTTPHandler(logger, service, cfg.Env, handlerOpts...)
+
+	// Runs in flight when the server shuts down derive from this context, it's only cancelled once the drain
+	// timeout is nearly up so runs get the chance to finish before being interrupted.
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+	defer cancelRuns()
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%s", cfg.HTTP.Port),
+		Handler:      h,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		BaseContext: func(net.Listener) context.Context {
+			return runCtx
+		},
+	}
+
+	var scheduler *balls.Scheduler
+	if cfg.Runs.Schedule != "" {
+		sched, err := balls.ParseSchedule(cfg.Runs.Schedule)
+		if err != nil {
+			logger.Error("error parsing schedule", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		scheduler = balls.NewScheduler(logger, service, store, sched, cfg.Runs.ScheduleJitter)
+		scheduler.Start(runCtx)
+		logger.Info("started scheduler", slog.String("schedule", cfg.Runs.Schedule))
+	}
+
+	for _, d := range digests {
+		d.Start(runCtx)
+		logger.Info("started digest", slog.String("digest", d.Name()))
+	}
 
 	errs := make(chan error)
 
@@ -77,18 +484,76 @@ func main() {
 	}()
 
 	go func() {
-		srv := &http.Server{
-			Addr:         fmt.Sprintf(":%s", *port),
-			Handler:      h,
-			IdleTimeout:  time.Minute,
-			ReadTimeout:  time.Minute,
-			WriteTimeout: time.Minute,
-		}
 		logger.Info("starting http server", slog.String("addr", srv.Addr))
 		errs <- srv.ListenAndServe()
 	}()
 
 	logger.Info("shutting down", slog.Any("exit", <-errs))
+
+	if err := shutdown(srv, scheduler, digests, cancelRuns, cfg.HTTP.ShutdownTimeout); err != nil {
+		logger.Error("error shutting down", slog.Any("error", err))
+		return
+	}
+
+	logger.Info("shutdown complete")
+}
+
+// webhookTimeout bounds each request posting a notification to a chat webhook or api.
+const webhookTimeout = 30 * time.Second
+
+// checkpointGrace is how long in flight runs are given to checkpoint after being cancelled.
+const checkpointGrace = 2 * time.Second
+
+// shutdown stops the server from accepting new requests, the scheduler from triggering new runs and digests from
+// being sent, all at once so one doesn't hold up the others, then waits up to timeout for in flight requests, runs
+// and digests to finish. Runs still in flight shortly before the timeout are cancelled so they can checkpoint
+// instead of being killed.
+func shutdown(
+	srv *http.Server,
+	scheduler *balls.Scheduler,
+	digests []*balls.DigestNotifier,
+	cancelRuns context.CancelFunc,
+	timeout time.Duration,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	timer := time.AfterFunc(max(timeout-checkpointGrace, 0), cancelRuns)
+	defer timer.Stop()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		var wg sync.WaitGroup
+		if scheduler != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				scheduler.Stop()
+			}()
+		}
+		for _, d := range digests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.Stop()
+			}()
+		}
+		wg.Wait()
+	}()
+
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		err = fmt.Errorf("shutting down http server: %w", err)
+	}
+
+	select {
+	case <-stopped:
+		return err
+	case <-ctx.Done():
+		return errors.Join(err, fmt.Errorf("waiting for runs and digests: %w", ctx.Err()))
+	}
 }
 
 func lookupEnv(key string, defaultValue string) string {
@@ -106,6 +571,6 @@ func (c *channels) String() string {
 }
 
 func (c *channels) Set(value string) error {
-	*c = strings.Split(value, ",")
+	*c = config.SplitList(value)
 	return nil
 }
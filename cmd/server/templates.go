@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/actatum/approved-ball-list/internal/balls"
+	"github.com/actatum/approved-ball-list/internal/config"
+)
+
+// runTemplatesCommand runs the templates subcommand and returns the process exit code. It renders the configured
+// templates of the instant notifications, a digest or another destination, such as email, against sample balls.
+func runTemplatesCommand(cfg config.Config, args []string) int {
+	if len(args) < 1 || len(args) > 2 || args[0] != "preview" {
+		fmt.Fprintln(os.Stderr, "usage: templates preview [digest | destination]")
+		return 2
+	}
+
+	templates := cfg.Discord.MessageTemplates("")
+	if len(args) == 2 {
+		name := args[1]
+		i := slices.IndexFunc(cfg.Destinations(), func(d config.Destination) bool { return d.Path == name })
+		switch {
+		case i >= 0:
+			templates = cfg.Destinations()[i].MessageTemplates(cfg.Discord)
+		case slices.ContainsFunc(cfg.Discord.Digests, func(d config.DigestConfig) bool { return d.Name == name }):
+			templates = cfg.Discord.MessageTemplates(name)
+		default:
+			fmt.Fprintf(os.Stderr, "unknown digest or destination %q\n", name)
+			return 2
+		}
+	}
+
+	renderer, err := balls.NewRenderer(templates)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid templates: %v\n", err)
+		return 1
+	}
+
+	sample := balls.SampleBalls()
+	ctx := balls.ContextWithRun(context.Background(), balls.Run{ID: "preview", StartedAt: time.Now(), Approved: len(sample)})
+	messages, err := renderer.RenderBalls(ctx, sample)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error rendering templates: %v\n", err)
+		return 1
+	}
+
+	for i, msg := range messages {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("title: %s\n", msg.Title)
+		fmt.Printf("description: %s\n", msg.Description)
+		fmt.Printf("digest line: %s\n", msg.DigestLine)
+	}
+
+	return 0
+}
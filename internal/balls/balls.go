@@ -3,16 +3,26 @@ package balls
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
+	"sort"
 	"time"
+
+	"github.com/actatum/approved-ball-list/internal/log"
 )
 
 type Service interface {
 	// CheckForNewlyApprovedBalls checks to see if any new balls are on the USBC approved ball list.
 	CheckForNewlyApprovedBalls(ctx context.Context) error
+	// SearchBalls returns up to limit balls with names similar to query, most similar first. A limit less than 1
+	// uses the default limit.
+	SearchBalls(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	// ListBalls returns the balls matching filter, most recently approved first.
+	ListBalls(ctx context.Context, filter BallFilter) ([]Ball, error)
 }
 
 // Ball represents a bowling ball.
@@ -22,14 +32,23 @@ type Ball struct {
 	Name         string
 	ApprovalDate time.Time
 	ImageURL     *url.URL
+	// CanonicalKey identifies the ball regardless of how the USBC spelled its name, see Canonicalizer. It isn't
+	// stored, so it's only set on balls from the USBC and those rekeyed by the service.
+	CanonicalKey string
 }
 
+// BallsEqual reports whether b1 and b2 are the same approval of the same ball. Balls are compared by canonical key
+// when both have one, otherwise by name.
 func BallsEqual(b1 Ball, b2 Ball) bool {
 	if b1.Brand != b2.Brand {
 		return false
 	}
 
-	if b1.Name != b2.Name {
+	if b1.CanonicalKey != "" && b2.CanonicalKey != "" {
+		if b1.CanonicalKey != b2.CanonicalKey {
+			return false
+		}
+	} else if b1.Name != b2.Name {
 		return false
 	}
 
@@ -76,8 +95,32 @@ var allBrands = []Brand{
 	Track,
 }
 
+// Run is a single check of the USBC approved ball list for newly approved balls.
+type Run struct {
+	ID         string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Approved   int
+	Err        string
+}
+
+// Succeeded reports whether the run completed without error.
+func (r Run) Succeeded() bool {
+	return r.Err == ""
+}
+
+// ErrNotFound is returned when a requested resource does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrDuplicateBall is returned when adding a ball with the same brand, name and approval date as an existing ball.
+var ErrDuplicateBall = errors.New("duplicate ball")
+
+// ErrRunInProgress is returned when a run is skipped because another run holds the run lease.
+var ErrRunInProgress = errors.New("run in progress")
+
 type BallFilter struct {
 	Brand        *Brand
+	Manufacturer *Manufacturer
 	Name         *string
 	ApprovalDate *time.Time
 }
@@ -87,21 +130,66 @@ type service struct {
 	store       Store
 	usbcSerivce USBCService
 	notifier    Notifier
+	workers     int
+	// canonicalizer keys stored and listed balls on every comparison, so alias rules and merges added since a
+	// ball was stored apply to it. When nil balls are compared by name.
+	canonicalizer *Canonicalizer
+}
+
+// ServiceOption configures the service.
+type ServiceOption interface {
+	apply(*service)
+}
+
+type workersOption int
+
+func (o workersOption) apply(s *service) {
+	s.workers = int(o)
+}
+
+// WithWorkers sets the number of brands checked concurrently.
+func WithWorkers(n int) ServiceOption {
+	return workersOption(n)
+}
+
+type canonicalizerOption struct {
+	c *Canonicalizer
+}
+
+func (o canonicalizerOption) apply(s *service) {
+	s.canonicalizer = o.c
+}
+
+// WithCanonicalizer sets the canonicalizer used to identify balls, it should be the same one used by the
+// USBCService. Its manual merges are reloaded from the store at the start of every run.
+func WithCanonicalizer(c *Canonicalizer) ServiceOption {
+	return canonicalizerOption{c: c}
 }
 
+const defaultWorkers = 7
+
 // NewService returns a new service.
 func NewService(
 	logger *slog.Logger,
 	store Store,
 	ubscService USBCService,
 	notifier Notifier,
+	opts ...ServiceOption,
 ) Service {
-	return service{
-		logger:      logger,
-		store:       store,
-		usbcSerivce: ubscService,
-		notifier:    notifier,
+	s := service{
+		logger:        logger,
+		store:         store,
+		usbcSerivce:   ubscService,
+		notifier:      notifier,
+		workers:       defaultWorkers,
+		canonicalizer: &Canonicalizer{},
+	}
+
+	for _, opt := range opts {
+		opt.apply(&s)
 	}
+
+	return s
 }
 
 type jobResult struct {
@@ -110,11 +198,75 @@ type jobResult struct {
 }
 
 func (s service) CheckForNewlyApprovedBalls(ctx context.Context) error {
+	run := Run{
+		ID:        newRunID(),
+		StartedAt: time.Now(),
+	}
+	ctx = log.ContextWithAttrs(ctx, slog.String("run_id", run.ID))
+
+	acquired, err := s.store.AcquireRunLease(ctx, run.ID, runLeaseTTL)
+	if err != nil {
+		return fmt.Errorf("acquiring run lease: %w", err)
+	}
+	if !acquired {
+		return ErrRunInProgress
+	}
+	defer func() {
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordRunTimeout)
+		defer cancel()
+		if err := s.store.ReleaseRunLease(releaseCtx, run.ID); err != nil {
+			s.logger.ErrorContext(ctx, "error releasing run lease", slog.Any("error", err))
+		}
+	}()
+
+	if s.canonicalizer != nil {
+		aliases, err := s.store.GetBallAliases(ctx)
+		if err != nil {
+			// Stale aliases only risk announcing a ball that should have been merged, so the run carries on.
+			s.logger.ErrorContext(ctx, "error loading ball aliases", slog.Any("error", err))
+		} else {
+			s.canonicalizer.SetAliases(aliases)
+		}
+	}
+
+	err = s.checkAllBrands(ctx, &run)
+
+	run.FinishedAt = time.Now()
+	if err != nil {
+		run.Err = err.Error()
+	}
+
+	// The run is recorded even when ctx has been cancelled, e.g. on shutdown, so the interrupted run is checkpointed
+	// in the run history.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordRunTimeout)
+	defer cancel()
+	if addErr := s.store.AddRun(recordCtx, run); addErr != nil {
+		s.logger.ErrorContext(ctx, "error recording run", slog.Any("error", addErr))
+	}
+
+	return err
+}
+
+const (
+	// runLeaseTTL bounds how long a run holds the lease if it's never released, e.g. when an instance is killed.
+	runLeaseTTL      = 15 * time.Minute
+	recordRunTimeout = 5 * time.Second
+	// maxNotifyAttempts is how many runs try to notify of a ball before giving up on it. A notifier failing makes
+	// the run notify every notifier again, so it's kept low to bound repeated announcements.
+	maxNotifyAttempts = 3
+)
+
+func (s service) checkAllBrands(ctx context.Context, run *Run) error {
 	numJobs := len(allBrands)
 	jobs := make(chan Brand, numJobs)
 	results := make(chan jobResult, numJobs)
 
-	for w := 0; w < 7; w++ {
+	workers := s.workers
+	if workers < 1 {
+		workers = defaultWorkers
+	}
+
+	for w := 0; w < workers; w++ {
 		go s.checkForNewlyApprovedBalls(ctx, jobs, results)
 	}
 
@@ -139,16 +291,52 @@ func (s service) CheckForNewlyApprovedBalls(ctx context.Context) error {
 	}
 
 	s.logger.InfoContext(ctx, fmt.Sprintf("%d newly approved balls", len(approved)))
+	run.Approved = len(approved)
 
-	if err := s.notifier.Notify(ctx, approved); err != nil {
+	// Balls are notified of from the store rather than as they're found, so balls stored by an earlier run that was
+	// interrupted, or failed to notify, before announcing them are retried along with the newly approved balls.
+	pending, err := s.store.GetPendingBalls(ctx, maxNotifyAttempts)
+	if err != nil {
+		return fmt.Errorf("getting pending balls: %w", err)
+	}
+	if retried := len(pending) - len(approved); retried > 0 {
+		s.logger.InfoContext(ctx, fmt.Sprintf("retrying notification of %d balls", retried))
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(pending))
+	for _, b := range pending {
+		ids = append(ids, b.ID)
+	}
+
+	// The outcome is checkpointed even when ctx has been cancelled, e.g. on shutdown, so balls that were announced
+	// aren't announced again.
+	checkpointCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordRunTimeout)
+	defer cancel()
+
+	if err := s.notifier.Notify(ContextWithRun(ctx, *run), pending); err != nil {
+		// Interrupted runs don't count towards the attempts, only notifiers that failed on their own.
+		if ctx.Err() == nil {
+			if attemptErr := s.store.AddNotifyAttempt(checkpointCtx, ids); attemptErr != nil {
+				s.logger.ErrorContext(ctx, "error recording notify attempt", slog.Any("error", attemptErr))
+			}
+		}
 		return fmt.Errorf("notifying: %w", err)
 	}
 
+	if err := s.store.MarkBallsNotified(checkpointCtx, ids, time.Now()); err != nil {
+		return fmt.Errorf("marking balls notified: %w", err)
+	}
+
 	return nil
 }
 
 func (s service) checkForNewlyApprovedBalls(ctx context.Context, jobs <-chan Brand, results chan<- jobResult) {
 	for brand := range jobs {
+		ctx := log.ContextWithAttrs(ctx, slog.String("brand", string(brand)))
+
 		s.logger.InfoContext(ctx, fmt.Sprintf("listing balls from %s", brand))
 		balls, err := s.usbcSerivce.ListBalls(ctx, brand)
 		if err != nil {
@@ -159,6 +347,7 @@ func (s service) checkForNewlyApprovedBalls(ctx context.Context, jobs <-chan Bra
 		}
 
 		if len(balls) == 0 {
+			results <- jobResult{}
 			continue
 		}
 
@@ -172,6 +361,16 @@ func (s service) checkForNewlyApprovedBalls(ctx context.Context, jobs <-chan Bra
 			continue
 		}
 
+		// Keys are computed on every run so rules and merges added since a ball was stored apply to it.
+		if s.canonicalizer != nil {
+			for i := range brandBalls {
+				brandBalls[i].CanonicalKey = s.canonicalizer.Key(brandBalls[i].Brand, brandBalls[i].Name)
+			}
+			for i := range balls {
+				balls[i].CanonicalKey = s.canonicalizer.Key(balls[i].Brand, balls[i].Name)
+			}
+		}
+
 		approved := make([]Ball, 0)
 		for _, usbcBall := range balls {
 			found := false
@@ -205,3 +404,43 @@ func (s service) checkForNewlyApprovedBalls(ctx context.Context, jobs <-chan Bra
 		}
 	}
 }
+
+func (s service) SearchBalls(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	if NormalizeName(query) == "" {
+		return nil, ErrEmptyQuery
+	}
+
+	switch {
+	case limit < 1:
+		limit = defaultSearchLimit
+	case limit > maxSearchLimit:
+		limit = maxSearchLimit
+	}
+
+	results, err := s.store.SearchBalls(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("searching balls: %w", err)
+	}
+
+	return results, nil
+}
+
+func (s service) ListBalls(ctx context.Context, filter BallFilter) ([]Ball, error) {
+	balls, err := s.store.GetAllBalls(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("listing balls: %w", err)
+	}
+
+	sort.SliceStable(balls, func(i, j int) bool {
+		return balls[i].ApprovalDate.After(balls[j].ApprovalDate)
+	})
+
+	return balls, nil
+}
+
+// newRunID returns a random id used to correlate all the logs of a single run.
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
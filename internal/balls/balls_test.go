@@ -3,10 +3,13 @@ package balls
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"testing"
 	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func Test_service_checkForNewlyApprovedBalls(t *testing.T) {
@@ -114,6 +117,37 @@ func Test_service_checkForNewlyApprovedBalls(t *testing.T) {
 		}
 	})
 
+	t.Run("no balls listed", func(t *testing.T) {
+		s := service{
+			logger: slog.Default(),
+			usbcSerivce: &USBCServiceMock{
+				ListBallsFunc: func(ctx context.Context, brand Brand) ([]Ball, error) {
+					return nil, nil
+				},
+			},
+		}
+
+		jobs := make(chan Brand)
+		results := make(chan jobResult)
+
+		go s.checkForNewlyApprovedBalls(context.Background(), jobs, results)
+
+		jobs <- Storm
+
+		res := <-results
+
+		close(jobs)
+		close(results)
+
+		if res.Err != nil {
+			t.Fatal(res.Err)
+		}
+
+		if len(res.Balls) != 0 {
+			t.Fatalf("expected 0 approved balls got %d", len(res.Balls))
+		}
+	})
+
 	t.Run("get all balls store error", func(t *testing.T) {
 		now := time.Now()
 
@@ -208,3 +242,187 @@ func Test_service_checkForNewlyApprovedBalls(t *testing.T) {
 		}
 	})
 }
+
+func Test_service_CheckForNewlyApprovedBalls(t *testing.T) {
+	t.Run("interrupted run is recorded", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var recorded []Run
+		store := &StoreMock{
+			GetAllBallsFunc: func(ctx context.Context, filter BallFilter) ([]Ball, error) {
+				return nil, nil
+			},
+			AddBallsFunc: func(ctx context.Context, balls []Ball) error {
+				return nil
+			},
+			GetPendingBallsFunc: func(ctx context.Context, maxAttempts int) ([]Ball, error) {
+				return []Ball{{ID: 1, Brand: Storm, Name: "Phaze II", ApprovalDate: time.Now()}}, nil
+			},
+			AcquireRunLeaseFunc: func(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+				return true, nil
+			},
+			ReleaseRunLeaseFunc: func(ctx context.Context, holder string) error {
+				return nil
+			},
+			AddRunFunc: func(ctx context.Context, run Run) error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				recorded = append(recorded, run)
+				return nil
+			},
+		}
+		s := service{
+			logger: slog.Default(),
+			store:  store,
+			usbcSerivce: &USBCServiceMock{
+				ListBallsFunc: func(ctx context.Context, brand Brand) ([]Ball, error) {
+					return []Ball{{Brand: brand, Name: "Phaze II", ApprovalDate: time.Now()}}, nil
+				},
+			},
+			notifier: &NotifierMock{
+				NotifyFunc: func(ctx context.Context, approvedBalls []Ball) error {
+					cancel()
+					return ctx.Err()
+				},
+			},
+		}
+
+		err := s.CheckForNewlyApprovedBalls(ctx)
+		if err == nil {
+			t.Fatal("expected error got nil")
+		}
+
+		if len(recorded) != 1 {
+			t.Fatalf("expected 1 recorded run got %d", len(recorded))
+		}
+		if recorded[0].Succeeded() {
+			t.Fatal("expected recorded run to have failed")
+		}
+		if recorded[0].Approved != len(allBrands) {
+			t.Fatalf("expected %d approved balls got %d", len(allBrands), recorded[0].Approved)
+		}
+		if len(store.AddNotifyAttemptCalls()) != 0 || len(store.MarkBallsNotifiedCalls()) != 0 {
+			t.Fatal("expected the interrupted run to leave its balls pending without counting an attempt")
+		}
+	})
+
+	t.Run("failed notifications are retried", func(t *testing.T) {
+		store := NewMemoryStore()
+		now := time.Now().Truncate(time.Microsecond)
+		phaze := Ball{Brand: Storm, Name: "Phaze II", ApprovalDate: now}
+		listed := map[Brand][]Ball{Storm: {phaze}}
+
+		var fail bool
+		var notified [][]Ball
+		s := service{
+			logger: slog.Default(),
+			store:  store,
+			usbcSerivce: &USBCServiceMock{
+				ListBallsFunc: func(ctx context.Context, brand Brand) ([]Ball, error) {
+					return listed[brand], nil
+				},
+			},
+			notifier: &NotifierMock{
+				NotifyFunc: func(ctx context.Context, approvedBalls []Ball) error {
+					notified = append(notified, approvedBalls)
+					if fail {
+						return errors.New("webhook unavailable")
+					}
+					return nil
+				},
+			},
+		}
+
+		tests := []struct {
+			name   string
+			fail   bool
+			listed []Ball
+			want   []string
+		}{
+			{name: "new ball fails", fail: true, listed: []Ball{phaze}, want: []string{"Phaze II"}},
+			{
+				name:   "retried with the next new ball",
+				listed: []Ball{phaze, {Brand: Storm, Name: "Hustle", ApprovalDate: now}},
+				want:   []string{"Phaze II", "Hustle"},
+			},
+			{name: "nothing left to notify", listed: []Ball{phaze}},
+		}
+
+		for _, tt := range tests {
+			fail, listed[Storm], notified = tt.fail, tt.listed, nil
+
+			err := s.CheckForNewlyApprovedBalls(context.Background())
+			if tt.fail != (err != nil) {
+				t.Fatalf("%s: unexpected error %v", tt.name, err)
+			}
+
+			var got []string
+			for _, batch := range notified {
+				for _, b := range batch {
+					got = append(got, b.Name)
+				}
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("%s: notified (-got, +want):\n%s", tt.name, diff)
+			}
+		}
+	})
+
+	t.Run("balls are given up on after max attempts", func(t *testing.T) {
+		store := NewMemoryStore()
+		approved := time.Now().Truncate(time.Microsecond)
+		notifier := &NotifierMock{
+			NotifyFunc: func(ctx context.Context, approvedBalls []Ball) error {
+				return errors.New("webhook unavailable")
+			},
+		}
+		s := service{
+			logger: slog.Default(),
+			store:  store,
+			usbcSerivce: &USBCServiceMock{
+				ListBallsFunc: func(ctx context.Context, brand Brand) ([]Ball, error) {
+					if brand != Storm {
+						return nil, nil
+					}
+					return []Ball{{Brand: Storm, Name: "Phaze II", ApprovalDate: approved}}, nil
+				},
+			},
+			notifier: notifier,
+		}
+
+		for range maxNotifyAttempts {
+			if err := s.CheckForNewlyApprovedBalls(context.Background()); err == nil {
+				t.Fatal("expected error got nil")
+			}
+		}
+		if err := s.CheckForNewlyApprovedBalls(context.Background()); err != nil {
+			t.Fatalf("expected the ball to be given up on got %v", err)
+		}
+
+		if n := len(notifier.NotifyCalls()); n != maxNotifyAttempts {
+			t.Fatalf("expected %d attempts got %d", maxNotifyAttempts, n)
+		}
+	})
+
+	t.Run("lease held by another run", func(t *testing.T) {
+		store := &StoreMock{
+			AcquireRunLeaseFunc: func(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+				return false, nil
+			},
+		}
+		s := service{
+			logger: slog.Default(),
+			store:  store,
+		}
+
+		err := s.CheckForNewlyApprovedBalls(context.Background())
+		if !errors.Is(err, ErrRunInProgress) {
+			t.Fatalf("expected ErrRunInProgress got %v", err)
+		}
+
+		if len(store.AddRunCalls()) != 0 {
+			t.Fatal("expected skipped run not to be recorded")
+		}
+	})
+}
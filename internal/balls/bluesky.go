@@ -0,0 +1,258 @@
+package balls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBlueskyService = "https://bsky.social"
+
+	// Bluesky counts graphemes, which never outnumber the runes the limit is checked against.
+	maxBlueskyChars     = 300
+	maxBlueskyAltText   = 2000
+	maxBlueskyImageSize = 1_000_000
+)
+
+// BlueskyNotifier implements the Notifier interface and posts newly approved balls to a Bluesky account, with
+// their images, threading replies when they don't fit in one post.
+type BlueskyNotifier struct {
+	rendering
+
+	client      *http.Client
+	service     string
+	identifier  string
+	appPassword string
+}
+
+// BlueskyOption configures a BlueskyNotifier.
+type BlueskyOption interface {
+	applyBluesky(*BlueskyNotifier)
+}
+
+func (o NotifierOption) applyBluesky(n *BlueskyNotifier) { o.applyRendering(&n.rendering) }
+
+type blueskyServiceOption string
+
+func (o blueskyServiceOption) applyBluesky(n *BlueskyNotifier) {
+	n.service = strings.TrimSuffix(string(o), "/")
+}
+
+// WithBlueskyService sets the url of the account's PDS, https://bsky.social by default.
+func WithBlueskyService(service string) BlueskyOption {
+	return blueskyServiceOption(service)
+}
+
+// NewBlueskyNotifier returns a notifier posting as the account identified by its handle or DID, authenticated by
+// an app password.
+func NewBlueskyNotifier(client *http.Client, identifier, appPassword string, opts ...BlueskyOption) *BlueskyNotifier {
+	n := &BlueskyNotifier{
+		client:      client,
+		service:     defaultBlueskyService,
+		identifier:  identifier,
+		appPassword: appPassword,
+	}
+	for _, opt := range opts {
+		opt.applyBluesky(n)
+	}
+	if n.client == nil {
+		n.client = http.DefaultClient
+	}
+	if n.renderer == nil {
+		n.renderer = defaultRenderer
+	}
+
+	return n
+}
+
+// Notify posts a thread listing the approved balls, attaching the image of each ball to the post mentioning it.
+// Images that can't be uploaded are left out.
+func (n *BlueskyNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	if len(approvedBalls) == 0 {
+		return nil
+	}
+
+	messages, err := n.renderer.RenderBalls(ctx, approvedBalls)
+	if err != nil {
+		return err
+	}
+
+	var session blueskySession
+	err = n.call(ctx, "", "com.atproto.server.createSession",
+		map[string]string{"identifier": n.identifier, "password": n.appPassword}, &session)
+	if err != nil {
+		return fmt.Errorf("creating session: %w", err)
+	}
+
+	titles := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		titles = append(titles, msg.Title)
+	}
+	posts := composeThread(ballCount(len(approvedBalls)), titles, maxBlueskyChars, maxSocialImages)
+
+	var (
+		errs  []error
+		reply *blueskyReply
+	)
+	for i, post := range posts {
+		record := blueskyPost{
+			Type:      "app.bsky.feed.post",
+			Text:      post.Text,
+			CreatedAt: time.Now().UTC().Format(time.RFC3339Nano),
+			Langs:     []string{"en"},
+			Reply:     reply,
+		}
+
+		var images []blueskyImage
+		for _, idx := range post.Balls {
+			b := approvedBalls[idx]
+			if b.ImageURL == nil || b.ImageURL.String() == "" {
+				continue
+			}
+			blob, err := n.uploadImage(ctx, session, b)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("uploading image of %s: %w", titles[idx], err))
+				continue
+			}
+			images = append(images, blueskyImage{Alt: truncate(socialAltText(b), maxBlueskyAltText), Image: blob})
+		}
+		if len(images) > 0 {
+			record.Embed = &blueskyEmbed{Type: "app.bsky.embed.images", Images: images}
+		}
+
+		var created blueskyRef
+		err := n.call(ctx, session.AccessJwt, "com.atproto.repo.createRecord",
+			blueskyCreateRecord{Repo: session.DID, Collection: record.Type, Record: record}, &created)
+		if err != nil {
+			// The rest of the thread has nothing to reply to.
+			return errors.Join(append(errs, fmt.Errorf("posting %d of %d: %w", i+1, len(posts), err))...)
+		}
+
+		if reply == nil {
+			reply = &blueskyReply{Root: created}
+		}
+		reply.Parent = created
+	}
+
+	return errors.Join(errs...)
+}
+
+// uploadImage uploads the ball's image as a blob, returning the reference to embed in a post.
+func (n *BlueskyNotifier) uploadImage(ctx context.Context, session blueskySession, b Ball) (json.RawMessage, error) {
+	data, mimeType, err := downloadImage(ctx, n.client, b.ImageURL, maxBlueskyImageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var uploaded struct {
+		Blob json.RawMessage `json:"blob"`
+	}
+	if err := n.do(ctx, session.AccessJwt, "com.atproto.repo.uploadBlob", mimeType, data, &uploaded); err != nil {
+		return nil, err
+	}
+
+	return uploaded.Blob, nil
+}
+
+type blueskySession struct {
+	DID       string `json:"did"`
+	AccessJwt string `json:"accessJwt"`
+}
+
+type blueskyCreateRecord struct {
+	Repo       string      `json:"repo"`
+	Collection string      `json:"collection"`
+	Record     blueskyPost `json:"record"`
+}
+
+type blueskyPost struct {
+	Type      string        `json:"$type"`
+	Text      string        `json:"text"`
+	CreatedAt string        `json:"createdAt"`
+	Langs     []string      `json:"langs,omitempty"`
+	Reply     *blueskyReply `json:"reply,omitempty"`
+	Embed     *blueskyEmbed `json:"embed,omitempty"`
+}
+
+// blueskyRef is a strong reference to a record.
+type blueskyRef struct {
+	URI string `json:"uri"`
+	CID string `json:"cid"`
+}
+
+type blueskyReply struct {
+	Root   blueskyRef `json:"root"`
+	Parent blueskyRef `json:"parent"`
+}
+
+type blueskyEmbed struct {
+	Type   string         `json:"$type"`
+	Images []blueskyImage `json:"images"`
+}
+
+type blueskyImage struct {
+	Alt   string          `json:"alt"`
+	Image json.RawMessage `json:"image"`
+}
+
+// BlueskyError is returned when the PDS rejects a request.
+type BlueskyError struct {
+	StatusCode int
+	Code       string `json:"error"`
+	Message    string `json:"message"`
+}
+
+func (e *BlueskyError) Error() string {
+	return fmt.Sprintf("bluesky error %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// call calls the XRPC procedure nsid with payload encoded as json.
+func (n *BlueskyNotifier) call(ctx context.Context, accessJwt, nsid string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling payload: %w", err)
+	}
+
+	return n.do(ctx, accessJwt, nsid, "application/json", body, out)
+}
+
+// do calls the XRPC procedure nsid with body, authenticated by accessJwt if it's set, decoding the response into
+// out if it's not nil.
+func (n *BlueskyNotifier) do(ctx context.Context, accessJwt, nsid, contentType string, body []byte, out any) error {
+	resp, err := doWithRetry(ctx, n.client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.service+"/xrpc/"+nsid, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		if accessJwt != "" {
+			req.Header.Set("Authorization", "Bearer "+accessJwt)
+		}
+		return req, nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if !resp.OK() {
+		blueskyErr := &BlueskyError{}
+		if json.Unmarshal(resp.Body, blueskyErr) != nil || blueskyErr.Code == "" {
+			blueskyErr.Message = string(resp.Body)
+		}
+		blueskyErr.StatusCode = resp.StatusCode
+		return blueskyErr
+	}
+	if out != nil {
+		if err := json.Unmarshal(resp.Body, out); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+	}
+
+	return nil
+}
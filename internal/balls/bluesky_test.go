@@ -0,0 +1,162 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBluesky stands in for a PDS, recording the blobs uploaded and posts created on it.
+type fakeBluesky struct {
+	blobs int
+	posts []blueskyPost
+}
+
+func newFakeBluesky(t *testing.T, f *fakeBluesky) string {
+	t.Helper()
+
+	xrpcError := func(w http.ResponseWriter, status int, code, msg string) {
+		writeJSON(w, status, map[string]string{"error": code, "message": msg})
+	}
+
+	srv := newRecordingServer(t, "access-jwt", func(w http.ResponseWriter) {
+		xrpcError(w, http.StatusUnauthorized, "AuthenticationRequired", "Authentication Required")
+	})
+	srv.handle("POST /xrpc/com.atproto.server.createSession", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Identifier string `json:"identifier"`
+			Password   string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Identifier != "balls.example.com" ||
+			req.Password != "app-password" {
+			xrpcError(w, http.StatusUnauthorized, "AuthenticationRequired", "Invalid identifier or password")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"did": "did:plc:balls", "accessJwt": "access-jwt"})
+	})
+	srv.handleAuthorized("POST /xrpc/com.atproto.repo.uploadBlob", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if len(data) > maxBlueskyImageSize {
+			xrpcError(w, http.StatusBadRequest, "BlobTooLarge", "This file is too large")
+			return
+		}
+		f.blobs++
+		writeJSON(w, http.StatusOK, map[string]any{"blob": map[string]any{
+			"$type":    "blob",
+			"ref":      map[string]string{"$link": fmt.Sprintf("bafkrei%d", f.blobs)},
+			"mimeType": r.Header.Get("Content-Type"),
+			"size":     len(data),
+		}})
+	})
+	srv.handleAuthorized("POST /xrpc/com.atproto.repo.createRecord", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Repo       string      `json:"repo"`
+			Collection string      `json:"collection"`
+			Record     blueskyPost `json:"record"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Repo != "did:plc:balls" ||
+			req.Collection != "app.bsky.feed.post" {
+			xrpcError(w, http.StatusBadRequest, "InvalidRequest", "Input must have the property \"repo\"")
+			return
+		}
+		if len([]rune(req.Record.Text)) > maxBlueskyChars {
+			xrpcError(w, http.StatusBadRequest, "InvalidRequest", "Invalid app.bsky.feed.post record: Record/text must not be longer than 300 graphemes")
+			return
+		}
+
+		f.posts = append(f.posts, req.Record)
+		n := len(f.posts)
+		writeJSON(w, http.StatusOK, blueskyRef{
+			URI: fmt.Sprintf("at://did:plc:balls/app.bsky.feed.post/%d", n),
+			CID: fmt.Sprintf("bafyrei%d", n),
+		})
+	})
+
+	return srv.URL
+}
+
+func TestBlueskyNotifier_Notify(t *testing.T) {
+	image := newImageServer(t)
+	approved := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	var approvedBalls []Ball
+	for i := range 4 {
+		approvedBalls = append(approvedBalls, Ball{
+			Brand:        Storm,
+			Name:         fmt.Sprintf("Phaze %d %s", i+1, strings.Repeat("Pearl Reactive ", 5)),
+			ImageURL:     image(fmt.Sprintf("phaze-%d.png", i+1)),
+			ApprovalDate: approved,
+		})
+	}
+	approvedBalls[1].ImageURL = image("huge.png")
+
+	fake := &fakeBluesky{}
+	n := NewBlueskyNotifier(nil, "balls.example.com", "app-password", WithBlueskyService(newFakeBluesky(t, fake)))
+
+	err := n.Notify(context.Background(), approvedBalls)
+	if err == nil || !strings.Contains(err.Error(), "image is larger than") {
+		t.Fatalf("expected the huge image to be reported got %v", err)
+	}
+
+	if len(fake.posts) != 2 {
+		t.Fatalf("expected a thread of 2 posts got %+v", fake.posts)
+	}
+	first, second := fake.posts[0], fake.posts[1]
+	if !strings.HasPrefix(first.Text, "4 newly approved balls\n• Storm Phaze 1") || !strings.HasSuffix(first.Text, "(1/2)") {
+		t.Errorf("unexpected first post %q", first.Text)
+	}
+	if first.Reply != nil || first.Embed == nil || len(first.Embed.Images) != 1 {
+		t.Fatalf("expected the first post to embed the one uploaded image got %+v", first)
+	}
+	if alt := first.Embed.Images[0].Alt; alt != "Product photo of the Storm "+approvedBalls[0].Name+" bowling ball" {
+		t.Errorf("unexpected alt text %q", alt)
+	}
+
+	root := blueskyRef{URI: "at://did:plc:balls/app.bsky.feed.post/1", CID: "bafyrei1"}
+	if second.Reply == nil || second.Reply.Root != root || second.Reply.Parent != root {
+		t.Errorf("expected the second post to reply to the first got %+v", second.Reply)
+	}
+	if second.Embed == nil || len(second.Embed.Images) != 2 {
+		t.Errorf("expected the second post to embed 2 images got %+v", second.Embed)
+	}
+}
+
+func TestBlueskyNotifier_Notify_invalidPassword(t *testing.T) {
+	fake := &fakeBluesky{}
+	n := NewBlueskyNotifier(nil, "balls.example.com", "wrong", WithBlueskyService(newFakeBluesky(t, fake)))
+
+	err := n.Notify(context.Background(), webhookTestBalls())
+
+	var blueskyErr *BlueskyError
+	if !errors.As(err, &blueskyErr) || blueskyErr.Code != "AuthenticationRequired" {
+		t.Fatalf("expected authentication error got %v", err)
+	}
+	if len(fake.posts) != 0 {
+		t.Fatal("expected nothing to be posted")
+	}
+}
+
+func TestBlueskyNotifier_Notify_renderer(t *testing.T) {
+	renderer, err := NewRenderer(MessageTemplates{Title: `{{upper .Ball.Name}} by {{.Ball.Brand}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeBluesky{}
+	n := NewBlueskyNotifier(nil, "balls.example.com", "app-password",
+		WithBlueskyService(newFakeBluesky(t, fake)),
+		WithRenderer(renderer),
+	)
+
+	if err := n.Notify(context.Background(), webhookTestBalls()[1:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.posts) != 1 || fake.posts[0].Text != "1 newly approved ball\n• VENOM SHOCK by Motiv" {
+		t.Errorf("expected the post to list the rendered title got %+v", fake.posts)
+	}
+}
@@ -0,0 +1,283 @@
+package balls
+
+import (
+	"net/url"
+	"sort"
+)
+
+// Manufacturer is the parent company of one or more brands.
+type Manufacturer string
+
+// Manufacturers of the active brands. Brands without a parent company are their own manufacturer.
+const (
+	StormProducts    Manufacturer = "Storm Products"
+	BrunswickBowling Manufacturer = "Brunswick Bowling Products"
+	BigBowlingCo     Manufacturer = Manufacturer(BigBowling)
+	MotivBowling     Manufacturer = Manufacturer(Motiv)
+	SwagBowling      Manufacturer = Manufacturer(Swag)
+)
+
+// BrandInfo is what's known about a brand beyond its name.
+type BrandInfo struct {
+	Brand        Brand
+	Manufacturer Manufacturer
+	Style        BrandStyle
+}
+
+// BrandStyle is how a brand's balls are presented in notifications.
+type BrandStyle struct {
+	// Color is the brand's color as 0xRRGGBB, e.g. the sidebar of discord embeds.
+	Color int
+	// Website is the brand's site, linked from its balls.
+	Website string
+	// LogoURL is an image of the brand's logo.
+	LogoURL string
+}
+
+// BallURL links to b on the brand's site, or is empty without one. The USBC list doesn't link to product pages and
+// the brands' sites don't share a URL scheme, so it's a DuckDuckGo search of the site that redirects to the first
+// result, which is the ball's page once the brand has published it.
+func (s BrandStyle) BallURL(b Ball) string {
+	site, err := url.Parse(s.Website)
+	if s.Website == "" || err != nil {
+		return ""
+	}
+
+	return "https://duckduckgo.com/?" + url.Values{"q": {`\site:` + site.Host + " " + b.Name}}.Encode()
+}
+
+// siteIcon returns the icon of site at a size suited to thumbnails. The brands don't publish stable logo URLs, their
+// sites' icons are their logos.
+func siteIcon(site string) string {
+	return "https://www.google.com/s2/favicons?" + url.Values{"domain_url": {site}, "sz": {"128"}}.Encode()
+}
+
+// Merge returns s with the non-zero fields of override replacing its own.
+func (s BrandStyle) Merge(override BrandStyle) BrandStyle {
+	if override.Color != 0 {
+		s.Color = override.Color
+	}
+	if override.Website != "" {
+		s.Website = override.Website
+	}
+	if override.LogoURL != "" {
+		s.LogoURL = override.LogoURL
+	}
+
+	return s
+}
+
+// BrandStyles overrides the registry's styling of brands.
+type BrandStyles map[Brand]BrandStyle
+
+// Style returns the registry's style of brand with any override in s applied.
+func (s BrandStyles) Style(brand Brand) BrandStyle {
+	return brandRegistry[brand].Style.Merge(s[brand])
+}
+
+// brandRegistry describes every active brand.
+var brandRegistry = map[Brand]BrandInfo{
+	Global: {
+		Brand:        Global,
+		Manufacturer: StormProducts,
+		Style: BrandStyle{
+			Color:   0xF7A800,
+			Website: "https://www.900global.com",
+			LogoURL: siteIcon("https://www.900global.com"),
+		},
+	},
+	BigBowling: {
+		Brand:        BigBowling,
+		Manufacturer: BigBowlingCo,
+		Style:        BrandStyle{Color: 0xF2C500},
+	},
+	Brunswick: {
+		Brand:        Brunswick,
+		Manufacturer: BrunswickBowling,
+		Style: BrandStyle{
+			Color:   0x0033A0,
+			Website: "https://www.brunswickbowling.com",
+			LogoURL: siteIcon("https://www.brunswickbowling.com"),
+		},
+	},
+	Columbia300: {
+		Brand:        Columbia300,
+		Manufacturer: BrunswickBowling,
+		Style: BrandStyle{
+			Color:   0xC8102E,
+			Website: "https://www.columbia300.com",
+			LogoURL: siteIcon("https://www.columbia300.com"),
+		},
+	},
+	DV8: {
+		Brand:        DV8,
+		Manufacturer: BrunswickBowling,
+		Style: BrandStyle{
+			Color:   0x6CC04A,
+			Website: "https://www.dv8bowling.com",
+			LogoURL: siteIcon("https://www.dv8bowling.com"),
+		},
+	},
+	Ebonite: {
+		Brand:        Ebonite,
+		Manufacturer: BrunswickBowling,
+		Style: BrandStyle{
+			Color:   0x00539B,
+			Website: "https://www.ebonite.com",
+			LogoURL: siteIcon("https://www.ebonite.com"),
+		},
+	},
+	Hammer: {
+		Brand:        Hammer,
+		Manufacturer: BrunswickBowling,
+		Style: BrandStyle{
+			Color:   0xD50032,
+			Website: "https://www.hammerbowling.com",
+			LogoURL: siteIcon("https://www.hammerbowling.com"),
+		},
+	},
+	Motiv: {
+		Brand:        Motiv,
+		Manufacturer: MotivBowling,
+		Style: BrandStyle{
+			Color:   0x00A3E0,
+			Website: "https://www.motivbowling.com",
+			LogoURL: siteIcon("https://www.motivbowling.com"),
+		},
+	},
+	Radical: {
+		Brand:        Radical,
+		Manufacturer: BrunswickBowling,
+		Style: BrandStyle{
+			Color:   0xFF6600,
+			Website: "https://www.radicalbowling.com",
+			LogoURL: siteIcon("https://www.radicalbowling.com"),
+		},
+	},
+	RotoGrip: {
+		Brand:        RotoGrip,
+		Manufacturer: StormProducts,
+		Style: BrandStyle{
+			Color:   0x2B2B2B,
+			Website: "https://www.rotogrip.com",
+			LogoURL: siteIcon("https://www.rotogrip.com"),
+		},
+	},
+	Storm: {
+		Brand:        Storm,
+		Manufacturer: StormProducts,
+		Style: BrandStyle{
+			Color:   0xE31837,
+			Website: "https://www.stormbowling.com",
+			LogoURL: siteIcon("https://www.stormbowling.com"),
+		},
+	},
+	Swag: {
+		Brand:        Swag,
+		Manufacturer: SwagBowling,
+		Style: BrandStyle{
+			Color:   0x7D3C98,
+			Website: "https://www.swagbowling.com",
+			LogoURL: siteIcon("https://www.swagbowling.com"),
+		},
+	},
+	Track: {
+		Brand:        Track,
+		Manufacturer: BrunswickBowling,
+		Style: BrandStyle{
+			Color:   0xFF8200,
+			Website: "https://www.trackbowling.com",
+			LogoURL: siteIcon("https://www.trackbowling.com"),
+		},
+	},
+}
+
+// LookupBrand returns the registry entry of brand, reporting whether it's a known brand.
+func LookupBrand(brand Brand) (BrandInfo, bool) {
+	info, ok := brandRegistry[brand]
+	return info, ok
+}
+
+// Manufacturer returns the parent company of b, unknown brands are their own manufacturer.
+func (b Brand) Manufacturer() Manufacturer {
+	if info, ok := brandRegistry[b]; ok {
+		return info.Manufacturer
+	}
+
+	return Manufacturer(b)
+}
+
+// Brands returns the known brands owned by m, sorted by name.
+func (m Manufacturer) Brands() []Brand {
+	var brands []Brand
+	for _, info := range brandRegistry {
+		if info.Manufacturer == m {
+			brands = append(brands, info.Brand)
+		}
+	}
+	sort.Slice(brands, func(i, j int) bool {
+		return brands[i] < brands[j]
+	})
+
+	return brands
+}
+
+// Manufacturers returns the manufacturers of every known brand, sorted by name.
+func Manufacturers() []Manufacturer {
+	seen := make(map[Manufacturer]struct{})
+	var manufacturers []Manufacturer
+	for _, info := range brandRegistry {
+		if _, ok := seen[info.Manufacturer]; ok {
+			continue
+		}
+		seen[info.Manufacturer] = struct{}{}
+		manufacturers = append(manufacturers, info.Manufacturer)
+	}
+	sort.Slice(manufacturers, func(i, j int) bool {
+		return manufacturers[i] < manufacturers[j]
+	})
+
+	return manufacturers
+}
+
+// ManufacturerGroup is a manufacturer's balls grouped by brand.
+type ManufacturerGroup struct {
+	Manufacturer Manufacturer
+	Brands       []BrandGroup
+}
+
+// BrandGroup is a brand's balls.
+type BrandGroup struct {
+	Brand Brand
+	Balls []Ball
+}
+
+// GroupByManufacturer groups balls by manufacturer and then brand, both sorted by name. Balls keep their order
+// within a brand.
+func GroupByManufacturer(balls []Ball) []ManufacturerGroup {
+	byManufacturer := make(map[Manufacturer]map[Brand][]Ball)
+	for _, b := range balls {
+		m := b.Brand.Manufacturer()
+		if byManufacturer[m] == nil {
+			byManufacturer[m] = make(map[Brand][]Ball)
+		}
+		byManufacturer[m][b.Brand] = append(byManufacturer[m][b.Brand], b)
+	}
+
+	groups := make([]ManufacturerGroup, 0, len(byManufacturer))
+	for m, byBrand := range byManufacturer {
+		group := ManufacturerGroup{Manufacturer: m}
+		for brand, brandBalls := range byBrand {
+			group.Brands = append(group.Brands, BrandGroup{Brand: brand, Balls: brandBalls})
+		}
+		sort.Slice(group.Brands, func(i, j int) bool {
+			return group.Brands[i].Brand < group.Brands[j].Brand
+		})
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Manufacturer < groups[j].Manufacturer
+	})
+
+	return groups
+}
@@ -0,0 +1,126 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestManufacturer_Brands(t *testing.T) {
+	tests := []struct {
+		manufacturer Manufacturer
+		want         []Brand
+	}{
+		{manufacturer: StormProducts, want: []Brand{Global, RotoGrip, Storm}},
+		{manufacturer: BrunswickBowling, want: []Brand{Brunswick, Columbia300, DV8, Ebonite, Hammer, Radical, Track}},
+		{manufacturer: MotivBowling, want: []Brand{Motiv}},
+		{manufacturer: "Acme", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.manufacturer), func(t *testing.T) {
+			if diff := cmp.Diff(tt.manufacturer.Brands(), tt.want); diff != "" {
+				t.Fatalf("(-got, +want):\n%s", diff)
+			}
+			for _, b := range tt.want {
+				if b.Manufacturer() != tt.manufacturer {
+					t.Fatalf("expected %s to be made by %s got %s", b, tt.manufacturer, b.Manufacturer())
+				}
+			}
+		})
+	}
+
+	for _, b := range allBrands {
+		if _, ok := LookupBrand(b); !ok {
+			t.Errorf("expected %s to be in the brand registry", b)
+		}
+	}
+}
+
+func TestGroupByManufacturer(t *testing.T) {
+	balls := []Ball{
+		{Brand: Hammer, Name: "Black Widow Mania"},
+		{Brand: Storm, Name: "Phaze II"},
+		{Brand: DV8, Name: "Pitbull"},
+		{Brand: RotoGrip, Name: "Hustle"},
+		{Brand: Storm, Name: "Phaze III"},
+	}
+
+	got := GroupByManufacturer(balls)
+	want := []ManufacturerGroup{
+		{
+			Manufacturer: BrunswickBowling,
+			Brands: []BrandGroup{
+				{Brand: DV8, Balls: []Ball{balls[2]}},
+				{Brand: Hammer, Balls: []Ball{balls[0]}},
+			},
+		},
+		{
+			Manufacturer: StormProducts,
+			Brands: []BrandGroup{
+				{Brand: RotoGrip, Balls: []Ball{balls[3]}},
+				{Brand: Storm, Balls: []Ball{balls[1], balls[4]}},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("(-got, +want):\n%s", diff)
+	}
+}
+
+func Test_handleListBalls(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	err := store.AddBalls(context.Background(), []Ball{
+		{Brand: Storm, Name: "Phaze II", ApprovalDate: now.AddDate(-1, 0, 0)},
+		{Brand: RotoGrip, Name: "Hustle", ApprovalDate: now},
+		{Brand: Hammer, Name: "Black Widow Mania", ApprovalDate: now},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHTTPHandler(slog.Default(), NewService(slog.Default(), store, nil, nil), "test")
+
+	t.Run("by manufacturer", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/balls?manufacturer=Storm+Products", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d got %d", http.StatusOK, rec.Code)
+		}
+
+		var body struct {
+			Balls []ballResponse `json:"balls"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, b := range body.Balls {
+			if b.Manufacturer != StormProducts {
+				t.Fatalf("expected manufacturer %s got %s", StormProducts, b.Manufacturer)
+			}
+			names = append(names, b.Name)
+		}
+		if diff := cmp.Diff(names, []string{"Hustle", "Phaze II"}); diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("unknown manufacturer", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/balls?manufacturer=Acme", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package balls
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// AliasRule rewrites the canonical keys matching Pattern, merging spellings the USBC uses interchangeably for the
+// same ball, e.g. {Pattern: `^hy road`, Replacement: "hyroad"}.
+type AliasRule struct {
+	// Brand limits the rule to a single brand, when empty it applies to every brand.
+	Brand Brand
+	// Pattern is a regular expression matched against the canonical key.
+	Pattern string
+	// Replacement replaces matches of Pattern, it may refer to submatches as in regexp.Regexp.Expand.
+	Replacement string
+}
+
+// BallAlias merges a ball into another by mapping its canonical key to the canonical key of the ball it's the same
+// as, for one off corrections that don't warrant an AliasRule.
+type BallAlias struct {
+	Brand     Brand
+	Alias     string
+	Canonical string
+}
+
+type aliasRule struct {
+	brand       Brand
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+type aliasKey struct {
+	brand Brand
+	key   string
+}
+
+// Canonicalizer derives the canonical identity of balls from their names, so that entries for the same ball that
+// differ in case, whitespace, accents, trademark symbols or the order of their cover type compare equal.
+type Canonicalizer struct {
+	rules []aliasRule
+
+	mu      sync.RWMutex
+	aliases map[aliasKey]string
+}
+
+// NewCanonicalizer returns a canonicalizer applying rules, in order, to every key.
+func NewCanonicalizer(rules ...AliasRule) (*Canonicalizer, error) {
+	c := &Canonicalizer{}
+	for _, r := range rules {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling alias pattern %q: %w", r.Pattern, err)
+		}
+		c.rules = append(c.rules, aliasRule{brand: r.Brand, pattern: pattern, replacement: r.Replacement})
+	}
+
+	return c, nil
+}
+
+// SetAliases replaces the manual merges applied after the alias rules.
+func (c *Canonicalizer) SetAliases(aliases []BallAlias) {
+	m := make(map[aliasKey]string, len(aliases))
+	for _, a := range aliases {
+		m[aliasKey{brand: a.Brand, key: a.Alias}] = a.Canonical
+	}
+
+	c.mu.Lock()
+	c.aliases = m
+	c.mu.Unlock()
+}
+
+// coverTypes are the words describing a ball's coverstock that the USBC places inconsistently, e.g. "Pearl Phaze"
+// and "Phaze Pearl". They're moved to the end of keys in this order.
+var coverTypes = []string{"solid", "pearl", "hybrid"}
+
+// Key returns the canonical key of a ball of brand named name.
+func (c *Canonicalizer) Key(brand Brand, name string) string {
+	key := c.ruleKey(brand, name)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if canonical, ok := c.aliases[aliasKey{brand: brand, key: key}]; ok {
+		return canonical
+	}
+
+	return key
+}
+
+// ruleKey returns the canonical key of a ball of brand named name with the alias rules but not the manual merges
+// applied, which is what merges map from and to so they don't chain.
+func (c *Canonicalizer) ruleKey(brand Brand, name string) string {
+	words := strings.Fields(NormalizeName(name))
+
+	var covers []string
+	kept := words[:0]
+	for _, w := range words {
+		if isCoverType(w) {
+			covers = append(covers, w)
+			continue
+		}
+		kept = append(kept, w)
+	}
+	sort.SliceStable(covers, func(i, j int) bool {
+		return coverTypeIndex(covers[i]) < coverTypeIndex(covers[j])
+	})
+
+	key := strings.Join(append(kept, covers...), " ")
+
+	for _, r := range c.rules {
+		if r.brand != "" && r.brand != brand {
+			continue
+		}
+		key = strings.Join(strings.Fields(r.pattern.ReplaceAllString(key, r.replacement)), " ")
+	}
+
+	return key
+}
+
+func isCoverType(w string) bool {
+	return coverTypeIndex(w) >= 0
+}
+
+func coverTypeIndex(w string) int {
+	for i, c := range coverTypes {
+		if w == c {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// trademarks are stripped from names, the USBC only sometimes includes them.
+var trademarks = strings.NewReplacer("™", "", "®", "", "©", "", "℠", "", "(TM)", "", "(R)", "")
+
+// CleanName tidies a ball name for display, stripping trademark symbols and collapsing whitespace.
+func CleanName(name string) string {
+	name = norm.NFC.String(trademarks.Replace(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// foldName decomposes name and removes any combining marks so accented letters compare equal to their base letter,
+// e.g. "Señor" and "Senor".
+func foldName(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		return name
+	}
+
+	return folded
+}
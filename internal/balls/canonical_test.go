@@ -0,0 +1,243 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCanonicalizer_Key(t *testing.T) {
+	c, err := NewCanonicalizer(
+		AliasRule{Brand: Storm, Pattern: `^hy road`, Replacement: "hyroad"},
+		AliasRule{Pattern: `\btour$`, Replacement: "tour edition"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetAliases([]BallAlias{{Brand: Motiv, Alias: "venom shok", Canonical: "venom shock"}})
+
+	tests := []struct {
+		brand Brand
+		names []string
+		want  string
+	}{
+		{
+			brand: Storm,
+			names: []string{"Phaze II", "PHAZE  II", " Phaze II™", "Phaze II®", "Phaze 2"},
+			want:  "phaze 2",
+		},
+		{
+			brand: Storm,
+			names: []string{"Hy-Road Pearl", "Pearl Hy Road", "HyRoad Pearl"},
+			want:  "hyroad pearl",
+		},
+		{
+			brand: Hammer,
+			names: []string{"Hy-Road Pearl"},
+			want:  "hy road pearl",
+		},
+		{
+			brand: RotoGrip,
+			names: []string{"Señor Pearl Solid", "Senor Solid Pearl"},
+			want:  "senor solid pearl",
+		},
+		{
+			brand: Storm,
+			names: []string{"!Q Tour", "!Q  TOUR"},
+			want:  "q tour edition",
+		},
+		{
+			brand: Motiv,
+			names: []string{"Venom Shok", "Venom Shock"},
+			want:  "venom shock",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, name := range tt.names {
+			if got := c.Key(tt.brand, name); got != tt.want {
+				t.Errorf("Key(%q, %q) = %q, want %q", tt.brand, name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestCleanName(t *testing.T) {
+	if got := CleanName("  Black Widow®   Mania™ "); got != "Black Widow Mania" {
+		t.Fatalf("expected symbols and extra whitespace removed got %q", got)
+	}
+}
+
+func Test_service_checkForNewlyApprovedBalls_canonical(t *testing.T) {
+	now := time.Now().Truncate(time.Microsecond)
+	store := NewMemoryStore()
+
+	// Stored before canonical keys existed.
+	addNotifiedBalls(t, store, []Ball{{Brand: Storm, Name: "Phaze II™", ApprovalDate: now}})
+	err := store.AddBallAlias(context.Background(), BallAlias{Brand: Storm, Alias: "phase 2", Canonical: "phaze 2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCanonicalizer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notified []Ball
+	svc := NewService(slog.Default(), store,
+		&USBCServiceMock{
+			ListBallsFunc: func(ctx context.Context, brand Brand) ([]Ball, error) {
+				if brand != Storm {
+					return nil, nil
+				}
+				return []Ball{
+					{Brand: Storm, Name: "PHAZE  II", ApprovalDate: now},
+					{Brand: Storm, Name: "Phase II", ApprovalDate: now},
+					{Brand: Storm, Name: "Phaze III", ApprovalDate: now},
+				}, nil
+			},
+		},
+		&NotifierMock{
+			NotifyFunc: func(ctx context.Context, approvedBalls []Ball) error {
+				notified = approvedBalls
+				return nil
+			},
+		},
+		WithCanonicalizer(c),
+	)
+
+	if err := svc.CheckForNewlyApprovedBalls(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(notified) != 1 || notified[0].Name != "Phaze III" {
+		t.Fatalf("expected only Phaze III to be announced got %+v", notified)
+	}
+}
+
+func Test_service_checkForNewlyApprovedBalls_staleKeys(t *testing.T) {
+	now := time.Now().Truncate(time.Microsecond)
+	store := NewMemoryStore()
+
+	// Stored under the USBC's misspelling before the merge below was added.
+	addNotifiedBalls(t, store, []Ball{{Brand: Storm, Name: "Phase II", ApprovalDate: now}})
+	err := store.AddBallAlias(context.Background(), BallAlias{Brand: Storm, Alias: "phase 2", Canonical: "phaze 2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCanonicalizer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notified []Ball
+	svc := NewService(slog.Default(), store,
+		&USBCServiceMock{
+			ListBallsFunc: func(ctx context.Context, brand Brand) ([]Ball, error) {
+				if brand != Storm {
+					return nil, nil
+				}
+				return []Ball{{Brand: Storm, Name: "Phaze II", ApprovalDate: now, CanonicalKey: "phaze 2"}}, nil
+			},
+		},
+		&NotifierMock{
+			NotifyFunc: func(ctx context.Context, approvedBalls []Ball) error {
+				notified = approvedBalls
+				return nil
+			},
+		},
+		WithCanonicalizer(c),
+	)
+
+	if err := svc.CheckForNewlyApprovedBalls(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(notified) != 0 {
+		t.Fatalf("expected the corrected spelling not to be announced again got %+v", notified)
+	}
+}
+
+func TestHTTPHandler_ballAliases(t *testing.T) {
+	store := NewMemoryStore()
+	c, err := NewCanonicalizer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Merges aren't applied to the names being merged, so they don't chain.
+	c.SetAliases([]BallAlias{{Brand: Storm, Alias: "phaze 2", Canonical: "phaze"}})
+	h := NewHTTPHandler(slog.Default(), NewService(slog.Default(), store, nil, nil), "test",
+		WithBallAliasStore(store, c),
+		WithAdminToken("secret"),
+	)
+
+	request := func(method, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/aliases", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		token    string
+		wantCode int
+	}{
+		{
+			name:     "no admin token",
+			body:     `{"brand": "Storm", "name": "Phase II", "canonical": "Phaze II"}`,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "unknown brand",
+			body:     `{"brand": "Nope", "name": "Phase II", "canonical": "Phaze II"}`,
+			token:    "secret",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "same ball",
+			body:     `{"brand": "Storm", "name": "PHAZE 2", "canonical": "Phaze II"}`,
+			token:    "secret",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "merge",
+			body:     `{"brand": "Storm", "name": "Phase II", "canonical": "Phaze II"}`,
+			token:    "secret",
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := request(http.MethodPost, tt.body, tt.token); rec.Code != tt.wantCode {
+				t.Fatalf("expected status %d got %d: %s", tt.wantCode, rec.Code, rec.Body)
+			}
+		})
+	}
+
+	rec := request(http.MethodGet, "", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d", http.StatusOK, rec.Code)
+	}
+	var resp struct {
+		Aliases []ballAliasResponse `json:"aliases"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	want := []ballAliasResponse{{Brand: Storm, Alias: "phase 2", Canonical: "phaze 2"}}
+	if diff := cmp.Diff(resp.Aliases, want); diff != "" {
+		t.Fatalf("(-got, +want):\n%s", diff)
+	}
+}
@@ -0,0 +1,178 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Digest is a summary of the balls approved since the last digest was sent.
+type Digest struct {
+	// Name identifies the digest, e.g. daily.
+	Name  string
+	Balls []Ball
+	// Groups are the balls grouped by manufacturer and brand.
+	Groups []ManufacturerGroup
+}
+
+// DigestSender is implemented by notifiers that can send a digest as a single summary. Notifiers that don't
+// implement it are sent the digest's balls with Notify.
+type DigestSender interface {
+	NotifyDigest(ctx context.Context, digest Digest) error
+}
+
+// DigestNotifier implements the Notifier interface by accumulating approved balls in the store and sending them
+// to its notifier as a single digest on a schedule.
+type DigestNotifier struct {
+	logger   *slog.Logger
+	name     string
+	store    Store
+	notifier Notifier
+	schedule Schedule
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewDigestNotifier returns a new digest notifier, name identifies the digest's accumulated balls in the store so
+// it must be unique and stable across restarts.
+func NewDigestNotifier(
+	logger *slog.Logger,
+	name string,
+	store Store,
+	notifier Notifier,
+	schedule Schedule,
+) *DigestNotifier {
+	return &DigestNotifier{
+		logger:   logger,
+		name:     name,
+		store:    store,
+		notifier: notifier,
+		schedule: schedule,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Name returns the name of the digest.
+func (d *DigestNotifier) Name() string {
+	return d.name
+}
+
+// Notify adds the approved balls to the next digest.
+func (d *DigestNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	if len(approvedBalls) == 0 {
+		return nil
+	}
+
+	if err := d.store.AddDigestBalls(ctx, d.name, approvedBalls); err != nil {
+		return fmt.Errorf("adding balls to %s digest: %w", d.name, err)
+	}
+
+	return nil
+}
+
+// Send sends the balls accumulated since the last digest, if there are any. Balls are taken from the store before
+// sending so concurrent sends from multiple instances don't repeat them, and put back if sending fails.
+func (d *DigestNotifier) Send(ctx context.Context) error {
+	balls, err := d.store.TakeDigestBalls(ctx, d.name)
+	if err != nil {
+		return fmt.Errorf("taking balls for %s digest: %w", d.name, err)
+	}
+	if len(balls) == 0 {
+		return nil
+	}
+
+	digest := Digest{
+		Name:   d.name,
+		Balls:  balls,
+		Groups: GroupByManufacturer(balls),
+	}
+
+	if sender, ok := d.notifier.(DigestSender); ok {
+		err = sender.NotifyDigest(ctx, digest)
+	} else {
+		err = d.notifier.Notify(ctx, balls)
+	}
+	var deliveryErr *DeliveryError
+	if errors.As(err, &deliveryErr) && deliveryErr.Report.Delivered() > 0 {
+		// Putting the balls back would repeat them in the channels that were delivered to.
+		return fmt.Errorf("sending %s digest: %w", d.name, err)
+	}
+	if err != nil {
+		restoreCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordRunTimeout)
+		defer cancel()
+		if restoreErr := d.store.AddDigestBalls(restoreCtx, d.name, balls); restoreErr != nil {
+			err = errors.Join(err, fmt.Errorf("restoring balls: %w", restoreErr))
+		}
+		return fmt.Errorf("sending %s digest: %w", d.name, err)
+	}
+
+	d.logger.InfoContext(ctx, fmt.Sprintf("sent %s digest of %d balls", d.name, len(balls)))
+
+	return nil
+}
+
+// Start starts sending digests on the schedule in the background until Stop is called or ctx is cancelled.
+func (d *DigestNotifier) Start(ctx context.Context) {
+	go d.run(ctx)
+}
+
+// Stop stops sending digests and waits for an in flight send to finish.
+func (d *DigestNotifier) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+func (d *DigestNotifier) run(ctx context.Context) {
+	defer close(d.done)
+
+	for {
+		timer := time.NewTimer(time.Until(d.schedule.Next(time.Now())))
+		select {
+		case <-d.stop:
+			timer.Stop()
+			return
+
+		case <-ctx.Done():
+			timer.Stop()
+			return
+
+		case <-timer.C:
+		}
+
+		if err := d.Send(ctx); err != nil {
+			d.logger.ErrorContext(ctx, "error sending digest", slog.String("digest", d.name), slog.Any("error", err))
+		}
+	}
+}
+
+// sortDigestBalls sorts balls pending in a digest by approval date, then brand and name.
+func sortDigestBalls(balls []Ball) {
+	sort.Slice(balls, func(i, j int) bool {
+		if !balls[i].ApprovalDate.Equal(balls[j].ApprovalDate) {
+			return balls[i].ApprovalDate.Before(balls[j].ApprovalDate)
+		}
+		if balls[i].Brand != balls[j].Brand {
+			return balls[i].Brand < balls[j].Brand
+		}
+		return balls[i].Name < balls[j].Name
+	})
+}
+
+// MultiNotifier implements the Notifier interface by notifying each of its notifiers in turn.
+type MultiNotifier []Notifier
+
+func (n MultiNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	var errs []error
+	for _, notifier := range n {
+		if err := notifier.Notify(ctx, approvedBalls); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
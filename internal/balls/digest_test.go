@@ -0,0 +1,205 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type digestSenderFunc func(ctx context.Context, digest Digest) error
+
+func (f digestSenderFunc) Notify(context.Context, []Ball) error {
+	return errors.New("expected digest to be sent with NotifyDigest")
+}
+
+func (f digestSenderFunc) NotifyDigest(ctx context.Context, digest Digest) error {
+	return f(ctx, digest)
+}
+
+func TestDigestNotifier_Send(t *testing.T) {
+	now := time.Now().Truncate(time.Microsecond)
+	approved := []Ball{
+		{Brand: Storm, Name: "Phaze II", ApprovalDate: now},
+		{Brand: RotoGrip, Name: "Idol", ApprovalDate: now},
+		{Brand: Motiv, Name: "Venom Shock", ApprovalDate: now.Add(-time.Hour)},
+	}
+
+	t.Run("sends accumulated balls as a single digest", func(t *testing.T) {
+		store := NewMemoryStore()
+		var sent []Digest
+		d := NewDigestNotifier(slog.Default(), "daily", store, digestSenderFunc(func(_ context.Context, digest Digest) error {
+			sent = append(sent, digest)
+			return nil
+		}), intervalSchedule(time.Hour))
+
+		ctx := context.Background()
+		if err := d.Notify(ctx, approved[:2]); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Notify(ctx, approved[2:]); err != nil {
+			t.Fatal(err)
+		}
+		if len(sent) != 0 {
+			t.Fatal("expected nothing to be sent until the digest is due")
+		}
+
+		if err := d.Send(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if len(sent) != 1 {
+			t.Fatalf("expected 1 digest got %d", len(sent))
+		}
+		assertBalls(t, sent[0].Balls, approved)
+		if got := len(sent[0].Groups); got != 2 {
+			t.Fatalf("expected balls grouped by 2 manufacturers got %d", got)
+		}
+
+		if err := d.Send(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if len(sent) != 1 {
+			t.Fatal("expected empty digest not to be sent")
+		}
+	})
+
+	t.Run("falls back to notify", func(t *testing.T) {
+		store := NewMemoryStore()
+		var notified []Ball
+		d := NewDigestNotifier(slog.Default(), "daily", store, &NotifierMock{
+			NotifyFunc: func(_ context.Context, approvedBalls []Ball) error {
+				notified = approvedBalls
+				return nil
+			},
+		}, intervalSchedule(time.Hour))
+
+		ctx := context.Background()
+		if err := d.Notify(ctx, approved); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Send(ctx); err != nil {
+			t.Fatal(err)
+		}
+		assertBalls(t, notified, approved)
+	})
+
+	t.Run("keeps balls when sending fails", func(t *testing.T) {
+		store := NewMemoryStore()
+		d := NewDigestNotifier(slog.Default(), "daily", store, &NotifierMock{
+			NotifyFunc: func(context.Context, []Ball) error {
+				return errors.New("discord unavailable")
+			},
+		}, intervalSchedule(time.Hour))
+
+		ctx := context.Background()
+		if err := d.Notify(ctx, approved); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Send(ctx); err == nil {
+			t.Fatal("expected error got nil")
+		}
+
+		pending, err := store.TakeDigestBalls(ctx, "daily")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertBalls(t, pending, approved)
+	})
+}
+
+func TestDigestNotifier_Start(t *testing.T) {
+	store := NewMemoryStore()
+	sent := make(chan Digest, 1)
+	d := NewDigestNotifier(slog.Default(), "daily", store, digestSenderFunc(func(_ context.Context, digest Digest) error {
+		sent <- digest
+		return nil
+	}), intervalSchedule(5*time.Millisecond))
+
+	if err := d.Notify(context.Background(), []Ball{{Brand: Storm, Name: "Phaze II", ApprovalDate: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Start(context.Background())
+	defer d.Stop()
+
+	select {
+	case digest := <-sent:
+		if len(digest.Balls) != 1 {
+			t.Fatalf("expected 1 ball got %d", len(digest.Balls))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected digest to be sent on schedule")
+	}
+}
+
+func Test_handleSendDigest(t *testing.T) {
+	store := NewMemoryStore()
+	var sent int
+	d := NewDigestNotifier(slog.Default(), "daily", store, digestSenderFunc(func(context.Context, Digest) error {
+		sent++
+		return nil
+	}), intervalSchedule(time.Hour))
+	if err := d.Notify(context.Background(), []Ball{{Brand: Storm, Name: "Phaze II", ApprovalDate: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHTTPHandler(slog.Default(), NewService(slog.Default(), store, nil, nil), "test",
+		WithDigests(d),
+		WithAdminToken("secret"),
+	)
+
+	request := func(method, target, token string) int {
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if got := request(http.MethodGet, "/v1/digests/daily", "secret"); got != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d for a GET got %d", http.StatusMethodNotAllowed, got)
+	}
+	if got := request(http.MethodPost, "/v1/digests/daily", ""); got != http.StatusUnauthorized {
+		t.Errorf("expected status %d without the admin token got %d", http.StatusUnauthorized, got)
+	}
+	if got := request(http.MethodPost, "/v1/digests/weekly", "secret"); got != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown digest got %d", http.StatusNotFound, got)
+	}
+	if sent != 0 {
+		t.Fatal("expected the digest not to be sent")
+	}
+
+	if got := request(http.MethodPost, "/v1/digests/daily", "secret"); got != http.StatusNoContent {
+		t.Fatalf("expected status %d got %d", http.StatusNoContent, got)
+	}
+	if sent != 1 {
+		t.Fatalf("expected the digest to be sent once got %d", sent)
+	}
+}
+
+func TestMultiNotifier_Notify(t *testing.T) {
+	var calls int
+	notifier := &NotifierMock{
+		NotifyFunc: func(context.Context, []Ball) error {
+			calls++
+			return nil
+		},
+	}
+	failing := &NotifierMock{
+		NotifyFunc: func(context.Context, []Ball) error {
+			return errors.New("boom")
+		},
+	}
+
+	err := MultiNotifier{notifier, failing, notifier}.Notify(context.Background(), []Ball{{Brand: Storm, Name: "Phaze II"}})
+	if err == nil {
+		t.Fatal("expected error got nil")
+	}
+	if calls != 2 {
+		t.Fatalf("expected every notifier to be notified despite errors got %d calls", calls)
+	}
+}
@@ -0,0 +1,356 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DiscordCommands handles the bot's /ball slash commands.
+type DiscordCommands struct {
+	logger   *slog.Logger
+	svc      Service
+	styles   BrandStyles
+	renderer *Renderer
+	store    Store
+}
+
+// DiscordCommandsOption configures the discord commands.
+type DiscordCommandsOption interface {
+	apply(*DiscordCommands)
+}
+
+type commandStylesOption BrandStyles
+
+func (o commandStylesOption) apply(c *DiscordCommands) {
+	c.styles = BrandStyles(o)
+}
+
+// WithCommandBrandStyles overrides the registry's styling of brands in command responses.
+func WithCommandBrandStyles(styles BrandStyles) DiscordCommandsOption {
+	return commandStylesOption(styles)
+}
+
+type commandRendererOption struct {
+	renderer *Renderer
+}
+
+func (o commandRendererOption) apply(c *DiscordCommands) {
+	c.renderer = o.renderer
+}
+
+// WithCommandRenderer sets the templates the balls in command responses are rendered with, DefaultMessageTemplates
+// are used otherwise.
+func WithCommandRenderer(r *Renderer) DiscordCommandsOption {
+	return commandRendererOption{renderer: r}
+}
+
+type commandStoreOption struct {
+	store Store
+}
+
+func (o commandStoreOption) apply(c *DiscordCommands) {
+	c.store = o.store
+}
+
+// WithCommandStore stores the roles created by the follow command in store, following brands is unavailable
+// without it.
+func WithCommandStore(store Store) DiscordCommandsOption {
+	return commandStoreOption{store: store}
+}
+
+func NewDiscordCommands(logger *slog.Logger, svc Service, opts ...DiscordCommandsOption) *DiscordCommands {
+	c := &DiscordCommands{logger: logger, svc: svc}
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+	if c.renderer == nil {
+		c.renderer = defaultRenderer
+	}
+
+	return c
+}
+
+const (
+	// searchCommandLimit is the number of results shown by the search command.
+	searchCommandLimit = 5
+	// interactionTimeout is how long discord waits for the first response to an interaction.
+	interactionTimeout = 3 * time.Second
+	// interactionEditTimeout is how long a command can take once its response is deferred. Discord accepts the
+	// response for 15 minutes but no one waits that long for a command.
+	interactionEditTimeout = time.Minute
+)
+
+// ApplicationCommands returns the slash commands handled by c.
+func (c *DiscordCommands) ApplicationCommands() []*discordgo.ApplicationCommand {
+	return []*discordgo.ApplicationCommand{
+		{
+			Name:        "ball",
+			Description: "USBC approved ball list",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "search",
+					Description: "Search approved balls by name",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "query",
+							Description: "Ball name, e.g. phaze 2",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "follow",
+					Description: "Get pinged when balls of a brand are approved",
+					Options:     []*discordgo.ApplicationCommandOption{followOption()},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "unfollow",
+					Description: "Stop getting pinged when balls of a brand are approved",
+					Options:     []*discordgo.ApplicationCommandOption{followOption()},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "subscribe",
+					Description: "Get a direct message when matching balls are approved",
+					Options:     subscriptionOptions(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "unsubscribe",
+					Description: "Stop getting direct messages for matching balls",
+					Options:     subscriptionOptions(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "subscriptions",
+					Description: "List your subscriptions",
+				},
+			},
+		},
+	}
+}
+
+func followOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "brand",
+		Description: "Brand, or manufacturer for all of its brands",
+		Required:    true,
+		Choices:     followChoices(),
+	}
+}
+
+// Register registers the slash commands with discord and handles their interactions. The session must be opened
+// for interactions to be received.
+func (c *DiscordCommands) Register(dg *discordgo.Session) error {
+	app, err := dg.Application("@me")
+	if err != nil {
+		return fmt.Errorf("getting application: %w", err)
+	}
+
+	if _, err = dg.ApplicationCommandBulkOverwrite(app.ID, "", c.ApplicationCommands()); err != nil {
+		return fmt.Errorf("registering commands: %w", err)
+	}
+
+	dg.AddHandler(c.HandleInteraction)
+
+	return nil
+}
+
+// HandleInteraction responds to the slash commands handled by c, ignoring any other interactions.
+func (c *DiscordCommands) HandleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+	data := i.ApplicationCommandData()
+	if data.Name != "ball" || len(data.Options) == 0 {
+		return
+	}
+
+	var handle func(ctx context.Context) *discordgo.InteractionResponse
+	switch sub := data.Options[0]; sub.Name {
+	case "search":
+		handle = func(ctx context.Context) *discordgo.InteractionResponse {
+			return c.search(ctx, optionString(sub.Options, "query"))
+		}
+
+	case "follow", "unfollow":
+		handle = func(ctx context.Context) *discordgo.InteractionResponse {
+			return c.handleFollow(ctx, s, i, sub.Name == "follow", optionString(sub.Options, "brand"))
+		}
+
+	case "subscribe", "unsubscribe":
+		user := interactionUser(i)
+		if user == nil {
+			return
+		}
+		subscription := Subscription{
+			UserID:       user.ID,
+			Brand:        Brand(optionString(sub.Options, "brand")),
+			Manufacturer: Manufacturer(optionString(sub.Options, "manufacturer")),
+			Pattern:      NormalizeName(optionString(sub.Options, "name")),
+			CreatedAt:    time.Now(),
+		}
+		handle = func(ctx context.Context) *discordgo.InteractionResponse {
+			if sub.Name == "subscribe" {
+				return c.subscribe(ctx, subscription)
+			}
+			return c.unsubscribe(ctx, subscription)
+		}
+
+	case "subscriptions":
+		user := interactionUser(i)
+		if user == nil {
+			return
+		}
+		handle = func(ctx context.Context) *discordgo.InteractionResponse {
+			return c.subscriptions(ctx, user.ID)
+		}
+
+	default:
+		return
+	}
+
+	// Search results are posted in the channel, the other commands only answer the user.
+	c.respond(s, i, data.Options[0].Name == "search", handle)
+}
+
+// respond defers the response to i, so discord doesn't give up on it while handle talks to the store and discord,
+// then edits the deferred response into handle's. A deferred response keeps its visibility, so an ephemeral
+// response to a public command, such as an error, replaces it with an ephemeral follow up.
+func (c *DiscordCommands) respond(
+	s *discordgo.Session,
+	i *discordgo.InteractionCreate,
+	public bool,
+	handle func(ctx context.Context) *discordgo.InteractionResponse,
+) {
+	deferred := &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{},
+	}
+	if !public {
+		deferred.Data.Flags = discordgo.MessageFlagsEphemeral
+	}
+	deferCtx, cancelDefer := context.WithTimeout(context.Background(), interactionTimeout)
+	defer cancelDefer()
+	if err := s.InteractionRespond(i.Interaction, deferred, discordgo.WithContext(deferCtx)); err != nil {
+		c.logger.ErrorContext(deferCtx, "error deferring interaction response", slog.Any("error", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), interactionEditTimeout)
+	defer cancel()
+
+	resp := handle(ctx).Data
+	var err error
+	if public && resp.Flags&discordgo.MessageFlagsEphemeral != 0 {
+		if err = s.InteractionResponseDelete(i.Interaction, discordgo.WithContext(ctx)); err == nil {
+			_, err = s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+				Content: resp.Content,
+				Embeds:  resp.Embeds,
+				Flags:   resp.Flags,
+			}, discordgo.WithContext(ctx))
+		}
+	} else {
+		edit := &discordgo.WebhookEdit{Content: &resp.Content}
+		if len(resp.Embeds) > 0 {
+			edit.Embeds = &resp.Embeds
+		}
+		_, err = s.InteractionResponseEdit(i.Interaction, edit, discordgo.WithContext(ctx))
+	}
+	if err != nil {
+		c.logger.ErrorContext(ctx, "error responding to interaction", slog.Any("error", err))
+	}
+}
+
+func (c *DiscordCommands) search(ctx context.Context, query string) *discordgo.InteractionResponse {
+	results, err := c.svc.SearchBalls(ctx, query, searchCommandLimit)
+	if errors.Is(err, ErrEmptyQuery) {
+		return ephemeralResponse("Search for a ball by name, e.g. `/ball search phaze 2`.")
+	}
+	if err != nil {
+		c.logger.ErrorContext(ctx, "error searching balls", slog.Any("error", err))
+		return ephemeralResponse("Something went wrong searching, try again later.")
+	}
+
+	if len(results) == 0 {
+		return ephemeralResponse(fmt.Sprintf("No approved balls found matching %q.", query))
+	}
+
+	found := make([]Ball, 0, len(results))
+	for _, res := range results {
+		found = append(found, res.Ball)
+	}
+	messages, err := c.renderer.RenderBalls(ctx, found)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "error rendering search results", slog.Any("error", err))
+		return ephemeralResponse("Something went wrong searching, try again later.")
+	}
+
+	embeds := make([]*discordgo.MessageEmbed, 0, len(found))
+	for i, b := range found {
+		embed := ballEmbed(b, c.styles.Style(b.Brand))
+		embed.Title = truncate(messages[i].Title, maxEmbedTitle)
+		embed.Description = truncate(messages[i].Description, maxEmbedDescription)
+		embeds = append(embeds, embed)
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: embeds,
+		},
+	}
+}
+
+func (c *DiscordCommands) handleFollow(
+	ctx context.Context,
+	s *discordgo.Session,
+	i *discordgo.InteractionCreate,
+	follow bool,
+	brand string,
+) *discordgo.InteractionResponse {
+	if i.GuildID == "" || i.Member == nil || i.Member.User == nil {
+		return ephemeralResponse("Brands can only be followed in a server.")
+	}
+
+	target, ok := parseFollowTarget(i.GuildID, brand)
+	if !ok {
+		return ephemeralResponse(fmt.Sprintf("Unknown brand %q.", brand))
+	}
+
+	if follow {
+		return c.follow(ctx, s, i.Member.User.ID, target)
+	}
+
+	return c.unfollow(ctx, s, i.Member.User.ID, target)
+}
+
+func ephemeralResponse(content string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}
+}
+
+func optionString(opts []*discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	for _, opt := range opts {
+		if opt.Name == name {
+			return opt.StringValue()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,168 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiscordCommands_HandleInteraction(t *testing.T) {
+	api := "/api/v" + discordgo.APIVersion
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(r *http.Request, kind string) {
+		var body struct {
+			Type    discordgo.InteractionResponseType `json:"type"`
+			Content string                            `json:"content"`
+			Flags   discordgo.MessageFlags            `json:"flags"`
+			Embeds  []struct {
+				Title string `json:"title"`
+			} `json:"embeds"`
+			Data struct {
+				Flags discordgo.MessageFlags `json:"flags"`
+			} `json:"data"`
+		}
+		if r.Method != http.MethodDelete {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch kind {
+		case "callback":
+			calls = append(calls, fmt.Sprintf("%s type %d flags %d", kind, body.Type, body.Data.Flags))
+		case "delete":
+			calls = append(calls, kind)
+		default:
+			calls = append(calls, fmt.Sprintf("%s %q flags %d", kind, body.Content, body.Flags))
+		}
+		for _, e := range body.Embeds {
+			calls = append(calls, fmt.Sprintf("embed %q", e.Title))
+		}
+	}
+	original := api + "/webhooks/{app}/{token}/messages/@original"
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+api+"/interactions/{id}/{token}/callback", func(w http.ResponseWriter, r *http.Request) {
+		record(r, "callback")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PATCH "+original, func(w http.ResponseWriter, r *http.Request) {
+		record(r, "edit")
+		w.Write([]byte(`{"id": "1"}`))
+	})
+	mux.HandleFunc("DELETE "+original, func(w http.ResponseWriter, r *http.Request) {
+		record(r, "delete")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST "+api+"/webhooks/{app}/{token}", func(w http.ResponseWriter, r *http.Request) {
+		record(r, "followup")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	dg := newFakeDiscordSession(t, mux)
+
+	store := NewMemoryStore()
+	err := store.AddBalls(context.Background(), []Ball{{Brand: Storm, Name: "Phaze II", ApprovalDate: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderer, err := NewRenderer(MessageTemplates{Title: `{{upper .Ball.Name}} by {{.Ball.Brand}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewDiscordCommands(slog.Default(), NewService(slog.Default(), store, nil, nil), WithCommandRenderer(renderer))
+	interaction := func(sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+		return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			ID:    "1",
+			AppID: "app",
+			Type:  discordgo.InteractionApplicationCommand,
+			Token: "token",
+			Data: discordgo.ApplicationCommandInteractionData{
+				Name:    "ball",
+				Options: []*discordgo.ApplicationCommandInteractionDataOption{sub},
+			},
+			User: &discordgo.User{ID: "a"},
+		}}
+	}
+
+	tests := []struct {
+		name string
+		sub  *discordgo.ApplicationCommandInteractionDataOption
+		want []string
+	}{
+		{
+			name: "ephemeral command",
+			sub: &discordgo.ApplicationCommandInteractionDataOption{
+				Name: "follow",
+				Options: []*discordgo.ApplicationCommandInteractionDataOption{
+					{Name: "brand", Type: discordgo.ApplicationCommandOptionString, Value: "brand:Storm"},
+				},
+			},
+			want: []string{
+				fmt.Sprintf("callback type %d flags %d",
+					discordgo.InteractionResponseDeferredChannelMessageWithSource, discordgo.MessageFlagsEphemeral),
+				`edit "Brands can only be followed in a server." flags 0`,
+			},
+		},
+		{
+			name: "subscription command",
+			sub:  &discordgo.ApplicationCommandInteractionDataOption{Name: "subscriptions"},
+			want: []string{
+				fmt.Sprintf("callback type %d flags %d",
+					discordgo.InteractionResponseDeferredChannelMessageWithSource, discordgo.MessageFlagsEphemeral),
+				`edit "Subscriptions aren't available." flags 0`,
+			},
+		},
+		{
+			name: "search",
+			sub: &discordgo.ApplicationCommandInteractionDataOption{
+				Name: "search",
+				Options: []*discordgo.ApplicationCommandInteractionDataOption{
+					{Name: "query", Type: discordgo.ApplicationCommandOptionString, Value: "phaze 2"},
+				},
+			},
+			want: []string{
+				fmt.Sprintf("callback type %d flags 0", discordgo.InteractionResponseDeferredChannelMessageWithSource),
+				`edit "" flags 0`,
+				`embed "PHAZE II by Storm"`,
+			},
+		},
+		{
+			name: "public command answered ephemerally",
+			sub: &discordgo.ApplicationCommandInteractionDataOption{
+				Name: "search",
+				Options: []*discordgo.ApplicationCommandInteractionDataOption{
+					{Name: "query", Type: discordgo.ApplicationCommandOptionString, Value: "quantum"},
+				},
+			},
+			want: []string{
+				fmt.Sprintf("callback type %d flags 0", discordgo.InteractionResponseDeferredChannelMessageWithSource),
+				"delete",
+				fmt.Sprintf(`followup "No approved balls found matching \"quantum\"." flags %d`,
+					discordgo.MessageFlagsEphemeral),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			c.HandleInteraction(dg, interaction(tt.sub))
+
+			if diff := cmp.Diff(calls, tt.want); diff != "" {
+				t.Fatalf("(-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DisabledChannel is a channel that can't be delivered to, e.g. because it was deleted or the bot was removed from
+// it. Disabled channels are skipped until they're enabled again, or until disabledChannelRetry has passed and a
+// delivery to them succeeds.
+type DisabledChannel struct {
+	ChannelID  string
+	Reason     string
+	DisabledAt time.Time
+}
+
+// DeliveryReport describes the outcome of sending a notification to each channel.
+type DeliveryReport struct {
+	Channels []ChannelDelivery
+	// Users are the direct messages sent to subscribed users. Failing to message a user doesn't fail the
+	// notification.
+	Users []UserDelivery
+}
+
+// ChannelDelivery is the outcome of sending a notification to a channel.
+type ChannelDelivery struct {
+	ChannelID string
+	// Batches is the number of messages the notification was split into, Sent is how many were sent.
+	Batches int
+	Sent    int
+	// Skipped is why the channel was skipped without sending, set when it was disabled by an earlier delivery.
+	Skipped string
+	// Err is why sending stopped short of every batch.
+	Err error
+	// Disabled reports whether Err is permanent and the channel has been disabled.
+	Disabled bool
+}
+
+// Delivered returns the number of channels every message was sent to.
+func (r DeliveryReport) Delivered() int {
+	var n int
+	for _, c := range r.Channels {
+		if c.Skipped == "" && c.Err == nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Err returns a *DeliveryError if sending to any channel failed.
+func (r DeliveryReport) Err() error {
+	for _, c := range r.Channels {
+		if c.Err != nil {
+			return &DeliveryError{Report: r}
+		}
+	}
+
+	return nil
+}
+
+// DeliveryError is returned by notifiers that failed to deliver to some of their channels, Report includes the
+// channels that were delivered to.
+type DeliveryError struct {
+	Report DeliveryReport
+}
+
+func (e *DeliveryError) Error() string {
+	var failed []string
+	for _, c := range e.Report.Channels {
+		if c.Err != nil {
+			failed = append(failed, fmt.Sprintf("channel %s after %d of %d messages: %v", c.ChannelID, c.Sent, c.Batches, c.Err))
+		}
+	}
+
+	return fmt.Sprintf("delivery failed to %d of %d channels: %s",
+		len(failed), len(e.Report.Channels), strings.Join(failed, "; "))
+}
+
+func (e *DeliveryError) Unwrap() []error {
+	var errs []error
+	for _, c := range e.Report.Channels {
+		if c.Err != nil {
+			errs = append(errs, c.Err)
+		}
+	}
+
+	return errs
+}
+
+const (
+	// disabledChannelRetry is how long a disabled channel is skipped before it's delivered to again, so channels the
+	// bot regains access to are enabled without intervention. A channel that still fails is disabled for as long
+	// again.
+	disabledChannelRetry = 24 * time.Hour
+)
+
+// deliver sends batches messages to each of channels with send, skipping disabled channels and disabling channels
+// that fail permanently. A failing channel doesn't stop delivery to the others.
+func (n *DiscordNotifier) deliver(
+	ctx context.Context,
+	channels []string,
+	batches int,
+	send func(channelID string, batch int, opts ...discordgo.RequestOption) error,
+) DeliveryReport {
+	disabled := n.disabledChannels(ctx)
+
+	report := DeliveryReport{Channels: make([]ChannelDelivery, 0, len(channels))}
+	for _, id := range channels {
+		delivery := ChannelDelivery{ChannelID: id, Batches: batches}
+		c, wasDisabled := disabled[id]
+		if wasDisabled && time.Since(c.DisabledAt) < disabledChannelRetry {
+			delivery.Skipped = c.Reason
+			report.Channels = append(report.Channels, delivery)
+			continue
+		}
+
+		for i := 0; i < batches; i++ {
+			err := sendWithBackoff(ctx, func() error {
+				return send(id, i, discordgo.WithContext(ctx), discordgo.WithRetryOnRatelimit(false))
+			})
+			if err != nil {
+				delivery.Err = err
+				break
+			}
+			delivery.Sent++
+		}
+
+		switch {
+		case delivery.Err != nil && isPermanentChannelError(delivery.Err):
+			delivery.Disabled = true
+			n.disableChannel(ctx, id, delivery.Err)
+		case delivery.Err == nil && wasDisabled:
+			n.enableChannel(ctx, id)
+		}
+
+		report.Channels = append(report.Channels, delivery)
+	}
+
+	return report
+}
+
+// disabledChannels returns the disabled channels by id. Channels are delivered to as usual if they can't be loaded.
+func (n *DiscordNotifier) disabledChannels(ctx context.Context) map[string]DisabledChannel {
+	if n.store == nil {
+		return nil
+	}
+
+	channels, err := n.store.GetDisabledChannels(ctx)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "error loading disabled channels", slog.Any("error", err))
+		return nil
+	}
+
+	disabled := make(map[string]DisabledChannel, len(channels))
+	for _, c := range channels {
+		disabled[c.ChannelID] = c
+	}
+
+	return disabled
+}
+
+func (n *DiscordNotifier) disableChannel(ctx context.Context, channelID string, reason error) {
+	n.logger.WarnContext(ctx, "disabling discord channel", slog.String("channel_id", channelID), slog.Any("reason", reason))
+
+	if n.store == nil {
+		return
+	}
+
+	err := n.store.DisableChannel(ctx, DisabledChannel{
+		ChannelID:  channelID,
+		Reason:     reason.Error(),
+		DisabledAt: time.Now(),
+	})
+	if err != nil {
+		n.logger.ErrorContext(ctx, "error disabling channel", slog.String("channel_id", channelID), slog.Any("error", err))
+	}
+}
+
+func (n *DiscordNotifier) enableChannel(ctx context.Context, channelID string) {
+	n.logger.InfoContext(ctx, "enabling discord channel", slog.String("channel_id", channelID))
+
+	if err := n.store.EnableChannel(ctx, channelID); err != nil {
+		n.logger.ErrorContext(ctx, "error enabling channel", slog.String("channel_id", channelID), slog.Any("error", err))
+	}
+}
+
+// sendWithBackoff calls send, waiting out and retrying discord's rate limits.
+func sendWithBackoff(ctx context.Context, send func() error) error {
+	return retryRateLimits(ctx, func(attempt int) (bool, time.Duration, error) {
+		err := send()
+
+		var rateLimitErr *discordgo.RateLimitError
+		if !errors.As(err, &rateLimitErr) {
+			return false, 0, err
+		}
+		wait := rateLimitErr.RetryAfter
+		if wait <= 0 {
+			wait = time.Second << attempt
+		}
+		return true, wait, err
+	})
+}
+
+// isPermanentChannelError reports whether err means the channel can't be sent to until someone intervenes, e.g.
+// it was deleted or the bot lost access to it.
+func isPermanentChannelError(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) {
+		return false
+	}
+
+	if restErr.Message != nil {
+		switch restErr.Message.Code {
+		case discordgo.ErrCodeUnknownChannel, discordgo.ErrCodeMissingAccess, discordgo.ErrCodeMissingPermissions:
+			return true
+		}
+	}
+
+	return restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+}
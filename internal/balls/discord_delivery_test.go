@@ -0,0 +1,274 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeDiscord stands in for the discord api, recording the messages sent to each channel.
+type fakeDiscord struct {
+	mu          sync.Mutex
+	sent        map[string]int
+	rateLimited map[string]bool
+}
+
+func (f *fakeDiscord) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	channelID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/v"+discordgo.APIVersion+"/channels/"), "/messages")
+	if !ok || r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case channelID == "deleted":
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": 10003, "message": "Unknown Channel"}`))
+
+	case channelID == "limited" && !f.rateLimited[channelID]:
+		f.rateLimited[channelID] = true
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.01, "global": false}`))
+
+	default:
+		f.sent[channelID]++
+		w.Write([]byte(`{"id": "1", "channel_id": "` + channelID + `"}`))
+	}
+}
+
+// newFakeDiscordSession returns a session sending its requests to f.
+func newFakeDiscordSession(t *testing.T, f http.Handler) *discordgo.Session {
+	t.Helper()
+
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dg, err := discordgo.New("Bot token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dg.Client = &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r = r.Clone(r.Context())
+			r.URL.Scheme = target.Scheme
+			r.URL.Host = target.Host
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+	}
+
+	return dg
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestDiscordNotifier_Deliver(t *testing.T) {
+	fake := &fakeDiscord{sent: make(map[string]int), rateLimited: make(map[string]bool)}
+	store := NewMemoryStore()
+	n := NewDiscordNotifier(newFakeDiscordSession(t, fake), []string{"deleted", "limited", "ok"},
+		WithBatchSize(1),
+		WithChannelStore(store),
+	)
+
+	approved := []Ball{
+		{Brand: Storm, Name: "Phaze II", ImageURL: &url.URL{Scheme: "https", Host: "some-url"}, ApprovalDate: time.Now()},
+		{Brand: Motiv, Name: "Venom Shock", ImageURL: &url.URL{Scheme: "https", Host: "some-url"}, ApprovalDate: time.Now()},
+	}
+
+	report, err := n.Deliver(context.Background(), approved)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Channels) != 3 {
+		t.Fatalf("expected a delivery per channel got %+v", report.Channels)
+	}
+	deleted, limited, ok := report.Channels[0], report.Channels[1], report.Channels[2]
+	if deleted.Err == nil || !deleted.Disabled || deleted.Sent != 0 {
+		t.Errorf("expected deleted channel to fail and be disabled got %+v", deleted)
+	}
+	if limited.Err != nil || limited.Sent != 2 {
+		t.Errorf("expected rate limited channel to be retried got %+v", limited)
+	}
+	if ok.Err != nil || ok.Sent != 2 {
+		t.Errorf("expected channel after the failure to be delivered to got %+v", ok)
+	}
+	if report.Delivered() != 2 {
+		t.Errorf("expected 2 channels delivered got %d", report.Delivered())
+	}
+
+	var deliveryErr *DeliveryError
+	if err := report.Err(); !errors.As(err, &deliveryErr) {
+		t.Fatalf("expected delivery error got %v", err)
+	}
+
+	disabled, err := store.GetDisabledChannels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(disabled) != 1 || disabled[0].ChannelID != "deleted" || !strings.Contains(disabled[0].Reason, "Unknown Channel") {
+		t.Fatalf("expected deleted channel to be persisted with its reason got %+v", disabled)
+	}
+
+	if err = n.Notify(context.Background(), approved); err != nil {
+		t.Fatalf("expected disabled channel to be skipped got %v", err)
+	}
+	if fake.sent["ok"] != 4 {
+		t.Fatalf("expected 4 messages sent to ok got %d", fake.sent["ok"])
+	}
+}
+
+func TestDiscordNotifier_Deliver_disabledChannels(t *testing.T) {
+	fake := &fakeDiscord{sent: make(map[string]int), rateLimited: make(map[string]bool)}
+	store := NewMemoryStore()
+	n := NewDiscordNotifier(newFakeDiscordSession(t, fake), []string{"recent", "restored", "deleted"},
+		WithChannelStore(store),
+	)
+
+	ctx := context.Background()
+	stale := time.Now().Add(-disabledChannelRetry - time.Minute)
+	for _, c := range []DisabledChannel{
+		{ChannelID: "recent", Reason: "Missing Access", DisabledAt: time.Now()},
+		{ChannelID: "restored", Reason: "Missing Permissions", DisabledAt: stale},
+		{ChannelID: "deleted", Reason: "Unknown Channel", DisabledAt: stale},
+	} {
+		if err := store.DisableChannel(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := n.Deliver(ctx, []Ball{{Brand: Storm, Name: "Phaze II", ApprovalDate: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recent, restored, deleted := report.Channels[0], report.Channels[1], report.Channels[2]
+	if recent.Skipped != "Missing Access" || fake.sent["recent"] != 0 {
+		t.Errorf("expected recently disabled channel to be skipped got %+v", recent)
+	}
+	if restored.Skipped != "" || restored.Err != nil || fake.sent["restored"] != 1 {
+		t.Errorf("expected channel disabled a day ago to be retried got %+v", restored)
+	}
+	if !deleted.Disabled {
+		t.Errorf("expected channel still failing to be disabled again got %+v", deleted)
+	}
+
+	disabled, err := store.GetDisabledChannels(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]DisabledChannel, len(disabled))
+	for _, c := range disabled {
+		got[c.ChannelID] = c
+	}
+	if _, ok := got["restored"]; ok || len(got) != 2 {
+		t.Fatalf("expected the retried channel to be enabled got %+v", disabled)
+	}
+	if !got["deleted"].DisabledAt.After(stale) {
+		t.Errorf("expected the still failing channel to be disabled for another day got %+v", got["deleted"])
+	}
+}
+
+func TestHTTPHandler_disabledChannels(t *testing.T) {
+	store := NewMemoryStore()
+	disabledAt := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range []string{"2", "1"} {
+		err := store.DisableChannel(context.Background(), DisabledChannel{ChannelID: id, Reason: "Missing Access", DisabledAt: disabledAt})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewHTTPHandler(slog.Default(), NewService(slog.Default(), store, nil, nil), "test",
+		WithDisabledChannelStore(store),
+		WithAdminToken("secret"),
+	)
+
+	request := func(method, target, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request(http.MethodDelete, "/v1/channels/disabled/1", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d without the admin token got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	if rec := request(http.MethodDelete, "/v1/channels/disabled/1", "secret"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d got %d", http.StatusNoContent, rec.Code)
+	}
+
+	rec := request(http.MethodGet, "/v1/channels/disabled", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d", http.StatusOK, rec.Code)
+	}
+	var resp struct {
+		Channels []disabledChannelResponse `json:"channels"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	want := []disabledChannelResponse{{ChannelID: "2", Reason: "Missing Access", DisabledAt: disabledAt}}
+	if diff := cmp.Diff(resp.Channels, want); diff != "" {
+		t.Fatalf("(-got, +want):\n%s", diff)
+	}
+}
+
+func Test_sendWithBackoff(t *testing.T) {
+	t.Run("gives up after max retries", func(t *testing.T) {
+		var calls int
+		err := sendWithBackoff(context.Background(), func() error {
+			calls++
+			return &discordgo.RateLimitError{RateLimit: &discordgo.RateLimit{
+				TooManyRequests: &discordgo.TooManyRequests{RetryAfter: time.Microsecond},
+			}}
+		})
+		if err == nil {
+			t.Fatal("expected error got nil")
+		}
+		if calls != maxRateLimitRetries+1 {
+			t.Fatalf("expected %d attempts got %d", maxRateLimitRetries+1, calls)
+		}
+	})
+
+	t.Run("stops waiting when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := sendWithBackoff(ctx, func() error {
+			return &discordgo.RateLimitError{RateLimit: &discordgo.RateLimit{
+				TooManyRequests: &discordgo.TooManyRequests{RetryAfter: time.Hour},
+			}}
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled got %v", err)
+		}
+	})
+}
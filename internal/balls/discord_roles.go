@@ -0,0 +1,300 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// BrandRole is the discord role pinged in a guild when balls of a brand, or of any brand a manufacturer owns, are
+// approved. Exactly one of Brand and Manufacturer is set.
+type BrandRole struct {
+	GuildID      string
+	Brand        Brand
+	Manufacturer Manufacturer
+	RoleID       string
+}
+
+// Matches reports whether b is followed by the role.
+func (r BrandRole) Matches(b Ball) bool {
+	if r.Brand != "" {
+		return b.Brand == r.Brand
+	}
+
+	return r.Manufacturer != "" && b.Brand.Manufacturer() == r.Manufacturer
+}
+
+// Name returns what the role follows, e.g. Storm.
+func (r BrandRole) Name() string {
+	if r.Brand != "" {
+		return string(r.Brand)
+	}
+
+	return string(r.Manufacturer)
+}
+
+func (r BrandRole) sameTarget(o BrandRole) bool {
+	return r.GuildID == o.GuildID && r.Brand == o.Brand && r.Manufacturer == o.Manufacturer
+}
+
+// Prefixes of the values of follow command choices, telling brands and manufacturers apart.
+const (
+	followBrandPrefix        = "brand:"
+	followManufacturerPrefix = "manufacturer:"
+)
+
+// followChoices returns the choices of the follow commands, every brand and the manufacturers that own more than
+// their namesake brand.
+func followChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, m := range Manufacturers() {
+		brands := m.Brands()
+		for _, b := range brands {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  string(b),
+				Value: followBrandPrefix + string(b),
+			})
+		}
+		if ownsOtherBrands(m) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  fmt.Sprintf("%s (all brands)", m),
+				Value: followManufacturerPrefix + string(m),
+			})
+		}
+	}
+
+	return choices
+}
+
+// ownsOtherBrands reports whether m owns more than its namesake brand, so choosing it differs from choosing a brand.
+func ownsOtherBrands(m Manufacturer) bool {
+	brands := m.Brands()
+	return len(brands) > 1 || (len(brands) == 1 && string(brands[0]) != string(m))
+}
+
+// parseFollowTarget returns the role following the brand or manufacturer of a follow command choice in guildID.
+func parseFollowTarget(guildID, value string) (BrandRole, bool) {
+	role := BrandRole{GuildID: guildID}
+	if brand, ok := strings.CutPrefix(value, followBrandPrefix); ok {
+		role.Brand = Brand(brand)
+		_, known := LookupBrand(role.Brand)
+		return role, known
+	}
+	if m, ok := strings.CutPrefix(value, followManufacturerPrefix); ok {
+		role.Manufacturer = Manufacturer(m)
+		return role, len(role.Manufacturer.Brands()) > 0
+	}
+
+	return BrandRole{}, false
+}
+
+// follow gives userID the role following target in its guild, creating the role if the guild doesn't have one.
+func (c *DiscordCommands) follow(
+	ctx context.Context,
+	s *discordgo.Session,
+	userID string,
+	target BrandRole,
+) *discordgo.InteractionResponse {
+	if c.store == nil {
+		return ephemeralResponse("Following brands isn't available.")
+	}
+
+	// A role deleted from the guild is replaced by a new one, so adding the role is tried twice.
+	for attempt := 0; attempt < 2; attempt++ {
+		role, err := c.guildRole(ctx, s, target)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "error getting brand role", slog.String("brand", target.Name()), slog.Any("error", err))
+			return ephemeralResponse("Something went wrong, check the bot can manage roles and try again later.")
+		}
+
+		err = s.GuildMemberRoleAdd(role.GuildID, userID, role.RoleID, discordgo.WithContext(ctx))
+		if isUnknownRole(err) {
+			if err = c.store.RemoveBrandRole(ctx, role); err == nil {
+				continue
+			}
+		}
+		if err != nil {
+			c.logger.ErrorContext(ctx, "error adding brand role", slog.String("brand", target.Name()), slog.Any("error", err))
+			return ephemeralResponse("Something went wrong, check the bot can manage roles and try again later.")
+		}
+
+		return ephemeralResponse(fmt.Sprintf("You'll be pinged when new %s balls are approved.", target.Name()))
+	}
+
+	return ephemeralResponse("Something went wrong, try again later.")
+}
+
+// unfollow takes the role following target in its guild from userID.
+func (c *DiscordCommands) unfollow(
+	ctx context.Context,
+	s *discordgo.Session,
+	userID string,
+	target BrandRole,
+) *discordgo.InteractionResponse {
+	if c.store == nil {
+		return ephemeralResponse("Following brands isn't available.")
+	}
+
+	roles, err := c.store.GetBrandRoles(ctx, target.GuildID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "error getting brand roles", slog.Any("error", err))
+		return ephemeralResponse("Something went wrong, try again later.")
+	}
+
+	for _, role := range roles {
+		if !role.sameTarget(target) {
+			continue
+		}
+
+		err = s.GuildMemberRoleRemove(role.GuildID, userID, role.RoleID, discordgo.WithContext(ctx))
+		if err != nil && !isUnknownRole(err) {
+			c.logger.ErrorContext(ctx, "error removing brand role", slog.String("brand", target.Name()), slog.Any("error", err))
+			return ephemeralResponse("Something went wrong, try again later.")
+		}
+	}
+
+	return ephemeralResponse(fmt.Sprintf("You won't be pinged for new %s balls anymore.", target.Name()))
+}
+
+// guildRole returns the guild's role following target, creating it if there isn't one.
+func (c *DiscordCommands) guildRole(ctx context.Context, s *discordgo.Session, target BrandRole) (BrandRole, error) {
+	roles, err := c.store.GetBrandRoles(ctx, target.GuildID)
+	if err != nil {
+		return BrandRole{}, fmt.Errorf("getting roles: %w", err)
+	}
+	for _, r := range roles {
+		if r.sameTarget(target) {
+			return r, nil
+		}
+	}
+
+	mentionable := true
+	params := &discordgo.RoleParams{
+		Name:        fmt.Sprintf("%s approvals", target.Name()),
+		Mentionable: &mentionable,
+	}
+	if target.Brand != "" {
+		if color := c.styles.Style(target.Brand).Color; color != 0 {
+			params.Color = &color
+		}
+	}
+
+	created, err := s.GuildRoleCreate(target.GuildID, params, discordgo.WithContext(ctx))
+	if err != nil {
+		return BrandRole{}, fmt.Errorf("creating role: %w", err)
+	}
+
+	target.RoleID = created.ID
+	role, err := c.store.AddBrandRole(ctx, target)
+	if err != nil {
+		return BrandRole{}, fmt.Errorf("adding role: %w", err)
+	}
+
+	if role.RoleID != created.ID {
+		// Another follow created the guild's role first.
+		if err := s.GuildRoleDelete(target.GuildID, created.ID, discordgo.WithContext(ctx)); err != nil {
+			c.logger.ErrorContext(ctx, "error deleting duplicate role", slog.Any("error", err))
+		}
+	}
+
+	return role, nil
+}
+
+func isUnknownRole(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownRole
+}
+
+// roleMentions returns the mentions of the roles in roles following any of balls, and the allowed mentions
+// restricting pings to just those roles.
+func roleMentions(roles []BrandRole, balls []Ball) (string, *discordgo.MessageAllowedMentions) {
+	allowed := &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}}
+
+	var mentions []string
+	for _, r := range roles {
+		for _, b := range balls {
+			if r.Matches(b) {
+				mentions = append(mentions, fmt.Sprintf("<@&%s>", r.RoleID))
+				allowed.Roles = append(allowed.Roles, r.RoleID)
+				break
+			}
+		}
+	}
+
+	return strings.Join(mentions, " "), allowed
+}
+
+// channelRoles returns a func returning the roles of the guild a channel belongs to, loading each channel's roles
+// once. Roles that can't be loaded aren't mentioned.
+func (n *DiscordNotifier) channelRoles(ctx context.Context) func(channelID string) []BrandRole {
+	loaded := make(map[string][]BrandRole)
+	return func(channelID string) []BrandRole {
+		roles, ok := loaded[channelID]
+		if !ok {
+			roles = n.loadChannelRoles(ctx, channelID)
+			loaded[channelID] = roles
+		}
+		return roles
+	}
+}
+
+func (n *DiscordNotifier) loadChannelRoles(ctx context.Context, channelID string) []BrandRole {
+	if n.store == nil {
+		return nil
+	}
+
+	guildID, err := n.channelGuild(ctx, channelID)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "error getting channel guild", slog.String("channel_id", channelID), slog.Any("error", err))
+		return nil
+	}
+	if guildID == "" {
+		return nil
+	}
+
+	roles, err := n.store.GetBrandRoles(ctx, guildID)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "error getting brand roles", slog.String("guild_id", guildID), slog.Any("error", err))
+		return nil
+	}
+
+	return roles
+}
+
+// channelGuild returns the id of the guild channelID belongs to, empty for channels outside of guilds.
+func (n *DiscordNotifier) channelGuild(ctx context.Context, channelID string) (string, error) {
+	ch, err := n.channel(ctx, channelID)
+	if err != nil {
+		return "", err
+	}
+
+	return ch.GuildID, nil
+}
+
+// channel returns the channel with channelID, loading it from discord the first time it isn't in the session's
+// state.
+func (n *DiscordNotifier) channel(ctx context.Context, channelID string) (*discordgo.Channel, error) {
+	n.mu.Lock()
+	ch, ok := n.channelInfo[channelID]
+	n.mu.Unlock()
+	if ok {
+		return ch, nil
+	}
+
+	ch, err := n.dg.State.Channel(channelID)
+	if err != nil {
+		if ch, err = n.dg.Channel(channelID, discordgo.WithContext(ctx)); err != nil {
+			return nil, fmt.Errorf("getting channel: %w", err)
+		}
+	}
+
+	n.mu.Lock()
+	n.channelInfo[channelID] = ch
+	n.mu.Unlock()
+
+	return ch, nil
+}
@@ -0,0 +1,161 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiscordCommands_follow(t *testing.T) {
+	api := "/api/v" + discordgo.APIVersion
+
+	var (
+		mu      sync.Mutex
+		created int
+		members = make(map[string]bool)
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+api+"/guilds/{guild}/roles", func(w http.ResponseWriter, r *http.Request) {
+		var params discordgo.RoleParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Error(err)
+		}
+		if params.Name != "Storm approvals" || params.Mentionable == nil || !*params.Mentionable {
+			t.Errorf("unexpected role params %+v", params)
+		}
+
+		mu.Lock()
+		created++
+		mu.Unlock()
+		w.Write([]byte(`{"id": "new"}`))
+	})
+	mux.HandleFunc("PUT "+api+"/guilds/{guild}/members/{user}/roles/{role}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("role") == "deleted" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 10011, "message": "Unknown Role"}`))
+			return
+		}
+
+		mu.Lock()
+		members[r.PathValue("user")+"/"+r.PathValue("role")] = true
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE "+api+"/guilds/{guild}/members/{user}/roles/{role}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		delete(members, r.PathValue("user")+"/"+r.PathValue("role"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	dg := newFakeDiscordSession(t, mux)
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	c := NewDiscordCommands(slog.Default(), nil, WithCommandStore(store))
+
+	t.Run("creates missing role", func(t *testing.T) {
+		target, _ := parseFollowTarget("1", followBrandPrefix+string(Storm))
+		c.follow(ctx, dg, "a", target)
+		c.follow(ctx, dg, "b", target)
+
+		if created != 1 {
+			t.Fatalf("expected role to be created once got %d", created)
+		}
+		if !members["a/new"] || !members["b/new"] {
+			t.Fatalf("expected both users to get the role got %v", members)
+		}
+	})
+
+	t.Run("replaces deleted role", func(t *testing.T) {
+		if _, err := store.AddBrandRole(ctx, BrandRole{GuildID: "2", Brand: Storm, RoleID: "deleted"}); err != nil {
+			t.Fatal(err)
+		}
+
+		target, _ := parseFollowTarget("2", followBrandPrefix+string(Storm))
+		resp := c.follow(ctx, dg, "a", target)
+		if !strings.Contains(resp.Data.Content, "You'll be pinged") {
+			t.Fatalf("unexpected response %q", resp.Data.Content)
+		}
+
+		roles, err := store.GetBrandRoles(ctx, "2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(roles, []BrandRole{{GuildID: "2", Brand: Storm, RoleID: "new"}}); diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("unfollow", func(t *testing.T) {
+		target, _ := parseFollowTarget("1", followBrandPrefix+string(Storm))
+		c.unfollow(ctx, dg, "a", target)
+
+		if members["a/new"] || !members["b/new"] {
+			t.Fatalf("expected only a to lose the role got %v", members)
+		}
+	})
+}
+
+func TestDiscordNotifier_Deliver_roleMentions(t *testing.T) {
+	api := "/api/v" + discordgo.APIVersion
+
+	var (
+		mu   sync.Mutex
+		sent []discordgo.MessageSend
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+api+"/channels/{channel}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "` + r.PathValue("channel") + `", "guild_id": "1"}`))
+	})
+	mux.HandleFunc("POST "+api+"/channels/{channel}/messages", func(w http.ResponseWriter, r *http.Request) {
+		var msg discordgo.MessageSend
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Error(err)
+		}
+
+		mu.Lock()
+		sent = append(sent, msg)
+		mu.Unlock()
+		w.Write([]byte(`{"id": "1"}`))
+	})
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for _, r := range []BrandRole{
+		{GuildID: "1", Brand: Motiv, RoleID: "motiv"},
+		{GuildID: "1", Manufacturer: StormProducts, RoleID: "storm"},
+		{GuildID: "2", Brand: Storm, RoleID: "other"},
+	} {
+		if _, err := store.AddBrandRole(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n := NewDiscordNotifier(newFakeDiscordSession(t, mux), []string{"c"}, WithBatchSize(1), WithChannelStore(store))
+	err := n.Notify(ctx, []Ball{
+		{Brand: RotoGrip, Name: "Idol", ImageURL: &url.URL{Scheme: "https", Host: "some-url"}, ApprovalDate: time.Now()},
+		{Brand: Hammer, Name: "Black Widow", ImageURL: &url.URL{Scheme: "https", Host: "some-url"}, ApprovalDate: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 messages got %d", len(sent))
+	}
+	if sent[0].Content != "<@&storm>" || cmp.Diff(sent[0].AllowedMentions.Roles, []string{"storm"}) != "" {
+		t.Errorf("expected storm role mentioned got %q, %+v", sent[0].Content, sent[0].AllowedMentions)
+	}
+	if sent[1].Content != "" || len(sent[1].AllowedMentions.Roles) != 0 || sent[1].AllowedMentions.Parse == nil {
+		t.Errorf("expected no mentions allowed got %q, %+v", sent[1].Content, sent[1].AllowedMentions)
+	}
+}
@@ -0,0 +1,330 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Subscription is a discord user's request to be sent a direct message when balls matching it are approved.
+type Subscription struct {
+	UserID string
+	// Brand limits the subscription to a brand, empty for every brand.
+	Brand Brand
+	// Manufacturer limits the subscription to the brands a manufacturer owns, at most one of Brand and Manufacturer
+	// is set.
+	Manufacturer Manufacturer
+	// Pattern is the normalized text the names of matching balls contain, see NormalizeName.
+	Pattern   string
+	CreatedAt time.Time
+}
+
+// Matches reports whether b is one of the balls the subscription is for.
+func (s Subscription) Matches(b Ball) bool {
+	if s.Brand != "" && b.Brand != s.Brand {
+		return false
+	}
+	if s.Manufacturer != "" && b.Brand.Manufacturer() != s.Manufacturer {
+		return false
+	}
+
+	return containsWords(NormalizeName(b.Name), s.Pattern)
+}
+
+// String describes the subscription, e.g. "phaze" from Storm.
+func (s Subscription) String() string {
+	switch {
+	case s.Brand != "":
+		return fmt.Sprintf("%q from %s", s.Pattern, s.Brand)
+	case s.Manufacturer != "":
+		return fmt.Sprintf("%q from %s brands", s.Pattern, s.Manufacturer)
+	default:
+		return fmt.Sprintf("%q", s.Pattern)
+	}
+}
+
+func (s Subscription) sameFilter(o Subscription) bool {
+	return s.UserID == o.UserID && s.Brand == o.Brand && s.Manufacturer == o.Manufacturer && s.Pattern == o.Pattern
+}
+
+// containsWords reports whether the normalized name contains the normalized pattern as whole words, so phaze
+// matches "phaze 2" but not "phazer".
+func containsWords(name, pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+
+	return strings.Contains(" "+name+" ", " "+pattern+" ")
+}
+
+// maxUserSubscriptions is how many subscriptions a user can have.
+const maxUserSubscriptions = 25
+
+// brandChoices returns the choices of the subscription commands' brand option.
+func brandChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, m := range Manufacturers() {
+		for _, b := range m.Brands() {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: string(b), Value: string(b)})
+		}
+	}
+
+	return choices
+}
+
+// manufacturerChoices returns the choices of the subscription commands' manufacturer option, the manufacturers
+// that own more than their namesake brand.
+func manufacturerChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, m := range Manufacturers() {
+		if ownsOtherBrands(m) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: string(m), Value: string(m)})
+		}
+	}
+
+	return choices
+}
+
+func subscriptionOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "name",
+			Description: "Text in the ball's name, e.g. phaze",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "brand",
+			Description: "Only balls of this brand",
+			Choices:     brandChoices(),
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "manufacturer",
+			Description: "Only balls of the brands this manufacturer owns",
+			Choices:     manufacturerChoices(),
+		},
+	}
+}
+
+// interactionUser returns the user that triggered i, both in guilds and direct messages.
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+
+	return i.User
+}
+
+func (c *DiscordCommands) subscribe(ctx context.Context, sub Subscription) *discordgo.InteractionResponse {
+	if c.store == nil {
+		return ephemeralResponse("Subscriptions aren't available.")
+	}
+	if sub.Pattern == "" {
+		return ephemeralResponse("Subscribe to a ball's name, e.g. `/ball subscribe phaze`.")
+	}
+	if sub.Brand != "" && sub.Manufacturer != "" {
+		return ephemeralResponse("Subscribe to a brand or a manufacturer, not both.")
+	}
+
+	subs, err := c.store.GetUserSubscriptions(ctx, sub.UserID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "error getting subscriptions", slog.Any("error", err))
+		return ephemeralResponse("Something went wrong, try again later.")
+	}
+	if len(subs) >= maxUserSubscriptions {
+		return ephemeralResponse(fmt.Sprintf(
+			"You can have at most %d subscriptions, unsubscribe from one first.", maxUserSubscriptions,
+		))
+	}
+
+	if err = c.store.AddSubscription(ctx, sub); err != nil {
+		c.logger.ErrorContext(ctx, "error adding subscription", slog.Any("error", err))
+		return ephemeralResponse("Something went wrong, try again later.")
+	}
+
+	return ephemeralResponse(fmt.Sprintf("You'll get a direct message when balls matching %s are approved.", sub))
+}
+
+func (c *DiscordCommands) unsubscribe(ctx context.Context, sub Subscription) *discordgo.InteractionResponse {
+	if c.store == nil {
+		return ephemeralResponse("Subscriptions aren't available.")
+	}
+
+	if err := c.store.RemoveSubscription(ctx, sub); err != nil {
+		c.logger.ErrorContext(ctx, "error removing subscription", slog.Any("error", err))
+		return ephemeralResponse("Something went wrong, try again later.")
+	}
+
+	return ephemeralResponse(fmt.Sprintf("You won't get direct messages for balls matching %s anymore.", sub))
+}
+
+func (c *DiscordCommands) subscriptions(ctx context.Context, userID string) *discordgo.InteractionResponse {
+	if c.store == nil {
+		return ephemeralResponse("Subscriptions aren't available.")
+	}
+
+	subs, err := c.store.GetUserSubscriptions(ctx, userID)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "error getting subscriptions", slog.Any("error", err))
+		return ephemeralResponse("Something went wrong, try again later.")
+	}
+	if len(subs) == 0 {
+		return ephemeralResponse("You don't have any subscriptions, add one with `/ball subscribe`.")
+	}
+
+	lines := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		lines = append(lines, "- "+sub.String())
+	}
+
+	return ephemeralResponse("Your subscriptions:\n" + strings.Join(lines, "\n"))
+}
+
+// UserDelivery is the outcome of sending a direct message to a subscribed user.
+type UserDelivery struct {
+	UserID string
+	// Balls is the number of approved balls matching the user's subscriptions.
+	Balls int
+	Sent  int
+	Err   error
+	// Unsubscribed reports whether the user can't be messaged and their subscriptions were removed.
+	Unsubscribed bool
+}
+
+// deliverDirect sends each subscribed user the embeds of the balls matching their subscriptions. Users that can't
+// be messaged, e.g. because they blocked the bot, are unsubscribed.
+func (n *DiscordNotifier) deliverDirect(
+	ctx context.Context,
+	approvedBalls []Ball,
+	embeds []*discordgo.MessageEmbed,
+) []UserDelivery {
+	if !n.directMessages || n.store == nil {
+		return nil
+	}
+
+	subs, err := n.store.GetSubscriptions(ctx)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "error loading subscriptions", slog.Any("error", err))
+		return nil
+	}
+
+	var (
+		users   []string
+		matched = make(map[string][]*discordgo.MessageEmbed)
+	)
+	for i, b := range approvedBalls {
+		notified := make(map[string]bool)
+		for _, sub := range subs {
+			if notified[sub.UserID] || !sub.Matches(b) {
+				continue
+			}
+			notified[sub.UserID] = true
+			if _, ok := matched[sub.UserID]; !ok {
+				users = append(users, sub.UserID)
+			}
+			matched[sub.UserID] = append(matched[sub.UserID], embeds[i])
+		}
+	}
+
+	deliveries := make([]UserDelivery, 0, len(users))
+	for _, userID := range users {
+		deliveries = append(deliveries, n.sendDirect(ctx, userID, matched[userID]))
+	}
+
+	return deliveries
+}
+
+func (n *DiscordNotifier) sendDirect(ctx context.Context, userID string, embeds []*discordgo.MessageEmbed) UserDelivery {
+	delivery := UserDelivery{UserID: userID, Balls: len(embeds)}
+	opts := []discordgo.RequestOption{discordgo.WithContext(ctx), discordgo.WithRetryOnRatelimit(false)}
+
+	delivery.Err = sendWithBackoff(ctx, func() error {
+		_, err := n.directChannel(userID, opts...)
+		return err
+	})
+
+	for i, batch := range batchSlice(embeds, n.batchSize) {
+		if delivery.Err != nil {
+			break
+		}
+
+		msg := &discordgo.MessageSend{
+			Embeds:          batch,
+			AllowedMentions: &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}},
+		}
+		if i == 0 {
+			msg.Content = "New approvals matching your subscriptions:"
+		}
+
+		delivery.Err = sendWithBackoff(ctx, func() error {
+			channelID, err := n.directChannel(userID, opts...)
+			if err != nil {
+				return err
+			}
+			if _, err = n.dg.ChannelMessageSendComplex(channelID, msg, opts...); err != nil {
+				return fmt.Errorf("sending direct message: %w", err)
+			}
+			return nil
+		})
+		if delivery.Err == nil {
+			delivery.Sent++
+		}
+	}
+
+	if delivery.Err != nil && isUnreachableUser(delivery.Err) {
+		n.logger.WarnContext(ctx, "unsubscribing unreachable user", slog.String("user_id", userID), slog.Any("reason", delivery.Err))
+		if err := n.store.RemoveUserSubscriptions(ctx, userID); err != nil {
+			n.logger.ErrorContext(ctx, "error removing subscriptions", slog.String("user_id", userID), slog.Any("error", err))
+		} else {
+			delivery.Unsubscribed = true
+		}
+	} else if delivery.Err != nil {
+		n.logger.ErrorContext(ctx, "error sending direct message", slog.String("user_id", userID), slog.Any("error", delivery.Err))
+	}
+
+	return delivery
+}
+
+// directChannel returns the id of the direct message channel with userID, creating it the first time.
+func (n *DiscordNotifier) directChannel(userID string, opts ...discordgo.RequestOption) (string, error) {
+	n.mu.Lock()
+	channelID, ok := n.dmChannels[userID]
+	n.mu.Unlock()
+	if ok {
+		return channelID, nil
+	}
+
+	ch, err := n.dg.UserChannelCreate(userID, opts...)
+	if err != nil {
+		return "", fmt.Errorf("creating direct message channel: %w", err)
+	}
+
+	n.mu.Lock()
+	n.dmChannels[userID] = ch.ID
+	n.mu.Unlock()
+
+	return ch.ID, nil
+}
+
+// isUnreachableUser reports whether err means the user can't be sent direct messages, e.g. because they blocked
+// the bot, disabled direct messages or no longer share a server with it.
+func isUnreachableUser(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Message == nil {
+		return false
+	}
+
+	switch restErr.Message.Code {
+	case discordgo.ErrCodeCannotSendMessagesToThisUser, discordgo.ErrCodeUnknownUser:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,166 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSubscription_Matches(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  Subscription
+		ball Ball
+		want bool
+	}{
+		{
+			name: "name contains pattern",
+			sub:  Subscription{Pattern: "phaze"},
+			ball: Ball{Brand: Storm, Name: "Phaze II"},
+			want: true,
+		},
+		{
+			name: "normalized name",
+			sub:  Subscription{Pattern: NormalizeName("phaze 2")},
+			ball: Ball{Brand: Storm, Name: "Phaze II"},
+			want: true,
+		},
+		{
+			name: "partial word",
+			sub:  Subscription{Pattern: "phaze"},
+			ball: Ball{Brand: Storm, Name: "Phazer"},
+			want: false,
+		},
+		{
+			name: "other brand",
+			sub:  Subscription{Brand: Motiv, Pattern: "phaze"},
+			ball: Ball{Brand: Storm, Name: "Phaze II"},
+			want: false,
+		},
+		{
+			name: "brand of manufacturer",
+			sub:  Subscription{Manufacturer: StormProducts, Pattern: "idol"},
+			ball: Ball{Brand: RotoGrip, Name: "Idol"},
+			want: true,
+		},
+		{
+			name: "other manufacturer",
+			sub:  Subscription{Manufacturer: BrunswickBowling, Pattern: "idol"},
+			ball: Ball{Brand: RotoGrip, Name: "Idol"},
+			want: false,
+		},
+		{
+			name: "empty pattern",
+			sub:  Subscription{},
+			ball: Ball{Brand: Storm, Name: "Phaze II"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sub.Matches(tt.ball); got != tt.want {
+				t.Fatalf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscordNotifier_Deliver_directMessages(t *testing.T) {
+	api := "/api/v" + discordgo.APIVersion
+
+	var (
+		mu          sync.Mutex
+		sent        = make(map[string][]discordgo.MessageSend)
+		rateLimited bool
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+api+"/users/@me/channels", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			RecipientID string `json:"recipient_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"id": "dm-` + body.RecipientID + `"}`))
+	})
+	mux.HandleFunc("POST "+api+"/channels/{channel}/messages", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch channelID := r.PathValue("channel"); {
+		case channelID == "dm-blocked":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"code": 50007, "message": "Cannot send messages to this user"}`))
+
+		case channelID == "dm-limited" && !rateLimited:
+			rateLimited = true
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.01, "global": false}`))
+
+		default:
+			var msg discordgo.MessageSend
+			if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+				t.Error(err)
+			}
+			sent[channelID] = append(sent[channelID], msg)
+			w.Write([]byte(`{"id": "1"}`))
+		}
+	})
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for _, sub := range []Subscription{
+		{UserID: "limited", Pattern: "phaze"},
+		{UserID: "limited", Brand: Storm, Pattern: "phaze 2"},
+		{UserID: "blocked", Pattern: "idol"},
+		{UserID: "nobody", Pattern: "venom"},
+	} {
+		if err := store.AddSubscription(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n := NewDiscordNotifier(newFakeDiscordSession(t, mux), nil, WithChannelStore(store), WithDirectMessages())
+	report, err := n.Deliver(ctx, []Ball{
+		{Brand: Storm, Name: "Phaze II", ApprovalDate: time.Now()},
+		{Brand: RotoGrip, Name: "Idol", ApprovalDate: time.Now()},
+		{Brand: Storm, Name: "Phaze 5", ApprovalDate: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = report.Err(); err != nil {
+		t.Fatalf("expected direct message failures not to fail delivery got %v", err)
+	}
+
+	if len(report.Users) != 2 {
+		t.Fatalf("expected 2 users messaged got %+v", report.Users)
+	}
+	limited, blocked := report.Users[0], report.Users[1]
+	if limited.Err != nil || limited.Balls != 2 || limited.Sent != 1 {
+		t.Errorf("expected rate limited user to be sent both balls once got %+v", limited)
+	}
+	if blocked.Err == nil || !blocked.Unsubscribed {
+		t.Errorf("expected blocked user to be unsubscribed got %+v", blocked)
+	}
+
+	msgs := sent["dm-limited"]
+	if len(msgs) != 1 || len(msgs[0].Embeds) != 2 || !strings.Contains(msgs[0].Content, "subscriptions") {
+		t.Fatalf("expected one message with both balls got %+v", msgs)
+	}
+
+	subs, err := store.GetUserSubscriptions(ctx, "blocked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 0 {
+		t.Fatalf("expected blocked user's subscriptions removed got %+v", subs)
+	}
+}
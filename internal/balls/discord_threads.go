@@ -0,0 +1,68 @@
+package balls
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxThreadName is the longest name discord allows threads.
+const maxThreadName = 100
+
+// deliverThreads starts a forum post or thread for each approved ball in the thread channels, with the ball's
+// embed as the starter message.
+func (n *DiscordNotifier) deliverThreads(
+	ctx context.Context,
+	approvedBalls []Ball,
+	embeds []*discordgo.MessageEmbed,
+	roles func(channelID string) []BrandRole,
+) DeliveryReport {
+	// started are the messages sent to text channels by ball, so retrying a rate limited thread doesn't send the
+	// ball's message again.
+	started := make(map[string]map[int]string)
+
+	send := func(channelID string, i int, opts ...discordgo.RequestOption) error {
+		ch, err := n.channel(ctx, channelID)
+		if err != nil {
+			return err
+		}
+
+		b := approvedBalls[i]
+		thread := &discordgo.ThreadStart{Name: truncate(fmt.Sprintf("%s %s", b.Brand, b.Name), maxThreadName)}
+		content, allowed := roleMentions(roles(channelID), []Ball{b})
+		msg := &discordgo.MessageSend{
+			Content:         content,
+			Embeds:          []*discordgo.MessageEmbed{embeds[i]},
+			AllowedMentions: allowed,
+		}
+
+		if ch.Type == discordgo.ChannelTypeGuildForum || ch.Type == discordgo.ChannelTypeGuildMedia {
+			if _, err = n.dg.ForumThreadStartComplex(channelID, thread, msg, opts...); err != nil {
+				return fmt.Errorf("starting forum post: %w", err)
+			}
+			return nil
+		}
+
+		if started[channelID] == nil {
+			started[channelID] = make(map[int]string)
+		}
+		messageID, ok := started[channelID][i]
+		if !ok {
+			m, err := n.dg.ChannelMessageSendComplex(channelID, msg, opts...)
+			if err != nil {
+				return fmt.Errorf("sending embed: %w", err)
+			}
+			messageID = m.ID
+			started[channelID][i] = messageID
+		}
+
+		if _, err = n.dg.MessageThreadStartComplex(channelID, messageID, thread, opts...); err != nil {
+			return fmt.Errorf("starting thread: %w", err)
+		}
+
+		return nil
+	}
+
+	return n.deliver(ctx, n.threads, len(approvedBalls), send)
+}
@@ -0,0 +1,94 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiscordNotifier_Deliver_threads(t *testing.T) {
+	api := "/api/v" + discordgo.APIVersion
+
+	var (
+		mu          sync.Mutex
+		messages    int
+		threads     = make(map[string][]string)
+		rateLimited bool
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+api+"/channels/{channel}", func(w http.ResponseWriter, r *http.Request) {
+		typ := discordgo.ChannelTypeGuildText
+		if r.PathValue("channel") == "forum" {
+			typ = discordgo.ChannelTypeGuildForum
+		}
+		json.NewEncoder(w).Encode(discordgo.Channel{ID: r.PathValue("channel"), Type: typ})
+	})
+	mux.HandleFunc("POST "+api+"/channels/forum/threads", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name    string                `json:"name"`
+			Message discordgo.MessageSend `json:"message"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		if len(body.Message.Embeds) != 1 {
+			t.Errorf("expected starter message with the ball's embed got %+v", body.Message)
+		}
+
+		mu.Lock()
+		threads["forum"] = append(threads["forum"], body.Name)
+		mu.Unlock()
+		w.Write([]byte(`{"id": "thread"}`))
+	})
+	mux.HandleFunc("POST "+api+"/channels/text/messages", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages++
+		w.Write([]byte(`{"id": "` + strconv.Itoa(messages) + `"}`))
+		mu.Unlock()
+	})
+	mux.HandleFunc("POST "+api+"/channels/text/messages/{message}/threads", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !rateLimited {
+			rateLimited = true
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.01, "global": false}`))
+			return
+		}
+
+		var body discordgo.ThreadStart
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		threads["text"] = append(threads["text"], r.PathValue("message")+" "+body.Name)
+		w.Write([]byte(`{"id": "thread"}`))
+	})
+
+	n := NewDiscordNotifier(newFakeDiscordSession(t, mux), nil, WithThreadChannels([]string{"forum", "text"}))
+	err := n.Notify(context.Background(), []Ball{
+		{Brand: Storm, Name: "Phaze II", ApprovalDate: time.Now()},
+		{Brand: Motiv, Name: "Venom Shock", ApprovalDate: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"forum": {"Storm Phaze II", "Motiv Venom Shock"},
+		"text":  {"1 Storm Phaze II", "2 Motiv Venom Shock"},
+	}
+	if diff := cmp.Diff(threads, want); diff != "" {
+		t.Fatalf("(-got, +want):\n%s", diff)
+	}
+	if messages != 2 {
+		t.Fatalf("expected a message per ball in the text channel got %d", messages)
+	}
+}
@@ -0,0 +1,751 @@
+package balls
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPSecurity is how the connection to an SMTP server is secured.
+type SMTPSecurity string
+
+const (
+	// SMTPStartTLS upgrades the connection with STARTTLS, failing if the server doesn't support it.
+	SMTPStartTLS SMTPSecurity = "starttls"
+	// SMTPTLS connects with implicit TLS, usually on port 465.
+	SMTPTLS SMTPSecurity = "tls"
+	// SMTPNone sends in the clear, only meant for local relays.
+	SMTPNone SMTPSecurity = "none"
+)
+
+// SMTPServer is the server email notifications are sent through.
+type SMTPServer struct {
+	Host string
+	Port int
+	// Username and Password authenticate with PLAIN auth when Username is set.
+	Username string
+	Password string
+	// Security defaults to SMTPStartTLS.
+	Security SMTPSecurity
+	// TLSConfig verifies the server's certificate, the system roots are used for Host when nil.
+	TLSConfig *tls.Config
+}
+
+// smtpTimeout bounds connecting to the SMTP server and then each message sent over the connection.
+const smtpTimeout = time.Minute
+
+// EmailSubscription is a request to be emailed when balls matching it are approved.
+type EmailSubscription struct {
+	Email string
+	// Brand limits the subscription to a brand, empty for every brand.
+	Brand Brand
+	// Manufacturer limits the subscription to the brands a manufacturer owns, at most one of Brand and Manufacturer
+	// is set.
+	Manufacturer Manufacturer
+	// Pattern is the normalized text the names of matching balls contain, empty for every ball.
+	Pattern string
+	// Token confirms and unsubscribes the subscription, it's only ever sent to Email.
+	Token     string
+	CreatedAt time.Time
+	// ConfirmedAt is when the address confirmed the subscription, zero while it's pending. Only confirmed
+	// subscriptions are emailed balls.
+	ConfirmedAt time.Time
+}
+
+// NewEmailSubscription returns a pending subscription with a new random token.
+func NewEmailSubscription(
+	email string,
+	brand Brand,
+	manufacturer Manufacturer,
+	name string,
+	now time.Time,
+) (EmailSubscription, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return EmailSubscription{}, fmt.Errorf("generating token: %w", err)
+	}
+
+	return EmailSubscription{
+		Email:        email,
+		Brand:        brand,
+		Manufacturer: manufacturer,
+		Pattern:      NormalizeName(name),
+		Token:        hex.EncodeToString(token),
+		CreatedAt:    now,
+	}, nil
+}
+
+// Matches reports whether b is one of the balls the subscription is for.
+func (s EmailSubscription) Matches(b Ball) bool {
+	if s.Brand != "" && b.Brand != s.Brand {
+		return false
+	}
+	if s.Manufacturer != "" && b.Brand.Manufacturer() != s.Manufacturer {
+		return false
+	}
+
+	return s.Pattern == "" || containsWords(NormalizeName(b.Name), s.Pattern)
+}
+
+// Limits on the subscriptions of an address. Anyone can subscribe any address, so they bound how many
+// confirmations someone can be sent by others and how many subscriptions they can be left with.
+const (
+	// maxEmailSubscriptions is how many subscriptions an address can have, confirmed or pending.
+	maxEmailSubscriptions = 25
+	// maxPendingEmailSubscriptions is how many subscriptions an address can have waiting to be confirmed.
+	maxPendingEmailSubscriptions = 3
+	// emailConfirmationInterval is how long after a confirmation is sent to an address before another can be.
+	emailConfirmationInterval = 10 * time.Minute
+	// emailConfirmationTTL is how long a subscription can wait to be confirmed before it's removed.
+	emailConfirmationTTL = 48 * time.Hour
+)
+
+// allowEmailSubscription reports whether an address with subs can be sent a confirmation of another subscription
+// at now.
+func allowEmailSubscription(subs []EmailSubscription, now time.Time) bool {
+	if len(subs) >= maxEmailSubscriptions {
+		return false
+	}
+
+	pending := 0
+	for _, sub := range subs {
+		if !sub.ConfirmedAt.IsZero() {
+			continue
+		}
+		pending++
+		if now.Sub(sub.CreatedAt) < emailConfirmationInterval {
+			return false
+		}
+	}
+
+	return pending < maxPendingEmailSubscriptions
+}
+
+func (s EmailSubscription) sameFilter(o EmailSubscription) bool {
+	return s.Email == o.Email && s.Brand == o.Brand && s.Manufacturer == o.Manufacturer && s.Pattern == o.Pattern
+}
+
+// EmailNotifier implements the Notifier interface and emails newly approved balls to the configured recipients
+// and to subscribed addresses.
+type EmailNotifier struct {
+	rendering
+
+	server SMTPServer
+	from   string
+	to     []string
+	store  Store
+	logger *slog.Logger
+	// publicURL is where the server is reachable, confirmation and unsubscribe links point to it.
+	publicURL string
+}
+
+// EmailOption configures the email notifier.
+type EmailOption interface {
+	applyEmail(*EmailNotifier)
+}
+
+func (o NotifierOption) applyEmail(n *EmailNotifier) { o.applyRendering(&n.rendering) }
+
+type emailRecipientsOption []string
+
+func (o emailRecipientsOption) applyEmail(n *EmailNotifier) {
+	n.to = append(n.to, o...)
+}
+
+// WithEmailRecipients emails every approved ball to the addresses in to.
+func WithEmailRecipients(to ...string) EmailOption {
+	return emailRecipientsOption(to)
+}
+
+type emailSubscriptionsOption struct {
+	store Store
+}
+
+func (o emailSubscriptionsOption) applyEmail(n *EmailNotifier) {
+	n.store = o.store
+}
+
+// WithEmailSubscriptions emails the addresses subscribed in store the approved balls matching their subscriptions.
+func WithEmailSubscriptions(store Store) EmailOption {
+	return emailSubscriptionsOption{store: store}
+}
+
+type emailPublicURLOption string
+
+func (o emailPublicURLOption) applyEmail(n *EmailNotifier) {
+	n.publicURL = strings.TrimSuffix(string(o), "/")
+}
+
+// WithEmailPublicURL sets the url the server is reachable at, which subscription confirmation and unsubscribe links
+// point to. Subscribers aren't sent unsubscribe links without it.
+func WithEmailPublicURL(u string) EmailOption {
+	return emailPublicURLOption(u)
+}
+
+type emailLoggerOption struct {
+	logger *slog.Logger
+}
+
+func (o emailLoggerOption) applyEmail(n *EmailNotifier) {
+	n.logger = o.logger
+}
+
+// WithEmailLogger sets the logger subscriptions that can't be loaded are logged to, slog.Default is used otherwise.
+func WithEmailLogger(logger *slog.Logger) EmailOption {
+	return emailLoggerOption{logger: logger}
+}
+
+func NewEmailNotifier(server SMTPServer, from string, opts ...EmailOption) *EmailNotifier {
+	n := &EmailNotifier{server: server, from: from}
+	for _, opt := range opts {
+		opt.applyEmail(n)
+	}
+	if n.server.Security == "" {
+		n.server.Security = SMTPStartTLS
+	}
+	if n.renderer == nil {
+		n.renderer = defaultRenderer
+	}
+	if n.logger == nil {
+		n.logger = slog.Default()
+	}
+
+	return n
+}
+
+// Notify emails each recipient the approved balls they're sent, one message per recipient. A recipient that can't
+// be sent to doesn't stop the others.
+func (n *EmailNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	if len(approvedBalls) == 0 {
+		return nil
+	}
+
+	messages, err := n.renderer.RenderBalls(ctx, approvedBalls)
+	if err != nil {
+		return err
+	}
+
+	recipients, matched, tokens := n.recipients(ctx, approvedBalls)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	from, err := mail.ParseAddress(n.from)
+	if err != nil {
+		return fmt.Errorf("parsing from address: %w", err)
+	}
+
+	c, err := n.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if c != nil {
+			c.Close()
+		}
+	}()
+
+	var errs []error
+	for _, to := range recipients {
+		if c == nil {
+			if c, err = n.dial(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("reconnecting: %w", err))
+				break
+			}
+		}
+
+		var (
+			balls = make([]Ball, 0, len(matched[to]))
+			msgs  = make([]Message, 0, len(matched[to]))
+		)
+		for _, i := range matched[to] {
+			balls = append(balls, approvedBalls[i])
+			msgs = append(msgs, messages[i])
+		}
+
+		msg, err := n.message(from, to, balls, msgs, n.unsubscribeURL(tokens[to]), time.Now())
+		if err == nil {
+			err = c.extend(ctx)
+		}
+		if err == nil {
+			err = sendMail(c.Client, from.Address, to, msg)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sending to %s: %w", to, err))
+			// A connection that timed out or was dropped can't be reset, the next recipient gets a new one.
+			if c.extend(ctx) != nil || c.Reset() != nil {
+				c.Close()
+				c = nil
+			}
+		}
+	}
+	if c == nil {
+		return errors.Join(errs...)
+	}
+
+	if err := c.Quit(); err != nil && len(errs) == 0 {
+		return fmt.Errorf("quitting: %w", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// recipients returns the addresses to email in order with the indexes of the balls each is sent and the tokens of
+// the subscriptions that matched them.
+func (n *EmailNotifier) recipients(
+	ctx context.Context,
+	approvedBalls []Ball,
+) ([]string, map[string][]int, map[string][]string) {
+	var (
+		recipients []string
+		matched    = make(map[string][]int)
+		tokens     = make(map[string][]string)
+	)
+	add := func(to string, i int) {
+		if _, ok := matched[to]; !ok {
+			recipients = append(recipients, to)
+		}
+		if indexes := matched[to]; len(indexes) == 0 || indexes[len(indexes)-1] != i {
+			matched[to] = append(indexes, i)
+		}
+	}
+
+	var subs []EmailSubscription
+	if n.store != nil {
+		var err error
+		if subs, err = n.store.GetEmailSubscriptions(ctx); err != nil {
+			n.logger.ErrorContext(ctx, "error loading email subscriptions", slog.Any("error", err))
+		}
+	}
+
+	for i, b := range approvedBalls {
+		for _, to := range n.to {
+			add(to, i)
+		}
+		for _, sub := range subs {
+			if sub.Matches(b) {
+				add(sub.Email, i)
+				if !slices.Contains(tokens[sub.Email], sub.Token) {
+					tokens[sub.Email] = append(tokens[sub.Email], sub.Token)
+				}
+			}
+		}
+	}
+
+	return recipients, matched, tokens
+}
+
+// unsubscribeURL returns the link removing the subscriptions with tokens, empty when there are none or the public
+// url isn't known.
+func (n *EmailNotifier) unsubscribeURL(tokens []string) string {
+	if len(tokens) == 0 || n.publicURL == "" {
+		return ""
+	}
+
+	return n.publicURL + "/v1/subscriptions/email/unsubscribe?" + url.Values{"token": tokens}.Encode()
+}
+
+// SendConfirmation emails the address of a pending subscription the link confirming it, nothing is sent to the
+// address until it's followed.
+func (n *EmailNotifier) SendConfirmation(ctx context.Context, sub EmailSubscription) error {
+	if n.publicURL == "" {
+		return errors.New("no public url to link to")
+	}
+
+	from, err := mail.ParseAddress(n.from)
+	if err != nil {
+		return fmt.Errorf("parsing from address: %w", err)
+	}
+
+	data := emailConfirmationData{
+		Subscription: "every approved ball",
+		ConfirmURL:   n.publicURL + "/v1/subscriptions/email/confirm?" + url.Values{"token": {sub.Token}}.Encode(),
+	}
+	switch {
+	case sub.Brand != "" && sub.Pattern != "":
+		data.Subscription = fmt.Sprintf("approved %s balls matching %q", sub.Brand, sub.Pattern)
+	case sub.Brand != "":
+		data.Subscription = fmt.Sprintf("approved %s balls", sub.Brand)
+	case sub.Manufacturer != "" && sub.Pattern != "":
+		data.Subscription = fmt.Sprintf("approved balls from %s brands matching %q", sub.Manufacturer, sub.Pattern)
+	case sub.Manufacturer != "":
+		data.Subscription = fmt.Sprintf("approved balls from %s brands", sub.Manufacturer)
+	case sub.Pattern != "":
+		data.Subscription = fmt.Sprintf("approved balls matching %q", sub.Pattern)
+	}
+
+	var html bytes.Buffer
+	if err = emailConfirmationHTML.Execute(&html, data); err != nil {
+		return fmt.Errorf("rendering html: %w", err)
+	}
+	text := fmt.Sprintf("Confirm you'd like to be emailed %s:\r\n%s\r\n\r\n"+
+		"If you didn't ask to be, ignore this email and you won't be emailed again.\r\n", data.Subscription, data.ConfirmURL)
+
+	msg, err := n.compose(from, sub.Email, "Confirm your subscription", text, html.String(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	c, err := n.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err = sendMail(c.Client, from.Address, sub.Email, msg); err != nil {
+		return fmt.Errorf("sending to %s: %w", sub.Email, err)
+	}
+	if err = c.Quit(); err != nil {
+		return fmt.Errorf("quitting: %w", err)
+	}
+
+	return nil
+}
+
+// smtpClient is a connection to the SMTP server whose deadline is extended for each message sent over it.
+type smtpClient struct {
+	*smtp.Client
+	conn net.Conn
+}
+
+// extend gives the next exchange with the server smtpTimeout, or until ctx's deadline when that's sooner.
+func (c *smtpClient) extend(ctx context.Context) error {
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("setting deadline: %w", err)
+	}
+
+	return nil
+}
+
+// dial connects and authenticates to the SMTP server.
+func (n *EmailNotifier) dial(ctx context.Context) (*smtpClient, error) {
+	tlsConfig := n.server.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: n.server.Host, MinVersion: tls.VersionTLS12}
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(n.server.Host, strconv.Itoa(n.server.Port)))
+	if err != nil {
+		return nil, fmt.Errorf("dialing smtp server: %w", err)
+	}
+
+	sc := &smtpClient{conn: conn}
+	if err = sc.extend(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if n.server.Security == SMTPTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, n.server.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("creating smtp client: %w", err)
+	}
+	sc.Client = c
+
+	if n.server.Security == SMTPStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			c.Close()
+			return nil, errors.New("smtp server doesn't support STARTTLS")
+		}
+		if err = c.StartTLS(tlsConfig); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("starting tls: %w", err)
+		}
+	}
+
+	if n.server.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", n.server.Username, n.server.Password, n.server.Host)); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("authenticating: %w", err)
+		}
+	}
+
+	return sc, nil
+}
+
+func sendMail(c *smtp.Client, from, to string, msg []byte) error {
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("rcpt: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err = w.Write(msg); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("closing message: %w", err)
+	}
+
+	return nil
+}
+
+// message returns the email announcing balls to to, linking to unsubscribeURL when it's set.
+func (n *EmailNotifier) message(
+	from *mail.Address,
+	to string,
+	balls []Ball,
+	messages []Message,
+	unsubscribeURL string,
+	now time.Time,
+) ([]byte, error) {
+	subject := ballCount(len(balls))
+	if len(balls) == 1 {
+		subject = fmt.Sprintf("Newly approved: %s", messages[0].Title)
+	}
+
+	data := emailData{Heading: ballCount(len(balls)), UnsubscribeURL: unsubscribeURL}
+	for i, b := range balls {
+		style := n.styles.Style(b.Brand)
+		ball := emailBall{
+			Title:        messages[i].Title,
+			Description:  messages[i].Description,
+			Approved:     b.ApprovalDate.Format("January 2, 2006"),
+			Manufacturer: string(b.Brand.Manufacturer()),
+			Website:      style.Website,
+			Color:        "#cccccc",
+			Alt:          fmt.Sprintf("%s %s", b.Brand, b.Name),
+		}
+		if style.Color != 0 {
+			ball.Color = fmt.Sprintf("#%06x", style.Color)
+		}
+		if b.ImageURL != nil {
+			ball.ImageURL = b.ImageURL.String()
+		}
+		data.Balls = append(data.Balls, ball)
+	}
+
+	var html bytes.Buffer
+	if err := emailHTML.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("rendering html: %w", err)
+	}
+
+	var headers []emailHeader
+	if unsubscribeURL != "" {
+		// One-click unsubscribing (RFC 8058) POSTs to the url, so mail clients don't need to open the page.
+		headers = append(headers,
+			emailHeader{"List-Unsubscribe", "<" + unsubscribeURL + ">"},
+			emailHeader{"List-Unsubscribe-Post", "List-Unsubscribe=One-Click"},
+		)
+	}
+
+	return n.compose(from, to, subject, emailText(data), html.String(), now, headers...)
+}
+
+type emailHeader struct{ key, value string }
+
+// compose returns the multipart email with an html part and its plain text fallback.
+func (n *EmailNotifier) compose(
+	from *mail.Address,
+	to, subject, text, html string,
+	now time.Time,
+	extra ...emailHeader,
+) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("generating message id: %w", err)
+	}
+	_, domain, _ := strings.Cut(from.Address, "@")
+
+	headers := []emailHeader{
+		{"From", from.String()},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domain)},
+	}
+	headers = append(headers, extra...)
+	headers = append(headers,
+		emailHeader{"MIME-Version", "1.0"},
+		emailHeader{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary())},
+	)
+	var header bytes.Buffer
+	for _, h := range headers {
+		fmt.Fprintf(&header, "%s: %s\r\n", h.key, h.value)
+	}
+	header.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating part: %w", err)
+		}
+
+		qp := quotedprintable.NewWriter(w)
+		if _, err = qp.Write([]byte(part.body)); err != nil {
+			return nil, fmt.Errorf("writing part: %w", err)
+		}
+		if err = qp.Close(); err != nil {
+			return nil, fmt.Errorf("closing part: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("closing message: %w", err)
+	}
+
+	return append(header.Bytes(), buf.Bytes()...), nil
+}
+
+type emailData struct {
+	Heading        string
+	Balls          []emailBall
+	UnsubscribeURL string
+}
+
+type emailBall struct {
+	Title        string
+	Description  string
+	Approved     string
+	Manufacturer string
+	Website      string
+	Color        string
+	ImageURL     string
+	Alt          string
+}
+
+func emailText(data emailData) string {
+	var b strings.Builder
+	b.WriteString(data.Heading + "\r\n")
+	for _, ball := range data.Balls {
+		fmt.Fprintf(&b, "\r\n%s\r\nApproved %s, %s\r\n", ball.Title, ball.Approved, ball.Manufacturer)
+		if ball.Description != "" {
+			b.WriteString(strings.ReplaceAll(ball.Description, "\n", "\r\n") + "\r\n")
+		}
+		if ball.ImageURL != "" {
+			b.WriteString(ball.ImageURL + "\r\n")
+		}
+	}
+	if data.UnsubscribeURL != "" {
+		fmt.Fprintf(&b, "\r\nUnsubscribe: %s\r\n", data.UnsubscribeURL)
+	}
+
+	return b.String()
+}
+
+var emailHTML = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #222222">
+<h2>{{.Heading}}</h2>
+{{- range .Balls}}
+<div style="margin: 0 0 24px 0; padding-left: 12px; border-left: 4px solid {{.Color}}">
+<h3 style="margin: 0 0 4px 0">{{if .Website}}<a href="{{.Website}}" style="color: #222222">{{.Title}}</a>{{else}}{{.Title}}{{end}}</h3>
+<p style="margin: 0 0 8px 0; color: #666666">Approved {{.Approved}}, {{.Manufacturer}}</p>
+{{- if .Description}}
+<p style="margin: 0 0 8px 0">{{.Description}}</p>
+{{- end}}
+{{- if .ImageURL}}
+<img src="{{.ImageURL}}" alt="{{.Alt}}" width="300" style="max-width: 100%">
+{{- end}}
+</div>
+{{- end}}
+{{- if .UnsubscribeURL}}
+<p style="font-size: 12px; color: #666666"><a href="{{.UnsubscribeURL}}" style="color: #666666">Unsubscribe</a></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// emailPage is a page the confirmation and unsubscribe links lead to, with a form submitting to Action when it's
+// set.
+type emailPage struct {
+	Title   string
+	Message string
+	Action  string
+	Button  string
+}
+
+var (
+	confirmEmailPage = emailPage{
+		Title:   "Confirm subscription",
+		Message: "Confirm you'd like to be emailed newly approved balls.",
+		Button:  "Confirm",
+	}
+	unsubscribeEmailPage = emailPage{
+		Title:   "Unsubscribe",
+		Message: "Unsubscribe from emails about newly approved balls?",
+		Button:  "Unsubscribe",
+	}
+)
+
+const missingTokenMessage = "This link is missing its token, copy the whole link from the email."
+
+func renderEmailPage(w http.ResponseWriter, status int, page emailPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = emailPageHTML.Execute(w, page)
+}
+
+var emailPageHTML = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+</head>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #222222">
+<h2>{{.Title}}</h2>
+<p>{{.Message}}</p>
+{{- if .Action}}
+<form method="post" action="{{.Action}}">
+<button type="submit">{{.Button}}</button>
+</form>
+{{- end}}
+</body>
+</html>
+`))
+
+type emailConfirmationData struct {
+	Subscription string
+	ConfirmURL   string
+}
+
+var emailConfirmationHTML = template.Must(template.New("confirmation").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #222222">
+<p>Confirm you'd like to be emailed {{.Subscription}}.</p>
+<p><a href="{{.ConfirmURL}}">Confirm subscription</a></p>
+<p style="font-size: 12px; color: #666666">If you didn't ask to be, ignore this email and you won't be emailed again.</p>
+</body>
+</html>
+`))
@@ -0,0 +1,496 @@
+package balls
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpCapture is a minimal SMTP server capturing the mail sent to it. Recipients at bounce.example.com are
+// rejected.
+type smtpCapture struct {
+	ln        net.Listener
+	tlsConfig *tls.Config
+	username  string
+	password  string
+
+	mu   sync.Mutex
+	mail []capturedMail
+}
+
+type capturedMail struct {
+	From    string
+	To      []string
+	Data    string
+	TLS     bool
+	Authned bool
+}
+
+// newSMTPCapture starts a capture server offering STARTTLS and PLAIN auth, returning the TLS config clients verify
+// it with.
+func newSMTPCapture(t *testing.T, username, password string) (*smtpCapture, *tls.Config) {
+	t.Helper()
+
+	// The test server's certificate is valid for 127.0.0.1.
+	certSrv := httptest.NewTLSServer(nil)
+	certSrv.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(certSrv.Certificate())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpCapture{
+		ln:        ln,
+		tlsConfig: &tls.Config{Certificates: certSrv.TLS.Certificates},
+		username:  username,
+		password:  password,
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s, &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+}
+
+func (s *smtpCapture) server(security SMTPSecurity, tlsConfig *tls.Config) SMTPServer {
+	addr := s.ln.Addr().(*net.TCPAddr)
+	return SMTPServer{
+		Host:      "127.0.0.1",
+		Port:      addr.Port,
+		Username:  s.username,
+		Password:  s.password,
+		Security:  security,
+		TLSConfig: tlsConfig,
+	}
+}
+
+func (s *smtpCapture) captured() []capturedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]capturedMail(nil), s.mail...)
+}
+
+func (s *smtpCapture) serve(conn net.Conn) {
+	defer conn.Close()
+
+	var (
+		rw      = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+		current capturedMail
+	)
+	reply := func(lines ...string) {
+		for _, l := range lines {
+			rw.WriteString(l + "\r\n")
+		}
+		rw.Flush()
+	}
+
+	reply("220 capture ESMTP")
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			lines := []string{"250-capture"}
+			if !current.TLS {
+				lines = append(lines, "250-STARTTLS")
+			}
+			reply(append(lines, "250 AUTH PLAIN")...)
+
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			rw = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+			current = capturedMail{TLS: true}
+
+		case "AUTH":
+			creds, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(arg, "PLAIN "))
+			if string(creds) != "\x00"+s.username+"\x00"+s.password {
+				reply("535 authentication failed")
+				continue
+			}
+			current.Authned = true
+			reply("235 authenticated")
+
+		case "MAIL":
+			current.From = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			reply("250 ok")
+
+		case "RCPT":
+			to := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if strings.HasSuffix(to, "@bounce.example.com") {
+				reply("550 no such user")
+				continue
+			}
+			if strings.HasSuffix(to, "@hangup.example.com") {
+				return
+			}
+			current.To = append(current.To, to)
+			reply("250 ok")
+
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := rw.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			current.Data = data.String()
+
+			s.mu.Lock()
+			s.mail = append(s.mail, current)
+			s.mu.Unlock()
+			current = capturedMail{TLS: current.TLS, Authned: current.Authned}
+			reply("250 queued")
+
+		case "RSET":
+			current = capturedMail{TLS: current.TLS, Authned: current.Authned}
+			reply("250 ok")
+
+		case "QUIT":
+			reply("221 bye")
+			return
+
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	capture, tlsConfig := newSMTPCapture(t, "user", "secret")
+
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for _, sub := range []EmailSubscription{
+		{Email: "storm@example.com", Brand: Storm, Token: "storm"},
+		{Email: "storm@example.com", Pattern: "phaze", Token: "phaze"},
+		{Email: "idol@example.com", Pattern: "idol", Token: "idol"},
+		{Email: "brands@example.com", Manufacturer: StormProducts, Token: "brands"},
+		{Email: "nobody@bounce.example.com", Pattern: "idol", Token: "bounce"},
+		{Email: "dropped@hangup.example.com", Pattern: "idol", Token: "hangup"},
+		{Email: "pending@example.com", Token: "pending"},
+	} {
+		if _, err := store.AddEmailSubscription(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+		if sub.Token == "pending" {
+			continue
+		}
+		if err := store.ConfirmEmailSubscription(ctx, sub.Token, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n := NewEmailNotifier(capture.server(SMTPStartTLS, tlsConfig), "Approved balls <balls@example.com>",
+		WithEmailRecipients("shop@example.com"),
+		WithEmailSubscriptions(store),
+		WithEmailPublicURL("https://balls.example.com/"),
+	)
+
+	// The recipients after the dropped connection are sent over a new one.
+	err := n.Notify(ctx, webhookTestBalls())
+	if err == nil || !strings.Contains(err.Error(), "nobody@bounce.example.com") ||
+		!strings.Contains(err.Error(), "dropped@hangup.example.com") {
+		t.Fatalf("expected rejected and dropped recipient errors got %v", err)
+	}
+
+	got := capture.captured()
+	recipients := make(map[string]capturedMail, len(got))
+	for _, m := range got {
+		if !m.TLS || !m.Authned {
+			t.Errorf("expected mail sent over tls after authenticating got %+v", m)
+		}
+		if m.From != "balls@example.com" || len(m.To) != 1 {
+			t.Errorf("unexpected envelope %+v", m)
+		}
+		recipients[m.To[0]] = m
+	}
+	if len(recipients) != 4 {
+		t.Fatalf("expected mail to 4 recipients got %d", len(recipients))
+	}
+
+	shop := parseCapturedMail(t, recipients["shop@example.com"])
+	if shop.subject != "3 newly approved balls" {
+		t.Errorf("unexpected subject %q", shop.subject)
+	}
+	if !strings.Contains(shop.text, "Storm Phaze II") || !strings.Contains(shop.text, "https://some-url/phaze.png") {
+		t.Errorf("expected plain text to list the balls got %q", shop.text)
+	}
+	if !strings.Contains(shop.html, `<img src="https://some-url/phaze.png" alt="Storm Phaze II"`) {
+		t.Errorf("expected html to include the ball image got %q", shop.html)
+	}
+	if shop.header.Get("List-Unsubscribe") != "" || strings.Contains(shop.text, "Unsubscribe") {
+		t.Error("expected no unsubscribe link for configured recipients")
+	}
+
+	storm := parseCapturedMail(t, recipients["storm@example.com"])
+	if storm.subject != "Newly approved: Storm Phaze II" || strings.Contains(storm.html, "Idol") {
+		t.Errorf("expected storm subscriber to only get storm balls got %q", storm.subject)
+	}
+	unsubscribe := "https://balls.example.com/v1/subscriptions/email/unsubscribe?token=phaze&token=storm"
+	if got := storm.header.Get("List-Unsubscribe"); got != "<"+unsubscribe+">" {
+		t.Errorf("expected unsubscribing from both matching subscriptions got %q", got)
+	}
+	if storm.header.Get("List-Unsubscribe-Post") != "List-Unsubscribe=One-Click" {
+		t.Error("expected one-click unsubscribing")
+	}
+	if !strings.Contains(storm.text, "Unsubscribe: "+unsubscribe) ||
+		!strings.Contains(storm.html, `<a href="`+strings.ReplaceAll(unsubscribe, "&", "&amp;")+`"`) {
+		t.Errorf("expected unsubscribe links in both parts got %q\n%q", storm.text, storm.html)
+	}
+	idol := parseCapturedMail(t, recipients["idol@example.com"])
+	if idol.subject != "Newly approved: Roto Grip Idol" {
+		t.Errorf("expected idol subscriber to only get the idol got %q", idol.subject)
+	}
+	brands := parseCapturedMail(t, recipients["brands@example.com"])
+	if brands.subject != "2 newly approved balls" || strings.Contains(brands.text, "Venom Shock") {
+		t.Errorf("expected manufacturer subscriber to only get storm products balls got %q", brands.subject)
+	}
+}
+
+func TestEmailNotifier_Notify_startTLSRequired(t *testing.T) {
+	capture, _ := newSMTPCapture(t, "", "")
+	server := capture.server(SMTPStartTLS, &tls.Config{ServerName: "127.0.0.1"})
+
+	// The capture server's certificate isn't trusted, so the upgrade fails rather than sending in the clear.
+	err := NewEmailNotifier(server, "balls@example.com", WithEmailRecipients("shop@example.com")).
+		Notify(context.Background(), webhookTestBalls())
+	if err == nil {
+		t.Fatal("expected error got nil")
+	}
+	if len(capture.captured()) != 0 {
+		t.Fatal("expected no mail to be sent")
+	}
+}
+
+type parsedMail struct {
+	header  mail.Header
+	subject string
+	text    string
+	html    string
+}
+
+func parseCapturedMail(t *testing.T, m capturedMail) parsedMail {
+	t.Helper()
+
+	msg, err := mail.ReadMessage(strings.NewReader(m.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative got %q, %v", mediaType, err)
+	}
+
+	parsed := parsedMail{header: msg.Header, subject: subject}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// NextPart decodes quoted-printable parts.
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch ct := part.Header.Get("Content-Type"); {
+		case strings.HasPrefix(ct, "text/plain"):
+			parsed.text = string(body)
+		case strings.HasPrefix(ct, "text/html"):
+			parsed.html = string(body)
+		default:
+			t.Fatalf("unexpected part %s", strconv.Quote(ct))
+		}
+	}
+
+	return parsed
+}
+
+func Test_allowEmailSubscription(t *testing.T) {
+	now := time.Now()
+	confirmed := func(n int) []EmailSubscription {
+		subs := make([]EmailSubscription, n)
+		for i := range subs {
+			subs[i] = EmailSubscription{CreatedAt: now.Add(-time.Hour), ConfirmedAt: now.Add(-time.Hour)}
+		}
+		return subs
+	}
+	pending := EmailSubscription{CreatedAt: now.Add(-time.Hour)}
+
+	tests := []struct {
+		name string
+		subs []EmailSubscription
+		want bool
+	}{
+		{name: "first subscription", want: true},
+		{name: "under the limits", subs: append(confirmed(3), pending, pending), want: true},
+		{name: "at the subscription limit", subs: confirmed(maxEmailSubscriptions)},
+		{name: "at the pending limit", subs: []EmailSubscription{pending, pending, pending}},
+		{name: "confirmation just sent", subs: []EmailSubscription{{CreatedAt: now.Add(-time.Minute)}}},
+		{
+			name: "subscription just confirmed",
+			subs: []EmailSubscription{{CreatedAt: now.Add(-time.Minute), ConfirmedAt: now}},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowEmailSubscription(tt.subs, now); got != tt.want {
+				t.Errorf("allowEmailSubscription() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_emailSubscriptions(t *testing.T) {
+	capture, tlsConfig := newSMTPCapture(t, "", "")
+	store := NewMemoryStore()
+	notifier := NewEmailNotifier(capture.server(SMTPStartTLS, tlsConfig), "balls@example.com",
+		WithEmailPublicURL("https://balls.example.com"),
+	)
+	h := NewHTTPHandler(slog.Default(), NewService(slog.Default(), store, nil, nil), "test",
+		WithEmailSubscriptionStore(store, notifier),
+	)
+
+	request := func(method, target, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	subscribe := func(body string) *httptest.ResponseRecorder {
+		return request(http.MethodPost, "/v1/subscriptions/email", "application/json", body)
+	}
+
+	// The same subscription again, and another one straight after, don't send more confirmations.
+	for _, body := range []string{
+		`{"email":"Shop <shop@example.com>","brand":"Storm","name":"Phaze"}`,
+		`{"email":"Shop <shop@example.com>","brand":"Storm","name":"Phaze"}`,
+		`{"email":"SHOP@example.com","name":"Hustle"}`,
+	} {
+		if rec := subscribe(body); rec.Code != http.StatusAccepted {
+			t.Fatalf("expected status %d got %d: %s", http.StatusAccepted, rec.Code, rec.Body)
+		}
+	}
+	for _, body := range []string{
+		`{"email":"shop"}`,
+		`{"email":"shop@example.com","brand":"Nope"}`,
+		`{"email":"shop@example.com","manufacturer":"Nope"}`,
+		`{"email":"shop@example.com","brand":"Storm","manufacturer":"Storm Products"}`,
+	} {
+		if rec := subscribe(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d for %s got %d", http.StatusBadRequest, body, rec.Code)
+		}
+	}
+	if rec := request(http.MethodGet, "/v1/subscriptions/email", "", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected subscriptions not to be listed got %d", rec.Code)
+	}
+
+	sent := capture.captured()
+	if len(sent) != 1 || sent[0].To[0] != "shop@example.com" {
+		t.Fatalf("expected one confirmation email got %+v", sent)
+	}
+	confirmation := parseCapturedMail(t, sent[0])
+	match := regexp.MustCompile(`/v1/subscriptions/email/confirm\?token=([0-9a-f]+)`).FindStringSubmatch(confirmation.text)
+	if match == nil || !strings.Contains(confirmation.text, `approved Storm balls matching "phaze"`) {
+		t.Fatalf("expected a confirmation link got %q", confirmation.text)
+	}
+	token := match[1]
+
+	subs, err := store.GetEmailSubscriptions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 0 {
+		t.Fatalf("expected the subscription to be pending got %+v", subs)
+	}
+
+	// Following the link only shows the form, so link scanners don't confirm.
+	rec := request(http.MethodGet, "/v1/subscriptions/email/confirm?token="+token, "", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<form method="post"`) {
+		t.Fatalf("expected confirmation form got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := request(http.MethodPost, "/v1/subscriptions/email/confirm?token="+token, "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rec.Code, rec.Body)
+	}
+	if rec := request(http.MethodPost, "/v1/subscriptions/email/confirm?token=nope", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for unknown token got %d", http.StatusNotFound, rec.Code)
+	}
+
+	subs, err = store.GetEmailSubscriptions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].Email != "shop@example.com" || subs[0].Brand != Storm || subs[0].Pattern != "phaze" {
+		t.Fatalf("unexpected subscriptions %+v", subs)
+	}
+
+	// Mail clients unsubscribe in one click by POSTing to the List-Unsubscribe url.
+	rec = request(http.MethodPost, "/v1/subscriptions/email/unsubscribe?token="+token,
+		"application/x-www-form-urlencoded", "List-Unsubscribe=One-Click")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rec.Code, rec.Body)
+	}
+	subs, err = store.GetEmailSubscriptions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 0 {
+		t.Fatalf("expected subscription to be removed got %+v", subs)
+	}
+}
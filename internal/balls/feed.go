@@ -0,0 +1,349 @@
+package balls
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+const (
+	// maxFeedItems is the number of most recent approvals in a feed.
+	maxFeedItems = 50
+
+	feedTitle = "USBC approved balls"
+)
+
+// feedNamespace is the namespace of the name-based UUIDs identifying balls and feeds, so a ball keeps its GUID for
+// as long as it keeps its ID.
+var feedNamespace = [16]byte{
+	0x5c, 0x0b, 0x6f, 0x1e, 0x8a, 0x3d, 0x4f, 0x2b, 0x9e, 0x61, 0x27, 0xd4, 0xa0, 0x95, 0x3c, 0x7f,
+}
+
+// feedUUID returns the version 5 UUID URN of name in the feed namespace.
+func feedUUID(name string) string {
+	h := sha1.New()
+	h.Write(feedNamespace[:])
+	h.Write([]byte(name))
+	sum := h.Sum(nil)
+
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// ballGUID is the stable identifier of a ball in feeds.
+func ballGUID(b Ball) string {
+	return feedUUID("ball/" + strconv.Itoa(b.ID))
+}
+
+// feedFormat renders a feed of the approvals in an encoding such as RSS or Atom.
+type feedFormat struct {
+	contentType string
+	render      func(f feed) any
+}
+
+var (
+	rssFormat = feedFormat{
+		contentType: "application/rss+xml; charset=utf-8",
+		render:      rssFeed,
+	}
+	atomFormat = feedFormat{
+		contentType: "application/atom+xml; charset=utf-8",
+		render:      atomFeed,
+	}
+)
+
+// feed is what the RSS and Atom feeds are rendered from.
+type feed struct {
+	Title   string
+	ID      string
+	SelfURL string
+	Updated time.Time
+	Items   []feedItem
+}
+
+type feedItem struct {
+	Ball    Ball
+	Message Message
+	Website string
+}
+
+// handleFeed serves the most recent approvals, optionally filtered by brand, in format. Responses are validated with
+// an ETag of their content and a Last-Modified of the later of the newest approval and the last successful run, as
+// balls are only added by runs. Requests validated by Last-Modified alone are answered before the feed is rendered.
+func handleFeed(
+	logger *slog.Logger,
+	store Store,
+	renderer *Renderer,
+	styles BrandStyles,
+	format feedFormat,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		internalError := func(msg string, err error) {
+			logger.ErrorContext(r.Context(), msg, slog.Any("error", err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "internal server error",
+				},
+			})
+		}
+
+		var (
+			filter BallFilter
+			title  = feedTitle
+		)
+		if b := r.URL.Query().Get("brand"); b != "" {
+			brand := Brand(b)
+			if _, ok := LookupBrand(brand); !ok {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, map[string]any{
+					"error": map[string]any{
+						"message": "unknown brand",
+					},
+				})
+				return
+			}
+			filter.Brand = &brand
+			title = fmt.Sprintf("%s: %s", feedTitle, brand)
+		}
+
+		balls, err := store.GetRecentBalls(r.Context(), filter, maxFeedItems)
+		if err != nil {
+			internalError("error listing balls", err)
+			return
+		}
+
+		var updated time.Time
+		if len(balls) > 0 {
+			updated = balls[0].ApprovalDate
+		}
+		run, err := store.GetLastSuccessfulRun(r.Context())
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			internalError("error getting last successful run", err)
+			return
+		case run.FinishedAt.After(updated):
+			updated = run.FinishedAt
+		}
+		updated = updated.UTC().Truncate(time.Second)
+
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if notModifiedSince(r, updated) {
+			w.Header().Set("Last-Modified", updated.Format(http.TimeFormat))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		messages, err := renderer.RenderBalls(r.Context(), balls)
+		if err != nil {
+			internalError("error rendering balls", err)
+			return
+		}
+
+		f := feed{
+			Title:   title,
+			ID:      feedUUID("feed/approvals/" + r.URL.Query().Get("brand")),
+			SelfURL: requestURL(r),
+			Updated: updated,
+		}
+		for i, b := range balls {
+			f.Items = append(f.Items, feedItem{Ball: b, Message: messages[i], Website: styles.Style(b.Brand).Website})
+		}
+
+		var body bytes.Buffer
+		body.WriteString(xml.Header)
+		enc := xml.NewEncoder(&body)
+		enc.Indent("", "  ")
+		if err := enc.Encode(format.render(f)); err != nil {
+			internalError("error encoding feed", err)
+			return
+		}
+
+		sum := sha256.Sum256(body.Bytes())
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		w.Header().Set("Content-Type", format.contentType)
+
+		// ServeContent answers If-None-Match and If-Modified-Since with 304 Not Modified.
+		http.ServeContent(w, r, "", updated, bytes.NewReader(body.Bytes()))
+	}
+}
+
+// notModifiedSince reports whether r is only validated by an If-Modified-Since no earlier than updated. An
+// If-None-Match takes precedence and needs the ETag of the rendered feed.
+func notModifiedSince(r *http.Request, updated time.Time) bool {
+	if updated.IsZero() || r.Header.Get("If-None-Match") != "" {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+
+	return err == nil && !updated.After(since)
+}
+
+// requestURL returns the absolute url the request was made to, trusting the proxy's X-Forwarded-Proto.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// imageType guesses the media type of an image from its extension.
+func imageType(b Ball) string {
+	if t := mime.TypeByExtension(path.Ext(b.ImageURL.Path)); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	Description string        `xml:"description,omitempty"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Category    string        `xml:"category"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL string `xml:"url,attr"`
+	// Length is required but unknown without downloading the image, 0 is the convention for unknown.
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+func rssFeed(f feed) any {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.SelfURL,
+		Description: "Bowling balls newly approved by the USBC",
+		Language:    "en",
+		AtomLink:    atomLink{Rel: "self", Href: f.SelfURL, Type: "application/rss+xml"},
+	}
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+
+	for _, item := range f.Items {
+		b := item.Ball
+		i := rssItem{
+			Title:       item.Message.Title,
+			Link:        item.Website,
+			Description: item.Message.Description,
+			GUID:        rssGUID{Value: ballGUID(b)},
+			PubDate:     b.ApprovalDate.UTC().Format(time.RFC1123Z),
+			Category:    string(b.Brand),
+		}
+		if b.ImageURL != nil && b.ImageURL.String() != "" {
+			i.Enclosure = &rssEnclosure{URL: b.ImageURL.String(), Type: imageType(b)}
+		}
+		channel.Items = append(channel.Items, i)
+	}
+
+	return rss{Version: "2.0", AtomNS: "http://www.w3.org/2005/Atom", Channel: channel}
+}
+
+type atom struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title     string       `xml:"title"`
+	ID        string       `xml:"id"`
+	Updated   string       `xml:"updated"`
+	Published string       `xml:"published"`
+	Links     []atomLink   `xml:"link"`
+	Category  atomCategory `xml:"category"`
+	Summary   string       `xml:"summary,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func atomFeed(f feed) any {
+	feed := atom{
+		Title:   f.Title,
+		ID:      f.ID,
+		Updated: f.Updated.Format(time.RFC3339),
+		Links:   []atomLink{{Rel: "self", Href: f.SelfURL, Type: "application/atom+xml"}},
+		Author:  atomAuthor{Name: "Approved Ball List"},
+	}
+
+	for _, item := range f.Items {
+		b := item.Ball
+		entry := atomEntry{
+			Title:     item.Message.Title,
+			ID:        ballGUID(b),
+			Updated:   b.ApprovalDate.UTC().Format(time.RFC3339),
+			Published: b.ApprovalDate.UTC().Format(time.RFC3339),
+			Category:  atomCategory{Term: string(b.Brand)},
+			Summary:   item.Message.Description,
+		}
+		if item.Website != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Href: item.Website, Type: "text/html"})
+		}
+		if b.ImageURL != nil && b.ImageURL.String() != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "enclosure", Href: b.ImageURL.String(), Type: imageType(b)})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed
+}
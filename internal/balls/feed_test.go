@@ -0,0 +1,137 @@
+package balls
+
+import (
+	"context"
+	"encoding/xml"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPHandler_feeds(t *testing.T) {
+	image, err := url.Parse("https://images.example.com/phaze-ii.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	approved := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	store := NewMemoryStore()
+	err = store.AddBalls(context.Background(), []Ball{
+		{Brand: Storm, Name: "Phaze II", ImageURL: image, ApprovalDate: approved},
+		{Brand: Motiv, Name: "Venom Shock", ApprovalDate: approved.AddDate(0, 0, 7)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHTTPHandler(slog.Default(), NewService(slog.Default(), store, nil, nil), "test",
+		WithFeeds(store, nil, nil),
+	)
+
+	request := func(target string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("rss", func(t *testing.T) {
+		rec := request("/v1/feeds/approvals.rss", nil)
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/rss+xml; charset=utf-8" {
+			t.Fatalf("unexpected response %d %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+
+		var got rss
+		if err := xml.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		items := got.Channel.Items
+		if len(items) != 2 || items[0].Category != "Motiv" || items[1].Category != "Storm" {
+			t.Fatalf("expected the newest approval first got %+v", items)
+		}
+		if items[1].GUID.IsPermaLink || items[1].GUID.Value != ballGUID(Ball{ID: 1}) {
+			t.Errorf("unexpected guid %+v", items[1].GUID)
+		}
+		if e := items[1].Enclosure; e == nil || e.URL != image.String() || e.Type != "image/png" {
+			t.Errorf("unexpected enclosure %+v", e)
+		}
+		if items[0].Enclosure != nil {
+			t.Errorf("expected no enclosure without an image got %+v", items[0].Enclosure)
+		}
+		if items[1].Link != "https://www.stormbowling.com" {
+			t.Errorf("expected a link to the brand website got %q", items[1].Link)
+		}
+	})
+
+	t.Run("atom by brand", func(t *testing.T) {
+		rec := request("/v1/feeds/approvals.atom?brand=Storm", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d got %d: %s", http.StatusOK, rec.Code, rec.Body)
+		}
+
+		var got atom
+		if err := xml.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Entries) != 1 || got.Entries[0].ID != ballGUID(Ball{ID: 1}) {
+			t.Fatalf("unexpected entries %+v", got.Entries)
+		}
+		var enclosure bool
+		for _, link := range got.Entries[0].Links {
+			enclosure = enclosure || link.Rel == "enclosure" && link.Href == image.String()
+		}
+		if !enclosure {
+			t.Errorf("expected an enclosure link got %+v", got.Entries[0].Links)
+		}
+		if got.Updated != "2024-05-01T00:00:00Z" {
+			t.Errorf("unexpected updated %q", got.Updated)
+		}
+	})
+
+	t.Run("unknown brand", func(t *testing.T) {
+		if rec := request("/v1/feeds/approvals.atom?brand=Nope", nil); rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("conditional requests", func(t *testing.T) {
+		rec := request("/v1/feeds/approvals.rss", nil)
+		etag, lastModified := rec.Header().Get("ETag"), rec.Header().Get("Last-Modified")
+		if etag == "" || lastModified != "Wed, 08 May 2024 00:00:00 GMT" {
+			t.Fatalf("unexpected validators %q %q", etag, lastModified)
+		}
+
+		if rec := request("/v1/feeds/approvals.rss", http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotModified {
+			t.Errorf("expected status %d for a matching etag got %d", http.StatusNotModified, rec.Code)
+		}
+		if rec := request("/v1/feeds/approvals.rss", http.Header{"If-Modified-Since": {lastModified}}); rec.Code != http.StatusNotModified {
+			t.Errorf("expected status %d when unmodified got %d", http.StatusNotModified, rec.Code)
+		}
+
+		finished := time.Date(2024, time.May, 9, 12, 0, 0, 0, time.UTC)
+		if err := store.AddRun(context.Background(), Run{ID: "1", StartedAt: finished, FinishedAt: finished}); err != nil {
+			t.Fatal(err)
+		}
+		err := store.AddBalls(context.Background(), []Ball{{Brand: Hammer, Name: "Black Widow", ApprovalDate: approved}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec = request("/v1/feeds/approvals.rss", http.Header{"If-None-Match": {etag}})
+		if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+			t.Fatalf("expected the feed to change got %d %s", rec.Code, rec.Header().Get("ETag"))
+		}
+		if got := rec.Header().Get("Last-Modified"); got != "Thu, 09 May 2024 12:00:00 GMT" {
+			t.Errorf("expected the last run to be the last modification got %q", got)
+		}
+		if !strings.Contains(rec.Body.String(), "Black Widow") {
+			t.Error("expected the new ball in the feed")
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package balls
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// GoogleChatNotifier implements the Notifier interface and posts newly approved balls to a Google Chat webhook as
+// cards.
+type GoogleChatNotifier struct {
+	webhookNotifier
+}
+
+func NewGoogleChatNotifier(client *http.Client, webhookURL string, opts ...WebhookOption) *GoogleChatNotifier {
+	return &GoogleChatNotifier{webhookNotifier: newWebhookNotifier(client, webhookURL, opts)}
+}
+
+// Notify posts a message with a card per ball for each batch of the approved balls.
+func (n *GoogleChatNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	return n.notify(ctx, approvedBalls, func(batch, batches int, balls []Ball, messages []Message) any {
+		msg := googleChatMessage{Text: fmt.Sprintf("*%s*", ballCount(len(approvedBalls)))}
+		if batches > 1 {
+			msg.Text = fmt.Sprintf("*%s* (%d of %d)", ballCount(len(approvedBalls)), batch+1, batches)
+		}
+
+		for i, b := range balls {
+			msg.Cards = append(msg.Cards, googleChatCardWithID{
+				CardID: fmt.Sprintf("ball-%d", i),
+				Card:   googleChatBallCard(b, messages[i], n.styles.Style(b.Brand)),
+			})
+		}
+
+		return msg
+	})
+}
+
+func googleChatBallCard(b Ball, msg Message, style BrandStyle) googleChatCard {
+	var widgets []googleChatWidget
+	if msg.Description != "" {
+		widgets = append(widgets, googleChatWidget{TextParagraph: &googleChatText{Text: msg.Description}})
+	}
+	widgets = append(widgets, googleChatWidget{DecoratedText: &googleChatDecoratedText{
+		TopLabel: "Approved",
+		Text:     b.ApprovalDate.Format("January 2, 2006"),
+	}})
+	if b.ImageURL != nil {
+		widgets = append(widgets, googleChatWidget{Image: &googleChatImage{
+			ImageURL: b.ImageURL.String(),
+			AltText:  fmt.Sprintf("%s %s", b.Brand, b.Name),
+		}})
+	}
+	if style.Website != "" {
+		widgets = append(widgets, googleChatWidget{ButtonList: &googleChatButtonList{Buttons: []googleChatButton{{
+			Text:    "Website",
+			OnClick: googleChatOnClick{OpenLink: googleChatLink{URL: style.Website}},
+		}}}})
+	}
+
+	header := &googleChatHeader{Title: msg.Title, Subtitle: string(b.Brand.Manufacturer())}
+	if style.LogoURL != "" {
+		header.ImageURL = style.LogoURL
+		header.ImageType = "CIRCLE"
+	}
+
+	return googleChatCard{Header: header, Sections: []googleChatSection{{Widgets: widgets}}}
+}
+
+type googleChatMessage struct {
+	Text  string                 `json:"text,omitempty"`
+	Cards []googleChatCardWithID `json:"cardsV2,omitempty"`
+}
+
+type googleChatCardWithID struct {
+	CardID string         `json:"cardId"`
+	Card   googleChatCard `json:"card"`
+}
+
+type googleChatCard struct {
+	Header   *googleChatHeader   `json:"header,omitempty"`
+	Sections []googleChatSection `json:"sections"`
+}
+
+type googleChatHeader struct {
+	Title     string `json:"title"`
+	Subtitle  string `json:"subtitle,omitempty"`
+	ImageURL  string `json:"imageUrl,omitempty"`
+	ImageType string `json:"imageType,omitempty"`
+}
+
+type googleChatSection struct {
+	Widgets []googleChatWidget `json:"widgets"`
+}
+
+// googleChatWidget is one of the widgets used by notifications, exactly one field is set.
+type googleChatWidget struct {
+	TextParagraph *googleChatText          `json:"textParagraph,omitempty"`
+	DecoratedText *googleChatDecoratedText `json:"decoratedText,omitempty"`
+	Image         *googleChatImage         `json:"image,omitempty"`
+	ButtonList    *googleChatButtonList    `json:"buttonList,omitempty"`
+}
+
+type googleChatText struct {
+	Text string `json:"text"`
+}
+
+type googleChatDecoratedText struct {
+	TopLabel string `json:"topLabel"`
+	Text     string `json:"text"`
+}
+
+type googleChatImage struct {
+	ImageURL string `json:"imageUrl"`
+	AltText  string `json:"altText,omitempty"`
+}
+
+type googleChatButtonList struct {
+	Buttons []googleChatButton `json:"buttons"`
+}
+
+type googleChatButton struct {
+	Text    string            `json:"text"`
+	OnClick googleChatOnClick `json:"onClick"`
+}
+
+type googleChatOnClick struct {
+	OpenLink googleChatLink `json:"openLink"`
+}
+
+type googleChatLink struct {
+	URL string `json:"url"`
+}
@@ -0,0 +1,90 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HealthCheck checks whether a dependency of the service is usable.
+type HealthCheck struct {
+	// Name identifies the dependency in the readiness response.
+	Name string
+	// Check returns optional details about the dependency, or an error if it's unusable.
+	Check func(ctx context.Context) (string, error)
+}
+
+// HealthChecker is implemented by dependencies that can report whether they're usable, e.g. notifiers
+// verifying their credentials.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// notifierHealthTTL is how long the result of verifying the notifier's credentials is reused for, so frequent
+// readiness probes don't each call the notifier's API.
+const notifierHealthTTL = time.Minute
+
+// NotifierHealthCheck returns a check verifying the notifier's credentials. The check always passes when the
+// notifier doesn't implement HealthChecker. Results are cached for notifierHealthTTL.
+func NotifierHealthCheck(notifier Notifier) HealthCheck {
+	var (
+		mu        sync.Mutex
+		checkedAt time.Time
+		result    error
+	)
+
+	return HealthCheck{
+		Name: "notifier",
+		Check: func(ctx context.Context) (string, error) {
+			hc, ok := notifier.(HealthChecker)
+			if !ok {
+				return "no credentials to verify", nil
+			}
+
+			mu.Lock()
+			cached, err := !checkedAt.IsZero() && time.Since(checkedAt) < notifierHealthTTL, result
+			mu.Unlock()
+			if cached {
+				return "", err
+			}
+
+			err = hc.HealthCheck(ctx)
+			// A probe that gave up says nothing about the credentials, so it isn't cached.
+			if ctx.Err() != nil {
+				return "", err
+			}
+
+			mu.Lock()
+			checkedAt, result = time.Now(), err
+			mu.Unlock()
+
+			return "", err
+		},
+	}
+}
+
+// LastRunHealthCheck returns a check reporting the age of the last successful run, failing when it's older than
+// maxAge. A maxAge of zero only reports the age.
+func LastRunHealthCheck(store Store, maxAge time.Duration) HealthCheck {
+	return HealthCheck{
+		Name: "last_run",
+		Check: func(ctx context.Context) (string, error) {
+			run, err := store.GetLastSuccessfulRun(ctx)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					return "no successful runs recorded", nil
+				}
+				return "", fmt.Errorf("getting last successful run: %w", err)
+			}
+
+			age := time.Since(run.FinishedAt).Round(time.Second)
+			if maxAge > 0 && age > maxAge {
+				return "", fmt.Errorf("last successful run was %s ago, expected within %s", age, maxAge)
+			}
+
+			return fmt.Sprintf("last successful run was %s ago", age), nil
+		},
+	}
+}
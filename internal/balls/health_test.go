@@ -0,0 +1,141 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_handleReadyz(t *testing.T) {
+	t.Run("all dependencies available", func(t *testing.T) {
+		h := NewHTTPHandler(slog.Default(), nil, "test", WithHealthChecks(
+			HealthCheck{
+				Name: "database",
+				Check: func(ctx context.Context) (string, error) {
+					return "", nil
+				},
+			},
+		))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/readyz", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d got %d", http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("degraded dependency", func(t *testing.T) {
+		h := NewHTTPHandler(slog.Default(), nil, "test", WithHealthChecks(
+			HealthCheck{
+				Name: "database",
+				Check: func(ctx context.Context) (string, error) {
+					return "", nil
+				},
+			},
+			HealthCheck{
+				Name: "notifier",
+				Check: func(ctx context.Context) (string, error) {
+					return "", errors.New("invalid token")
+				},
+			},
+		))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/readyz", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected status %d got %d", http.StatusServiceUnavailable, rec.Code)
+		}
+
+		var body struct {
+			Status string                 `json:"status"`
+			Checks map[string]checkResult `json:"checks"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+
+		if body.Status != "degraded" {
+			t.Fatalf("expected degraded status got %s", body.Status)
+		}
+		if body.Checks["database"].Status != "ok" {
+			t.Fatalf("expected database ok got %s", body.Checks["database"].Status)
+		}
+		if body.Checks["notifier"].Error != "invalid token" {
+			t.Fatalf("expected notifier error got %q", body.Checks["notifier"].Error)
+		}
+	})
+}
+
+func TestLastRunHealthCheck(t *testing.T) {
+	t.Run("recent run", func(t *testing.T) {
+		store := &StoreMock{
+			GetLastSuccessfulRunFunc: func(ctx context.Context) (Run, error) {
+				return Run{FinishedAt: time.Now().Add(-10 * time.Minute)}, nil
+			},
+		}
+
+		_, err := LastRunHealthCheck(store, time.Hour).Check(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("stale run", func(t *testing.T) {
+		store := &StoreMock{
+			GetLastSuccessfulRunFunc: func(ctx context.Context) (Run, error) {
+				return Run{FinishedAt: time.Now().Add(-3 * time.Hour)}, nil
+			},
+		}
+
+		_, err := LastRunHealthCheck(store, time.Hour).Check(context.Background())
+		if err == nil {
+			t.Fatal("expected error got nil")
+		}
+	})
+
+	t.Run("no runs", func(t *testing.T) {
+		store := &StoreMock{
+			GetLastSuccessfulRunFunc: func(ctx context.Context) (Run, error) {
+				return Run{}, ErrNotFound
+			},
+		}
+
+		_, err := LastRunHealthCheck(store, time.Hour).Check(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+type healthCheckNotifier struct {
+	NotifierMock
+	check func(ctx context.Context) error
+}
+
+func (n *healthCheckNotifier) HealthCheck(ctx context.Context) error {
+	return n.check(ctx)
+}
+
+func TestNotifierHealthCheck(t *testing.T) {
+	var calls int
+	check := NotifierHealthCheck(&healthCheckNotifier{check: func(ctx context.Context) error {
+		calls++
+		return errors.New("invalid token")
+	}})
+
+	for i := 0; i < 3; i++ {
+		if _, err := check.Check(context.Background()); err == nil || err.Error() != "invalid token" {
+			t.Fatalf("expected invalid token error got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected the result to be cached got %d calls", calls)
+	}
+}
@@ -0,0 +1,95 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer is the httptest server the fakes of third party apis are built on. Its handlers run one at a
+// time so they can record the requests they're sent in their fake without locking, and handlers registered with
+// handleAuthorized answer requests without the server's bearer token with unauthorized.
+type recordingServer struct {
+	*httptest.Server
+
+	mux          *http.ServeMux
+	mu           sync.Mutex
+	token        string
+	unauthorized func(w http.ResponseWriter)
+}
+
+// newRecordingServer starts a recordingServer expecting token, which is closed when the test finishes.
+func newRecordingServer(t *testing.T, token string, unauthorized func(w http.ResponseWriter)) *recordingServer {
+	t.Helper()
+
+	s := &recordingServer{mux: http.NewServeMux(), token: token, unauthorized: unauthorized}
+	s.Server = httptest.NewServer(s.mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// handle registers h for pattern.
+func (s *recordingServer) handle(pattern string, h http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		h(w, r)
+	})
+}
+
+// handleAuthorized registers h for pattern, only passing it requests carrying the server's bearer token.
+func (s *recordingServer) handleAuthorized(pattern string, h http.HandlerFunc) {
+	s.handle(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.token {
+			s.unauthorized(w)
+			return
+		}
+
+		h(w, r)
+	})
+}
+
+// rateLimited reports whether a request should be rate limited, clearing limit so only the first one is.
+func rateLimited(limit *bool) bool {
+	if !*limit {
+		return false
+	}
+	*limit = false
+
+	return true
+}
+
+// writeJSON responds with status and v encoded as json.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// addNotifiedBalls stores balls as if a run had already notified of them.
+func addNotifiedBalls(t *testing.T, s Store, balls []Ball) {
+	t.Helper()
+
+	ctx := context.Background()
+	if err := s.AddBalls(ctx, balls); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := s.GetPendingBalls(ctx, maxNotifyAttempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, 0, len(pending))
+	for _, b := range pending {
+		ids = append(ids, b.ID)
+	}
+	if err := s.MarkBallsNotified(ctx, ids, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+}
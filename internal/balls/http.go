@@ -1,26 +1,218 @@
 package balls
 
 import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/actatum/approved-ball-list/internal/log"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 )
 
-func NewHTTPHandler(logger *slog.Logger, svc Service, env string) http.Handler {
+// HandlerOption configures the http handler.
+type HandlerOption interface {
+	apply(*handlerOptions)
+}
+
+type handlerOptions struct {
+	healthChecks  []HealthCheck
+	digests       map[string]*DigestNotifier
+	templates     map[string]MessageTemplates
+	emailStore    Store
+	emailNotifier *EmailNotifier
+	feeds         *feedsOption
+	adminToken    string
+	channelStore  Store
+	aliases       *ballAliasesOption
+}
+
+type healthChecksOption []HealthCheck
+
+func (o healthChecksOption) apply(opts *handlerOptions) {
+	opts.healthChecks = append(opts.healthChecks, o...)
+}
+
+// WithHealthChecks adds dependency checks reported by the readiness endpoint.
+func WithHealthChecks(checks ...HealthCheck) HandlerOption {
+	return healthChecksOption(checks)
+}
+
+type digestsOption []*DigestNotifier
+
+func (o digestsOption) apply(opts *handlerOptions) {
+	if opts.digests == nil {
+		opts.digests = make(map[string]*DigestNotifier, len(o))
+	}
+	for _, d := range o {
+		opts.digests[d.Name()] = d
+	}
+}
+
+// WithDigests allows digests to be sent on demand, e.g. by an external scheduler, with a POST to
+// /v1/digests/{name}.
+func WithDigests(digests ...*DigestNotifier) HandlerOption {
+	return digestsOption(digests)
+}
+
+type messageTemplatesOption struct {
+	destination string
+	templates   MessageTemplates
+}
+
+func (o messageTemplatesOption) apply(opts *handlerOptions) {
+	if opts.templates == nil {
+		opts.templates = make(map[string]MessageTemplates)
+	}
+	opts.templates[o.destination] = o.templates
+}
+
+// WithMessageTemplates sets the templates previewed for destination, the instant discord notifications are the
+// empty destination, digests are named after the digest and other destinations are named by their config path such
+// as email.
+func WithMessageTemplates(destination string, templates MessageTemplates) HandlerOption {
+	return messageTemplatesOption{destination: destination, templates: templates}
+}
+
+type emailSubscriptionStoreOption struct {
+	store    Store
+	notifier *EmailNotifier
+}
+
+func (o emailSubscriptionStoreOption) apply(opts *handlerOptions) {
+	opts.emailStore = o.store
+	opts.emailNotifier = o.notifier
+}
+
+// WithEmailSubscriptionStore allows addresses to subscribe at /v1/subscriptions/email. Subscriptions are added to
+// store pending until they're confirmed by the link notifier emails the address.
+func WithEmailSubscriptionStore(store Store, notifier *EmailNotifier) HandlerOption {
+	return emailSubscriptionStoreOption{store: store, notifier: notifier}
+}
+
+type feedsOption struct {
+	store    Store
+	renderer *Renderer
+	styles   BrandStyles
+}
+
+func (o feedsOption) apply(opts *handlerOptions) {
+	opts.feeds = &o
+}
+
+// WithFeeds serves RSS and Atom feeds of the approvals in store at /v1/feeds/approvals.rss and .atom, rendered by
+// renderer, or the DefaultMessageTemplates when it's nil, and linking to the brand websites in styles.
+func WithFeeds(store Store, renderer *Renderer, styles BrandStyles) HandlerOption {
+	return feedsOption{store: store, renderer: renderer, styles: styles}
+}
+
+type adminTokenOption string
+
+func (o adminTokenOption) apply(opts *handlerOptions) {
+	opts.adminToken = string(o)
+}
+
+// WithAdminToken requires requests to the administrative endpoints, /v1/cron, /v1/digests/{name},
+// /v1/channels/disabled, /v1/aliases and /v1/templates/preview, to carry token as a bearer token.
+func WithAdminToken(token string) HandlerOption {
+	return adminTokenOption(token)
+}
+
+type disabledChannelStoreOption struct {
+	store Store
+}
+
+func (o disabledChannelStoreOption) apply(opts *handlerOptions) {
+	opts.channelStore = o.store
+}
+
+// WithDisabledChannelStore lists the discord channels disabled in store at /v1/channels/disabled and enables them
+// again with a DELETE of /v1/channels/disabled/{id}, behind the admin token.
+func WithDisabledChannelStore(store Store) HandlerOption {
+	return disabledChannelStoreOption{store: store}
+}
+
+type ballAliasesOption struct {
+	store         Store
+	canonicalizer *Canonicalizer
+}
+
+func (o ballAliasesOption) apply(opts *handlerOptions) {
+	opts.aliases = &o
+}
+
+// WithBallAliasStore lists the manual merges of balls in store at /v1/aliases and adds them with a POST, behind the
+// admin token. Names are keyed with the alias rules of canonicalizer, merges are picked up by the next check.
+func WithBallAliasStore(store Store, canonicalizer *Canonicalizer) HandlerOption {
+	return ballAliasesOption{store: store, canonicalizer: canonicalizer}
+}
+
+func NewHTTPHandler(logger *slog.Logger, svc Service, env string, opts ...HandlerOption) http.Handler {
+	options := handlerOptions{}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
 	r := chi.NewRouter()
 
 	r.Use(
 		middleware.StripSlashes,
+		traceContext,
 		requestLogger(logger),
 		middleware.Recoverer,
 	)
 
 	r.Get("/v1/health", handleHealth(env))
-	r.Get("/v1/cron", handleCron(logger, svc))
+	r.Get("/v1/livez", handleLivez())
+	r.Get("/v1/readyz", handleReadyz(logger, options.healthChecks))
+	r.Get("/v1/balls", handleListBalls(logger, svc))
+	r.Get("/v1/balls/search", handleSearchBalls(logger, svc))
+	r.Get("/v1/brands", handleListBrands())
+	r.Group(func(r chi.Router) {
+		if options.adminToken != "" {
+			r.Use(requireAdminToken(options.adminToken))
+		}
+		r.Get("/v1/cron", handleCron(logger, svc))
+		r.Post("/v1/digests/{name}", handleSendDigest(logger, options.digests))
+		if options.channelStore != nil {
+			r.Get("/v1/channels/disabled", handleListDisabledChannels(logger, options.channelStore))
+			r.Delete("/v1/channels/disabled/{id}", handleEnableChannel(logger, options.channelStore))
+		}
+		if aliases := options.aliases; aliases != nil {
+			r.Get("/v1/aliases", handleListBallAliases(logger, aliases.store))
+			r.Post("/v1/aliases", handleAddBallAlias(logger, aliases.store, aliases.canonicalizer))
+		}
+		// Previews execute templates from the request, so without an admin token they're only served outside prod.
+		if options.adminToken != "" || env != "prod" {
+			r.Post("/v1/templates/preview", handlePreviewTemplates(logger, svc, options.templates))
+		}
+	})
+	if options.emailStore != nil {
+		r.Post("/v1/subscriptions/email", handleEmailSubscribe(logger, options.emailStore, options.emailNotifier))
+		r.Get("/v1/subscriptions/email/confirm", handleEmailSubscriptionPage(confirmEmailPage))
+		r.Post("/v1/subscriptions/email/confirm", handleConfirmEmailSubscription(logger, options.emailStore))
+		r.Get("/v1/subscriptions/email/unsubscribe", handleEmailSubscriptionPage(unsubscribeEmailPage))
+		r.Post("/v1/subscriptions/email/unsubscribe", handleEmailUnsubscribe(logger, options.emailStore))
+	}
+	if feeds := options.feeds; feeds != nil {
+		renderer := feeds.renderer
+		if renderer == nil {
+			renderer = defaultRenderer
+		}
+		r.Get("/v1/feeds/approvals.rss", handleFeed(logger, feeds.store, renderer, feeds.styles, rssFormat))
+		r.Get("/v1/feeds/approvals.atom", handleFeed(logger, feeds.store, renderer, feeds.styles, atomFormat))
+	}
 
 	return r
 }
@@ -37,9 +229,78 @@ func handleHealth(env string) http.HandlerFunc {
 	}
 }
 
+func handleLivez() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, map[string]any{
+			"status": "ok",
+		})
+	}
+}
+
+const healthCheckTimeout = 5 * time.Second
+
+type checkResult struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func handleReadyz(logger *slog.Logger, checks []HealthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		results := make([]checkResult, len(checks))
+
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				detail, err := check.Check(ctx)
+				if err != nil {
+					results[i] = checkResult{Status: "error", Error: err.Error()}
+					return
+				}
+				results[i] = checkResult{Status: "ok", Detail: detail}
+			}()
+		}
+		wg.Wait()
+
+		status := "ok"
+		resp := make(map[string]checkResult, len(checks))
+		for i, check := range checks {
+			resp[check.Name] = results[i]
+			if results[i].Status != "ok" {
+				status = "degraded"
+				logger.WarnContext(r.Context(), "dependency check failed",
+					slog.String("dependency", check.Name), slog.String("error", results[i].Error))
+			}
+		}
+
+		if status != "ok" {
+			render.Status(r, http.StatusServiceUnavailable)
+		}
+		render.JSON(w, r, map[string]any{
+			"status": status,
+			"checks": resp,
+		})
+	}
+}
+
 func handleCron(logger *slog.Logger, svc Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		err := svc.CheckForNewlyApprovedBalls(r.Context())
+		if errors.Is(err, ErrRunInProgress) {
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "run already in progress",
+				},
+			})
+			return
+		}
 		if err != nil {
 			logger.ErrorContext(r.Context(), "error checking for newly approved balls", slog.Any("error", err))
 			render.Status(r, http.StatusInternalServerError)
@@ -48,12 +309,487 @@ func handleCron(logger *slog.Logger, svc Service) http.HandlerFunc {
 					"message": "internal server error",
 				},
 			})
+			return
 		}
 
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
+func handleSendDigest(logger *slog.Logger, digests map[string]*DigestNotifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+		digest, ok := digests[name]
+		if !ok {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": fmt.Sprintf("unknown digest %q", name),
+				},
+			})
+			return
+		}
+
+		if err := digest.Send(r.Context()); err != nil {
+			logger.ErrorContext(r.Context(), "error sending digest", slog.String("digest", name), slog.Any("error", err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "internal server error",
+				},
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type disabledChannelResponse struct {
+	ChannelID  string    `json:"channel_id"`
+	Reason     string    `json:"reason"`
+	DisabledAt time.Time `json:"disabled_at"`
+}
+
+func handleListDisabledChannels(logger *slog.Logger, store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channels, err := store.GetDisabledChannels(r.Context())
+		if err != nil {
+			logger.ErrorContext(r.Context(), "error listing disabled channels", slog.Any("error", err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "internal server error",
+				},
+			})
+			return
+		}
+
+		resp := make([]disabledChannelResponse, 0, len(channels))
+		for _, c := range channels {
+			resp = append(resp, disabledChannelResponse{ChannelID: c.ChannelID, Reason: c.Reason, DisabledAt: c.DisabledAt})
+		}
+		sort.Slice(resp, func(i, j int) bool {
+			return resp[i].ChannelID < resp[j].ChannelID
+		})
+
+		render.JSON(w, r, map[string]any{
+			"channels": resp,
+		})
+	}
+}
+
+// handleEnableChannel enables a disabled channel so it's delivered to again from the next notification.
+func handleEnableChannel(logger *slog.Logger, store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if err := store.EnableChannel(r.Context(), id); err != nil {
+			logger.ErrorContext(r.Context(), "error enabling channel", slog.String("channel_id", id), slog.Any("error", err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "internal server error",
+				},
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type ballAliasResponse struct {
+	Brand     Brand  `json:"brand"`
+	Alias     string `json:"alias"`
+	Canonical string `json:"canonical"`
+}
+
+func handleListBallAliases(logger *slog.Logger, store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		aliases, err := store.GetBallAliases(r.Context())
+		if err != nil {
+			logger.ErrorContext(r.Context(), "error listing ball aliases", slog.Any("error", err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "internal server error",
+				},
+			})
+			return
+		}
+
+		resp := make([]ballAliasResponse, 0, len(aliases))
+		for _, a := range aliases {
+			resp = append(resp, ballAliasResponse(a))
+		}
+		sort.Slice(resp, func(i, j int) bool {
+			if resp[i].Brand != resp[j].Brand {
+				return resp[i].Brand < resp[j].Brand
+			}
+			return resp[i].Alias < resp[j].Alias
+		})
+
+		render.JSON(w, r, map[string]any{
+			"aliases": resp,
+		})
+	}
+}
+
+type ballAliasRequest struct {
+	Brand Brand `json:"brand"`
+	// Name is the ball to merge into the ball named Canonical, e.g. when the USBC corrects a misspelling, so it
+	// isn't announced again.
+	Name      string `json:"name"`
+	Canonical string `json:"canonical"`
+}
+
+// handleAddBallAlias merges a ball into another. Both names are keyed with the alias rules but not the existing
+// merges, so merges don't chain.
+func handleAddBallAlias(logger *slog.Logger, store Store, canonicalizer *Canonicalizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		badRequest := func(message string) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": message,
+				},
+			})
+		}
+
+		var req ballAliasRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			badRequest(fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+		if _, ok := LookupBrand(req.Brand); !ok {
+			badRequest(fmt.Sprintf("unknown brand %q", req.Brand))
+			return
+		}
+
+		alias := BallAlias{
+			Brand:     req.Brand,
+			Alias:     canonicalizer.ruleKey(req.Brand, req.Name),
+			Canonical: canonicalizer.ruleKey(req.Brand, req.Canonical),
+		}
+		if alias.Alias == "" || alias.Canonical == "" {
+			badRequest("name and canonical are required")
+			return
+		}
+		if alias.Alias == alias.Canonical {
+			badRequest(fmt.Sprintf("%q and %q are already the same ball", req.Name, req.Canonical))
+			return
+		}
+
+		if err := store.AddBallAlias(r.Context(), alias); err != nil {
+			logger.ErrorContext(r.Context(), "error adding ball alias", slog.Any("error", err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "internal server error",
+				},
+			})
+			return
+		}
+
+		render.JSON(w, r, ballAliasResponse(alias))
+	}
+}
+
+type ballResponse struct {
+	ID           int          `json:"id"`
+	Brand        Brand        `json:"brand"`
+	Manufacturer Manufacturer `json:"manufacturer"`
+	Name         string       `json:"name"`
+	ApprovalDate time.Time    `json:"approval_date"`
+	ImageURL     string       `json:"image_url,omitempty"`
+}
+
+func newBallResponse(b Ball) ballResponse {
+	resp := ballResponse{
+		ID:           b.ID,
+		Brand:        b.Brand,
+		Manufacturer: b.Brand.Manufacturer(),
+		Name:         b.Name,
+		ApprovalDate: b.ApprovalDate,
+	}
+	if b.ImageURL != nil {
+		resp.ImageURL = b.ImageURL.String()
+	}
+
+	return resp
+}
+
+func handleListBalls(logger *slog.Logger, svc Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var filter BallFilter
+		if b := r.URL.Query().Get("brand"); b != "" {
+			brand := Brand(b)
+			if _, ok := LookupBrand(brand); !ok {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, map[string]any{
+					"error": map[string]any{
+						"message": "unknown brand",
+					},
+				})
+				return
+			}
+			filter.Brand = &brand
+		}
+		if m := r.URL.Query().Get("manufacturer"); m != "" {
+			manufacturer := Manufacturer(m)
+			if len(manufacturer.Brands()) == 0 {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, map[string]any{
+					"error": map[string]any{
+						"message": "unknown manufacturer",
+					},
+				})
+				return
+			}
+			filter.Manufacturer = &manufacturer
+		}
+
+		balls, err := svc.ListBalls(r.Context(), filter)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "error listing balls", slog.Any("error", err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "internal server error",
+				},
+			})
+			return
+		}
+
+		resp := make([]ballResponse, 0, len(balls))
+		for _, b := range balls {
+			resp = append(resp, newBallResponse(b))
+		}
+
+		render.JSON(w, r, map[string]any{
+			"balls": resp,
+		})
+	}
+}
+
+type brandResponse struct {
+	Brand        Brand        `json:"brand"`
+	Manufacturer Manufacturer `json:"manufacturer"`
+}
+
+func handleListBrands() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp []brandResponse
+		for _, m := range Manufacturers() {
+			for _, b := range m.Brands() {
+				resp = append(resp, brandResponse{Brand: b, Manufacturer: m})
+			}
+		}
+
+		render.JSON(w, r, map[string]any{
+			"brands": resp,
+		})
+	}
+}
+
+type searchResultResponse struct {
+	ballResponse
+	Score float64 `json:"score"`
+}
+
+func handleSearchBalls(logger *slog.Logger, svc Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var limit int
+		if l := r.URL.Query().Get("limit"); l != "" {
+			var err error
+			if limit, err = strconv.Atoi(l); err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, map[string]any{
+					"error": map[string]any{
+						"message": "limit must be a number",
+					},
+				})
+				return
+			}
+		}
+
+		results, err := svc.SearchBalls(r.Context(), r.URL.Query().Get("q"), limit)
+		if errors.Is(err, ErrEmptyQuery) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "q is required",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.ErrorContext(r.Context(), "error searching balls", slog.Any("error", err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "internal server error",
+				},
+			})
+			return
+		}
+
+		resp := make([]searchResultResponse, 0, len(results))
+		for _, res := range results {
+			resp = append(resp, searchResultResponse{ballResponse: newBallResponse(res.Ball), Score: res.Score})
+		}
+
+		render.JSON(w, r, map[string]any{
+			"results": resp,
+		})
+	}
+}
+
+type previewTemplatesRequest struct {
+	// Destination selects the configured templates to preview, empty for the instant discord notifications.
+	Destination string `json:"destination"`
+	// Templates override the destination's configured templates.
+	Templates struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		DigestLine  string `json:"digest_line"`
+	} `json:"templates"`
+	// Query renders the templates against the approved balls best matching it instead of sample balls.
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+type messageResponse struct {
+	Ball        ballResponse `json:"ball"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	DigestLine  string       `json:"digest_line"`
+}
+
+const (
+	// maxPreviewBody bounds the size of preview requests.
+	maxPreviewBody = 64 << 10
+	// previewTimeout bounds how long a preview spends searching and rendering.
+	previewTimeout = 5 * time.Second
+)
+
+func handlePreviewTemplates(logger *slog.Logger, svc Service, templates map[string]MessageTemplates) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		badRequest := func(message string) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": message,
+				},
+			})
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), previewTimeout)
+		defer cancel()
+
+		var req previewTemplatesRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPreviewBody)).Decode(&req); err != nil {
+			badRequest(fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+
+		configured, ok := templates[req.Destination]
+		if !ok && req.Destination != "" {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": fmt.Sprintf("unknown destination %q", req.Destination),
+				},
+			})
+			return
+		}
+
+		renderer, err := NewRenderer(configured.Merge(MessageTemplates{
+			Title:       req.Templates.Title,
+			Description: req.Templates.Description,
+			DigestLine:  req.Templates.DigestLine,
+		}))
+		if err != nil {
+			badRequest(err.Error())
+			return
+		}
+
+		balls := SampleBalls()
+		if req.Query != "" {
+			results, err := svc.SearchBalls(ctx, req.Query, req.Limit)
+			if err != nil {
+				logger.ErrorContext(ctx, "error searching balls", slog.Any("error", err))
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, map[string]any{
+					"error": map[string]any{
+						"message": "internal server error",
+					},
+				})
+				return
+			}
+
+			balls = make([]Ball, 0, len(results))
+			for _, res := range results {
+				balls = append(balls, res.Ball)
+			}
+		}
+
+		ctx = ContextWithRun(ctx, Run{ID: "preview", StartedAt: time.Now(), Approved: len(balls)})
+		messages, err := renderer.RenderBalls(ctx, balls)
+		if err != nil {
+			badRequest(err.Error())
+			return
+		}
+
+		resp := make([]messageResponse, 0, len(messages))
+		for i, msg := range messages {
+			resp = append(resp, messageResponse{
+				Ball:        newBallResponse(balls[i]),
+				Title:       msg.Title,
+				Description: msg.Description,
+				DigestLine:  msg.DigestLine,
+			})
+		}
+
+		render.JSON(w, r, map[string]any{
+			"messages": resp,
+		})
+	}
+}
+
+// requireAdminToken rejects requests that don't carry token as a bearer token.
+func requireAdminToken(token string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, map[string]any{
+					"error": map[string]any{
+						"message": "unauthorized",
+					},
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// traceContext carries the trace from the incoming request headers in the request context so that logs can be
+// correlated with the request trace.
+func traceContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if trace, ok := log.TraceFromRequest(r); ok {
+			r = r.WithContext(log.ContextWithTrace(r.Context(), trace))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func requestLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -86,3 +822,185 @@ func requestLogger(logger *slog.Logger) func(next http.Handler) http.Handler {
 		})
 	}
 }
+
+// maxEmailSubscriptionBody bounds the size of subscription requests.
+const maxEmailSubscriptionBody = 4 << 10
+
+type emailSubscriptionRequest struct {
+	Email        string       `json:"email"`
+	Brand        Brand        `json:"brand"`
+	Manufacturer Manufacturer `json:"manufacturer"`
+	Name         string       `json:"name"`
+}
+
+// handleEmailSubscribe adds the subscription in the request body pending confirmation and emails the address the
+// link confirming it. The response is the same whether or not the address was already subscribed.
+func handleEmailSubscribe(logger *slog.Logger, store Store, notifier *EmailNotifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		badRequest := func(message string) {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": message,
+				},
+			})
+		}
+		internalError := func(msg string, err error) {
+			logger.ErrorContext(r.Context(), msg, slog.Any("error", err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, map[string]any{
+				"error": map[string]any{
+					"message": "internal server error",
+				},
+			})
+		}
+
+		var req emailSubscriptionRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEmailSubscriptionBody)).Decode(&req); err != nil {
+			badRequest(fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+
+		addr, err := mail.ParseAddress(req.Email)
+		if err != nil {
+			badRequest(fmt.Sprintf("invalid email: %v", err))
+			return
+		}
+		if _, ok := LookupBrand(req.Brand); req.Brand != "" && !ok {
+			badRequest(fmt.Sprintf("unknown brand %q", req.Brand))
+			return
+		}
+		if req.Manufacturer != "" && len(req.Manufacturer.Brands()) == 0 {
+			badRequest(fmt.Sprintf("unknown manufacturer %q", req.Manufacturer))
+			return
+		}
+		if req.Brand != "" && req.Manufacturer != "" {
+			badRequest("subscribe to a brand or a manufacturer, not both")
+			return
+		}
+
+		now := time.Now()
+		// Addresses are compared ignoring case so the limits can't be sidestepped by changing it.
+		sub, err := NewEmailSubscription(strings.ToLower(addr.Address), req.Brand, req.Manufacturer, req.Name, now)
+		if err != nil {
+			internalError("error creating email subscription", err)
+			return
+		}
+
+		if _, err = store.RemovePendingEmailSubscriptions(r.Context(), now.Add(-emailConfirmationTTL)); err != nil {
+			internalError("error removing expired email subscriptions", err)
+			return
+		}
+		subs, err := store.GetAddressEmailSubscriptions(r.Context(), sub.Email)
+		if err != nil {
+			internalError("error getting email subscriptions", err)
+			return
+		}
+
+		// Addresses already subscribed, waiting to confirm, or at their limits aren't emailed again so the endpoint
+		// can't be used to flood someone's inbox. The response doesn't say which, so it can't be used to find out
+		// what an address is subscribed to either.
+		added := false
+		if allowEmailSubscription(subs, now) {
+			added, err = store.AddEmailSubscription(r.Context(), sub)
+			if err != nil {
+				internalError("error adding email subscription", err)
+				return
+			}
+		}
+		if added {
+			if err = notifier.SendConfirmation(r.Context(), sub); err != nil {
+				if rmErr := store.RemoveEmailSubscription(r.Context(), sub.Token); rmErr != nil {
+					err = errors.Join(err, rmErr)
+				}
+				internalError("error sending email subscription confirmation", err)
+				return
+			}
+		}
+
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, map[string]any{
+			"message": "follow the link emailed to the address to confirm the subscription",
+		})
+	}
+}
+
+// handleEmailSubscriptionPage serves the page asking to confirm a link's action. Links only act when the page's
+// form is submitted, so mail scanners and prefetchers following them don't.
+func handleEmailSubscriptionPage(page emailPage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Query()["token"]) == 0 {
+			renderEmailPage(w, http.StatusBadRequest, emailPage{Title: page.Title, Message: missingTokenMessage})
+			return
+		}
+
+		page.Action = r.URL.RequestURI()
+		renderEmailPage(w, http.StatusOK, page)
+	}
+}
+
+func handleConfirmEmailSubscription(logger *slog.Logger, store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.FormValue("token")
+		if token == "" {
+			renderEmailPage(w, http.StatusBadRequest, emailPage{Title: confirmEmailPage.Title, Message: missingTokenMessage})
+			return
+		}
+
+		now := time.Now()
+		// Expired subscriptions are removed first so their links stop working.
+		_, err := store.RemovePendingEmailSubscriptions(r.Context(), now.Add(-emailConfirmationTTL))
+		if err == nil {
+			err = store.ConfirmEmailSubscription(r.Context(), token, now)
+		}
+		switch {
+		case errors.Is(err, ErrNotFound):
+			renderEmailPage(w, http.StatusNotFound, emailPage{
+				Title:   confirmEmailPage.Title,
+				Message: "This subscription doesn't exist, it may have been unsubscribed or not confirmed in time.",
+			})
+		case err != nil:
+			logger.ErrorContext(r.Context(), "error confirming email subscription", slog.Any("error", err))
+			renderEmailPage(w, http.StatusInternalServerError, emailPage{
+				Title:   confirmEmailPage.Title,
+				Message: "Something went wrong, try again later.",
+			})
+		default:
+			renderEmailPage(w, http.StatusOK, emailPage{
+				Title:   confirmEmailPage.Title,
+				Message: "You're subscribed. Every email has a link to unsubscribe.",
+			})
+		}
+	}
+}
+
+// handleEmailUnsubscribe removes the subscriptions with the tokens in the query or form, it's also the target of
+// one-click unsubscribing from mail clients. Unsubscribing twice succeeds.
+func handleEmailUnsubscribe(logger *slog.Logger, store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || len(r.Form["token"]) == 0 {
+			renderEmailPage(w, http.StatusBadRequest, emailPage{
+				Title:   unsubscribeEmailPage.Title,
+				Message: missingTokenMessage,
+			})
+			return
+		}
+
+		for _, token := range r.Form["token"] {
+			err := store.RemoveEmailSubscription(r.Context(), token)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				logger.ErrorContext(r.Context(), "error removing email subscription", slog.Any("error", err))
+				renderEmailPage(w, http.StatusInternalServerError, emailPage{
+					Title:   unsubscribeEmailPage.Title,
+					Message: "Something went wrong, try again later.",
+				})
+				return
+			}
+		}
+
+		renderEmailPage(w, http.StatusOK, emailPage{
+			Title:   unsubscribeEmailPage.Title,
+			Message: "You're unsubscribed and won't be emailed these balls again.",
+		})
+	}
+}
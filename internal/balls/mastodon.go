@@ -0,0 +1,279 @@
+package balls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	maxMastodonChars     = 500
+	maxMastodonAltText   = 1500
+	maxMastodonImageSize = 16 << 20
+
+	// Media that's still being processed is polled until it's ready to attach.
+	maxMastodonMediaPolls        = 10
+	defaultMastodonMediaPollWait = time.Second
+)
+
+// MastodonNotifier implements the Notifier interface and posts newly approved balls to a Mastodon account, with
+// their images, threading replies when they don't fit in one post.
+type MastodonNotifier struct {
+	rendering
+
+	client      *http.Client
+	server      string
+	accessToken string
+	visibility  string
+	mediaPoll   time.Duration
+
+	// Idempotency keys stop a retried post being published twice.
+	keyPrefix string
+	key       atomic.Uint64
+}
+
+// MastodonOption configures a MastodonNotifier.
+type MastodonOption interface {
+	applyMastodon(*MastodonNotifier)
+}
+
+func (o NotifierOption) applyMastodon(n *MastodonNotifier) { o.applyRendering(&n.rendering) }
+
+type mastodonVisibilityOption string
+
+func (o mastodonVisibilityOption) applyMastodon(n *MastodonNotifier) {
+	n.visibility = string(o)
+}
+
+// WithMastodonVisibility sets the visibility of posts, public, unlisted, private or direct. Posts are public by
+// default.
+func WithMastodonVisibility(visibility string) MastodonOption {
+	return mastodonVisibilityOption(visibility)
+}
+
+// NewMastodonNotifier returns a notifier posting to the account on server authenticated by accessToken, which needs
+// the write:media and write:statuses scopes.
+func NewMastodonNotifier(client *http.Client, server, accessToken string, opts ...MastodonOption) *MastodonNotifier {
+	n := &MastodonNotifier{
+		client:      client,
+		server:      strings.TrimSuffix(server, "/"),
+		accessToken: accessToken,
+		visibility:  "public",
+		mediaPoll:   defaultMastodonMediaPollWait,
+		keyPrefix:   strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+	for _, opt := range opts {
+		opt.applyMastodon(n)
+	}
+	if n.client == nil {
+		n.client = http.DefaultClient
+	}
+	if n.renderer == nil {
+		n.renderer = defaultRenderer
+	}
+
+	return n
+}
+
+// Notify posts a thread listing the approved balls, attaching the image of each ball to the post mentioning it.
+// Images that can't be uploaded are left out.
+func (n *MastodonNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	if len(approvedBalls) == 0 {
+		return nil
+	}
+
+	messages, err := n.renderer.RenderBalls(ctx, approvedBalls)
+	if err != nil {
+		return err
+	}
+	titles := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		titles = append(titles, msg.Title)
+	}
+	posts := composeThread(ballCount(len(approvedBalls)), titles, maxMastodonChars, maxSocialImages)
+
+	var (
+		errs    []error
+		replyTo string
+	)
+	for i, post := range posts {
+		var mediaIDs []string
+		for _, idx := range post.Balls {
+			b := approvedBalls[idx]
+			if b.ImageURL == nil || b.ImageURL.String() == "" {
+				continue
+			}
+			id, err := n.uploadImage(ctx, b)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("uploading image of %s: %w", titles[idx], err))
+				continue
+			}
+			mediaIDs = append(mediaIDs, id)
+		}
+
+		id, err := n.postStatus(ctx, mastodonStatus{
+			Status:      post.Text,
+			MediaIDs:    mediaIDs,
+			InReplyToID: replyTo,
+			Visibility:  n.visibility,
+			Language:    "en",
+		})
+		if err != nil {
+			// The rest of the thread has nothing to reply to.
+			return errors.Join(append(errs, fmt.Errorf("posting %d of %d: %w", i+1, len(posts), err))...)
+		}
+		replyTo = id
+	}
+
+	return errors.Join(errs...)
+}
+
+// uploadImage uploads the ball's image with alt text, returning the ID of the attachment once it's processed.
+func (n *MastodonNotifier) uploadImage(ctx context.Context, b Ball) (string, error) {
+	data, mimeType, err := downloadImage(ctx, n.client, b.ImageURL, maxMastodonImageSize)
+	if err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition",
+		fmt.Sprintf(`form-data; name="file"; filename=%q`, path.Base(b.ImageURL.Path)))
+	header.Set("Content-Type", mimeType)
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return "", fmt.Errorf("creating form: %w", err)
+	}
+	if _, err = part.Write(data); err != nil {
+		return "", fmt.Errorf("creating form: %w", err)
+	}
+	if err = w.WriteField("description", truncate(socialAltText(b), maxMastodonAltText)); err != nil {
+		return "", fmt.Errorf("creating form: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return "", fmt.Errorf("creating form: %w", err)
+	}
+
+	var media mastodonMedia
+	status, err := n.do(ctx, http.MethodPost, "/api/v2/media", w.FormDataContentType(), body.Bytes(), "", &media)
+	if err != nil {
+		return "", err
+	}
+
+	// Large images are processed asynchronously and can't be attached until they are, the media responds with
+	// 206 Partial Content while it's processing.
+	for poll := 0; status == http.StatusAccepted || status == http.StatusPartialContent; poll++ {
+		if poll == maxMastodonMediaPolls {
+			return "", fmt.Errorf("media %s is still processing", media.ID)
+		}
+		timer := time.NewTimer(n.mediaPoll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+		if status, err = n.do(ctx, http.MethodGet, "/api/v1/media/"+url.PathEscape(media.ID), "", nil, "", nil); err != nil {
+			return "", err
+		}
+	}
+
+	return media.ID, nil
+}
+
+// postStatus publishes status, returning its ID.
+func (n *MastodonNotifier) postStatus(ctx context.Context, status mastodonStatus) (string, error) {
+	body, err := json.Marshal(status)
+	if err != nil {
+		return "", fmt.Errorf("marshaling status: %w", err)
+	}
+
+	key := fmt.Sprintf("abl-%s-%d", n.keyPrefix, n.key.Add(1))
+	var posted struct {
+		ID string `json:"id"`
+	}
+	if _, err := n.do(ctx, http.MethodPost, "/api/v1/statuses", "application/json", body, key, &posted); err != nil {
+		return "", err
+	}
+
+	return posted.ID, nil
+}
+
+type mastodonStatus struct {
+	Status      string   `json:"status"`
+	MediaIDs    []string `json:"media_ids,omitempty"`
+	InReplyToID string   `json:"in_reply_to_id,omitempty"`
+	Visibility  string   `json:"visibility,omitempty"`
+	Language    string   `json:"language,omitempty"`
+}
+
+type mastodonMedia struct {
+	ID string `json:"id"`
+}
+
+// MastodonError is returned when the server rejects a request.
+type MastodonError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *MastodonError) Error() string {
+	return fmt.Sprintf("mastodon error %d: %s", e.StatusCode, e.Message)
+}
+
+// do makes an authenticated request to the server, decoding the response into out if it's not nil, and returns its
+// status.
+func (n *MastodonNotifier) do(
+	ctx context.Context,
+	method, endpoint, contentType string,
+	body []byte,
+	idempotencyKey string,
+	out any,
+) (int, error) {
+	resp, err := doWithRetry(ctx, n.client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, n.server+endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+n.accessToken)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		return req, nil
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if !resp.OK() {
+		var mastodonErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(resp.Body, &mastodonErr) != nil || mastodonErr.Error == "" {
+			mastodonErr.Error = string(resp.Body)
+		}
+		return resp.StatusCode, &MastodonError{StatusCode: resp.StatusCode, Message: mastodonErr.Error}
+	}
+	if out != nil {
+		if err := json.Unmarshal(resp.Body, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("decoding response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,193 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeMastodon is a Mastodon server recording the media uploaded and statuses posted to it. slow.png is processed
+// asynchronously, as large uploads are.
+type fakeMastodon struct {
+	rateLimit  bool
+	media      []mastodonUpload
+	processing map[string]int
+	statuses   []mastodonStatus
+	keys       map[string]bool
+}
+
+type mastodonUpload struct {
+	Filename    string
+	Description string
+}
+
+func newFakeMastodon(t *testing.T, f *fakeMastodon) string {
+	t.Helper()
+
+	f.processing = make(map[string]int)
+	f.keys = make(map[string]bool)
+	mastodonError := func(w http.ResponseWriter, status int, msg string) {
+		writeJSON(w, status, map[string]string{"error": msg})
+	}
+
+	srv := newRecordingServer(t, "access-token", func(w http.ResponseWriter) {
+		mastodonError(w, http.StatusUnauthorized, "The access token is invalid")
+	})
+	srv.handleAuthorized("POST /api/v2/media", func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			mastodonError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		data, _ := io.ReadAll(file)
+		if header.Header.Get("Content-Type") != "image/png" || !strings.HasPrefix(string(data), "\x89PNG") {
+			mastodonError(w, http.StatusUnprocessableEntity, "Validation failed: File has contents that are not what they are reported to be")
+			return
+		}
+
+		f.media = append(f.media, mastodonUpload{Filename: header.Filename, Description: r.FormValue("description")})
+		id := strconv.Itoa(len(f.media))
+		status := http.StatusOK
+		if header.Filename == "slow.png" {
+			f.processing[id] = 2
+			status = http.StatusAccepted
+		}
+		writeJSON(w, status, map[string]string{"id": id})
+	})
+	srv.handleAuthorized("GET /api/v1/media/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		status := http.StatusOK
+		if f.processing[id] > 0 {
+			f.processing[id]--
+			status = http.StatusPartialContent
+		}
+		writeJSON(w, status, map[string]string{"id": id})
+	})
+	srv.handleAuthorized("POST /api/v1/statuses", func(w http.ResponseWriter, r *http.Request) {
+		if rateLimited(&f.rateLimit) {
+			w.Header().Set("Retry-After", "0")
+			mastodonError(w, http.StatusTooManyRequests, "Too many requests")
+			return
+		}
+
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || f.keys[key] {
+			mastodonError(w, http.StatusUnprocessableEntity, "expected a new idempotency key")
+			return
+		}
+		f.keys[key] = true
+
+		var status mastodonStatus
+		if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+			mastodonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len([]rune(status.Status)) > maxMastodonChars || len(status.MediaIDs) > 4 {
+			mastodonError(w, http.StatusUnprocessableEntity, "Validation failed: Text character limit of 500 exceeded")
+			return
+		}
+		for _, id := range status.MediaIDs {
+			if f.processing[id] > 0 {
+				mastodonError(w, http.StatusUnprocessableEntity, "Cannot attach files that have not finished processing")
+				return
+			}
+		}
+
+		f.statuses = append(f.statuses, status)
+		writeJSON(w, http.StatusOK, map[string]string{"id": "s" + strconv.Itoa(len(f.statuses))})
+	})
+
+	return srv.URL
+}
+
+func TestMastodonNotifier_Notify(t *testing.T) {
+	image := newImageServer(t)
+	approved := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	renderer, err := NewRenderer(MessageTemplates{Title: `{{upper .Ball.Name}} by {{.Ball.Brand}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		balls        []Ball
+		opts         []MastodonOption
+		rateLimit    bool
+		wantStatuses []mastodonStatus
+		wantMedia    []mastodonUpload
+		wantErr      string
+	}{
+		{
+			// Images are attached four to a post, the missing one is reported and the slow one waited for.
+			name: "thread",
+			balls: []Ball{
+				{Brand: Storm, Name: "Phaze II", ImageURL: image("phaze.png"), ApprovalDate: approved},
+				{Brand: Motiv, Name: "Venom Shock", ApprovalDate: approved},
+				{Brand: RotoGrip, Name: "Idol", ImageURL: image("missing.png"), ApprovalDate: approved},
+				{Brand: Hammer, Name: "Black Widow", ImageURL: image("slow.png"), ApprovalDate: approved},
+				{Brand: Storm, Name: "Hustle", ImageURL: image("hustle.png"), ApprovalDate: approved},
+			},
+			opts:      []MastodonOption{WithMastodonVisibility("unlisted")},
+			rateLimit: true,
+			wantStatuses: []mastodonStatus{
+				{
+					Status: "5 newly approved balls\n• Storm Phaze II\n• Motiv Venom Shock\n• Roto Grip Idol\n" +
+						"• Hammer Black Widow (1/2)",
+					MediaIDs:   []string{"1", "2"},
+					Visibility: "unlisted",
+					Language:   "en",
+				},
+				{
+					Status:      "• Storm Hustle (2/2)",
+					MediaIDs:    []string{"3"},
+					InReplyToID: "s1",
+					Visibility:  "unlisted",
+					Language:    "en",
+				},
+			},
+			wantMedia: []mastodonUpload{
+				{Filename: "phaze.png", Description: "Product photo of the Storm Phaze II bowling ball"},
+				{Filename: "slow.png", Description: "Product photo of the Hammer Black Widow bowling ball"},
+				{Filename: "hustle.png", Description: "Product photo of the Storm Hustle bowling ball"},
+			},
+			wantErr: "uploading image of Roto Grip Idol",
+		},
+		{
+			name:  "renderer",
+			balls: []Ball{{Brand: Motiv, Name: "Venom Shock", ApprovalDate: approved}},
+			opts:  []MastodonOption{WithRenderer(renderer)},
+			wantStatuses: []mastodonStatus{
+				{Status: "1 newly approved ball\n• VENOM SHOCK by Motiv", Visibility: "public", Language: "en"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeMastodon{rateLimit: tt.rateLimit}
+			n := NewMastodonNotifier(nil, newFakeMastodon(t, fake)+"/", "access-token", tt.opts...)
+			n.mediaPoll = time.Millisecond
+
+			err := n.Notify(context.Background(), tt.balls)
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected %q got %v", tt.wantErr, err)
+			}
+
+			if diff := cmp.Diff(fake.statuses, tt.wantStatuses); diff != "" {
+				t.Errorf("(-got, +want):\n%s", diff)
+			}
+			if diff := cmp.Diff(fake.media, tt.wantMedia); diff != "" {
+				t.Errorf("(-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
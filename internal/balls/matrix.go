@@ -0,0 +1,245 @@
+package balls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxMatrixImageSize bounds the ball images downloaded to upload to the homeserver.
+const maxMatrixImageSize = 10 << 20
+
+// MatrixNotifier implements the Notifier interface and sends newly approved balls to Matrix rooms with the
+// client-server API, as a message listing them followed by their images.
+type MatrixNotifier struct {
+	rendering
+
+	client      *http.Client
+	homeserver  string
+	accessToken string
+	rooms       []string
+
+	// Transaction IDs make retried sends idempotent, they only need to be unique per access token.
+	txnPrefix string
+	txn       atomic.Uint64
+}
+
+// MatrixOption configures a MatrixNotifier.
+type MatrixOption interface {
+	applyMatrix(*MatrixNotifier)
+}
+
+func (o NotifierOption) applyMatrix(n *MatrixNotifier) { o.applyRendering(&n.rendering) }
+
+// NewMatrixNotifier returns a notifier sending to rooms, room IDs such as !abc:example.org, on homeserver as the user
+// authenticated by accessToken. The user has to have joined the rooms.
+func NewMatrixNotifier(
+	client *http.Client,
+	homeserver, accessToken string,
+	rooms []string,
+	opts ...MatrixOption,
+) *MatrixNotifier {
+	n := &MatrixNotifier{
+		client:      client,
+		homeserver:  strings.TrimSuffix(homeserver, "/"),
+		accessToken: accessToken,
+		rooms:       rooms,
+		txnPrefix:   strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+	for _, opt := range opts {
+		opt.applyMatrix(n)
+	}
+	if n.client == nil {
+		n.client = http.DefaultClient
+	}
+	if n.renderer == nil {
+		n.renderer = defaultRenderer
+	}
+
+	return n
+}
+
+// Notify uploads the balls' images once and sends each room a message listing the balls followed by an image per
+// ball. Images that can't be uploaded are left out, and a room that fails doesn't stop the others being sent to.
+func (n *MatrixNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	if len(approvedBalls) == 0 {
+		return nil
+	}
+
+	messages, err := n.renderer.RenderBalls(ctx, approvedBalls)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	events := []matrixMessage{matrixListMessage(approvedBalls, messages, n.styles)}
+	for i, b := range approvedBalls {
+		if b.ImageURL == nil || b.ImageURL.String() == "" {
+			continue
+		}
+		image, err := n.uploadImage(ctx, b.ImageURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("uploading image of %s: %w", messages[i].Title, err))
+			continue
+		}
+		image.Body = fmt.Sprintf("%s %s", b.Brand, b.Name)
+		events = append(events, image)
+	}
+
+	for _, room := range n.rooms {
+		for i, event := range events {
+			if err := n.sendMessage(ctx, room, event); err != nil {
+				errs = append(errs, fmt.Errorf("room %s: message %d of %d: %w", room, i+1, len(events), err))
+				break
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// matrixListMessage lists the balls, with an HTML body linking each title to the brand's website.
+func matrixListMessage(approvedBalls []Ball, messages []Message, styles BrandStyles) matrixMessage {
+	heading := ballCount(len(approvedBalls))
+	text := []string{heading}
+	formatted := []string{"<h4>" + html.EscapeString(heading) + "</h4>"}
+	for i, b := range approvedBalls {
+		msg := messages[i]
+		text = append(text, strings.TrimSpace(msg.Title+"\n"+msg.Description))
+
+		title := "<b>" + html.EscapeString(msg.Title) + "</b>"
+		if website := styles.Style(b.Brand).Website; website != "" {
+			title = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(website), title)
+		}
+		if msg.Description != "" {
+			title += "<br>" + strings.ReplaceAll(html.EscapeString(msg.Description), "\n", "<br>")
+		}
+		formatted = append(formatted, "<p>"+title+"</p>")
+	}
+
+	return matrixMessage{
+		MsgType:       "m.text",
+		Body:          strings.Join(text, "\n\n"),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: strings.Join(formatted, ""),
+	}
+}
+
+// uploadImage downloads the image at u and uploads it to the homeserver's media repository, returning the m.image
+// message showing it.
+func (n *MatrixNotifier) uploadImage(ctx context.Context, u *url.URL) (matrixMessage, error) {
+	data, mimeType, err := downloadImage(ctx, n.client, u, maxMatrixImageSize)
+	if err != nil {
+		return matrixMessage{}, err
+	}
+
+	filename := path.Base(u.Path)
+	var upload struct {
+		ContentURI string `json:"content_uri"`
+	}
+	endpoint := "/_matrix/media/v3/upload?filename=" + url.QueryEscape(filename)
+	if err := n.do(ctx, http.MethodPost, endpoint, mimeType, data, &upload); err != nil {
+		return matrixMessage{}, err
+	}
+
+	return matrixMessage{
+		MsgType:  "m.image",
+		Filename: filename,
+		URL:      upload.ContentURI,
+		Info:     &matrixImageInfo{MimeType: mimeType, Size: len(data)},
+	}, nil
+}
+
+// sendMessage sends msg to room, reusing its transaction ID when retried so it's only sent once.
+func (n *MatrixNotifier) sendMessage(ctx context.Context, room string, msg matrixMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+
+	txnID := fmt.Sprintf("abl.%s.%d", n.txnPrefix, n.txn.Add(1))
+	endpoint := fmt.Sprintf("/_matrix/client/v3/rooms/%s/send/m.room.message/%s", url.PathEscape(room), txnID)
+
+	return n.do(ctx, http.MethodPut, endpoint, "application/json", body, nil)
+}
+
+type matrixMessage struct {
+	MsgType       string           `json:"msgtype"`
+	Body          string           `json:"body"`
+	Format        string           `json:"format,omitempty"`
+	FormattedBody string           `json:"formatted_body,omitempty"`
+	Filename      string           `json:"filename,omitempty"`
+	URL           string           `json:"url,omitempty"`
+	Info          *matrixImageInfo `json:"info,omitempty"`
+}
+
+type matrixImageInfo struct {
+	MimeType string `json:"mimetype"`
+	Size     int    `json:"size"`
+}
+
+// MatrixError is returned when the homeserver rejects a request.
+type MatrixError struct {
+	StatusCode int
+	ErrCode    string `json:"errcode"`
+	Message    string `json:"error"`
+}
+
+func (e *MatrixError) Error() string {
+	return fmt.Sprintf("matrix error %d %s: %s", e.StatusCode, e.ErrCode, e.Message)
+}
+
+// do makes an authenticated request to the homeserver, decoding the response into out if it's not nil and waiting
+// out and retrying rate limits.
+func (n *MatrixNotifier) do(ctx context.Context, method, endpoint, contentType string, body []byte, out any) error {
+	resp, err := doWithRetry(ctx, n.client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, n.homeserver+endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+n.accessToken)
+		req.Header.Set("Content-Type", contentType)
+		return req, nil
+	}, matrixRetryAfter)
+	if err != nil {
+		return fmt.Errorf("calling homeserver: %w", err)
+	}
+
+	if !resp.OK() {
+		matrixErr := &MatrixError{}
+		if json.Unmarshal(resp.Body, matrixErr) != nil || matrixErr.ErrCode == "" {
+			matrixErr.Message = string(resp.Body)
+		}
+		matrixErr.StatusCode = resp.StatusCode
+		return matrixErr
+	}
+	if out != nil {
+		if err := json.Unmarshal(resp.Body, out); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// matrixRetryAfter returns how long a rate limited response asks to wait for.
+func matrixRetryAfter(resp apiResponse) time.Duration {
+	var limited struct {
+		RetryAfterMS int `json:"retry_after_ms"`
+	}
+	if json.Unmarshal(resp.Body, &limited) != nil {
+		return 0
+	}
+
+	return time.Duration(limited.RetryAfterMS) * time.Millisecond
+}
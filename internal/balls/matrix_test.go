@@ -0,0 +1,156 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeMatrix is a homeserver recording the images uploaded to it and the messages sent. The bot isn't in
+// !forbidden:example.org.
+type fakeMatrix struct {
+	rateLimit bool
+	uploads   []string
+	messages  []matrixEvent
+}
+
+type matrixEvent struct {
+	Room    string
+	TxnID   string
+	Message matrixMessage
+}
+
+func newFakeMatrix(t *testing.T, f *fakeMatrix) string {
+	t.Helper()
+
+	matrixError := func(w http.ResponseWriter, status int, errcode string, extra map[string]any) {
+		body := map[string]any{"errcode": errcode, "error": strings.ToLower(errcode)}
+		for k, v := range extra {
+			body[k] = v
+		}
+		writeJSON(w, status, body)
+	}
+
+	srv := newRecordingServer(t, "access-token", func(w http.ResponseWriter) {
+		matrixError(w, http.StatusUnauthorized, "M_UNKNOWN_TOKEN", nil)
+	})
+	srv.handleAuthorized("POST /_matrix/media/v3/upload", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "image/png" || !strings.HasPrefix(string(data), "\x89PNG") {
+			matrixError(w, http.StatusBadRequest, "M_INVALID_PARAM", nil)
+			return
+		}
+		f.uploads = append(f.uploads, r.URL.Query().Get("filename"))
+		writeJSON(w, http.StatusOK, map[string]string{"content_uri": fmt.Sprintf("mxc://example.org/%d", len(f.uploads))})
+	})
+	srv.handleAuthorized("PUT /_matrix/client/v3/rooms/{room}/send/m.room.message/{txn}", func(w http.ResponseWriter, r *http.Request) {
+		if rateLimited(&f.rateLimit) {
+			matrixError(w, http.StatusTooManyRequests, "M_LIMIT_EXCEEDED", map[string]any{"retry_after_ms": 1})
+			return
+		}
+		if r.PathValue("room") == "!forbidden:example.org" {
+			matrixError(w, http.StatusForbidden, "M_FORBIDDEN", nil)
+			return
+		}
+
+		event := matrixEvent{Room: r.PathValue("room"), TxnID: r.PathValue("txn")}
+		if err := json.NewDecoder(r.Body).Decode(&event.Message); err != nil {
+			matrixError(w, http.StatusBadRequest, "M_NOT_JSON", nil)
+			return
+		}
+		f.messages = append(f.messages, event)
+		writeJSON(w, http.StatusOK, map[string]string{"event_id": "$" + event.TxnID})
+	})
+
+	return srv.URL
+}
+
+// String summarizes the event for comparing in tests.
+func (e matrixEvent) String() string {
+	if e.Message.MsgType == "m.image" {
+		return fmt.Sprintf("%s %s: %s %s %s %d bytes",
+			e.Room, e.Message.MsgType, e.Message.Filename, e.Message.URL, e.Message.Info.MimeType, e.Message.Info.Size)
+	}
+	title, _, _ := strings.Cut(e.Message.Body, "\n")
+
+	return fmt.Sprintf("%s %s: %s", e.Room, e.Message.MsgType, title)
+}
+
+func TestMatrixNotifier_Notify(t *testing.T) {
+	image := newImageServer(t)
+	approved := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		rooms       []string
+		balls       []Ball
+		rateLimit   bool
+		wantUploads []string
+		wantEvents  []string
+		wantErrs    []string
+	}{
+		{
+			// The idol's image is left out and the forbidden room reported, the other room still gets the rest.
+			name:  "missing image and forbidden room",
+			rooms: []string{"!forbidden:example.org", "!room:example.org"},
+			balls: []Ball{
+				{Brand: Storm, Name: "Phaze II", ImageURL: image("phaze.png"), ApprovalDate: approved},
+				{Brand: Motiv, Name: "Venom Shock", ApprovalDate: approved},
+				{Brand: RotoGrip, Name: "Idol", ImageURL: image("missing.png"), ApprovalDate: approved},
+			},
+			rateLimit:   true,
+			wantUploads: []string{"phaze.png"},
+			wantEvents: []string{
+				"!room:example.org m.text: 3 newly approved balls",
+				fmt.Sprintf("!room:example.org m.image: phaze.png mxc://example.org/1 image/png %d bytes",
+					len("\x89PNG\r\n\x1a\nphaze.png")),
+			},
+			wantErrs: []string{"M_FORBIDDEN", "uploading image of Roto Grip Idol"},
+		},
+		{
+			name:       "without images",
+			rooms:      []string{"!room:example.org"},
+			balls:      []Ball{{Brand: Motiv, Name: "Venom Shock", ApprovalDate: approved}},
+			wantEvents: []string{"!room:example.org m.text: 1 newly approved ball"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeMatrix{rateLimit: tt.rateLimit}
+			n := NewMatrixNotifier(nil, newFakeMatrix(t, fake), "access-token", tt.rooms)
+
+			err := n.Notify(context.Background(), tt.balls)
+			if len(tt.wantErrs) == 0 && err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.wantErrs {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("expected an error including %q got %v", want, err)
+				}
+			}
+
+			got := make([]string, 0, len(fake.messages))
+			txnIDs := make(map[string]bool, len(fake.messages))
+			for _, event := range fake.messages {
+				got = append(got, event.String())
+				txnIDs[event.TxnID] = true
+			}
+			if diff := cmp.Diff(got, tt.wantEvents); diff != "" {
+				t.Fatalf("(-got, +want):\n%s", diff)
+			}
+			if diff := cmp.Diff(fake.uploads, tt.wantUploads); diff != "" {
+				t.Fatalf("(-got, +want):\n%s", diff)
+			}
+			if len(txnIDs) != len(fake.messages) {
+				t.Error("expected each message to have its own transaction id")
+			}
+		})
+	}
+}
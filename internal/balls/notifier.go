@@ -3,6 +3,10 @@ package balls
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -18,38 +22,340 @@ type Notifier interface {
 // DiscordNotifier implements the Notifier interface and sends notifications of newly approved balls to the
 // configured discord channels.
 type DiscordNotifier struct {
-	dg       *discordgo.Session
-	channels []string
+	rendering
+
+	dg        *discordgo.Session
+	channels  []string
+	batchSize int
+	logger    *slog.Logger
+	store     Store
+	// threads are the channels each ball gets its own forum post or thread in.
+	threads []string
+	// directMessages enables sending subscribed users direct messages.
+	directMessages bool
+
+	mu          sync.Mutex
+	channelInfo map[string]*discordgo.Channel
+	dmChannels  map[string]string
+}
+
+// rendering is how a notifier renders balls. Every notifier embeds it so a NotifierOption can configure any of them.
+type rendering struct {
+	renderer *Renderer
+	styles   BrandStyles
+}
+
+// NotifierOption configures how a notifier renders balls, it can be passed to the constructor of any notifier.
+type NotifierOption func(*rendering)
+
+// applyRendering configures the rendering a notifier embeds. Each notifier's options interface is satisfied by a
+// NotifierOption through a method next to the notifier calling it.
+func (o NotifierOption) applyRendering(r *rendering) { o(r) }
+
+// WithRenderer sets the templates notifications are rendered with, DefaultMessageTemplates are used otherwise.
+func WithRenderer(r *Renderer) NotifierOption {
+	return func(n *rendering) {
+		n.renderer = r
+	}
+}
+
+// WithBrandStyles overrides the registry's styling of brands in notifications.
+func WithBrandStyles(styles BrandStyles) NotifierOption {
+	return func(n *rendering) {
+		n.styles = styles
+	}
+}
+
+// DiscordNotifierOption configures the discord notifier.
+type DiscordNotifierOption interface {
+	apply(*DiscordNotifier)
+}
+
+func (o NotifierOption) apply(n *DiscordNotifier) { o.applyRendering(&n.rendering) }
+
+type batchSizeOption int
+
+func (o batchSizeOption) apply(n *DiscordNotifier) {
+	n.batchSize = int(o)
+}
+
+// WithBatchSize sets the number of embeds sent per message, discord allows at most 10.
+func WithBatchSize(size int) DiscordNotifierOption {
+	return batchSizeOption(size)
+}
+
+type discordLoggerOption struct {
+	logger *slog.Logger
+}
+
+func (o discordLoggerOption) apply(n *DiscordNotifier) {
+	n.logger = o.logger
+}
+
+// WithDiscordLogger sets the logger channels being disabled are logged to, slog.Default is used otherwise.
+func WithDiscordLogger(logger *slog.Logger) DiscordNotifierOption {
+	return discordLoggerOption{logger: logger}
+}
+
+type channelStoreOption struct {
+	store Store
+}
+
+func (o channelStoreOption) apply(n *DiscordNotifier) {
+	n.store = o.store
+}
+
+// WithChannelStore persists channels that fail permanently to store so they're skipped by later notifications,
+// without it they're only skipped for the rest of the notification.
+func WithChannelStore(store Store) DiscordNotifierOption {
+	return channelStoreOption{store: store}
+}
+
+type threadChannelsOption []string
+
+func (o threadChannelsOption) apply(n *DiscordNotifier) {
+	n.threads = o
 }
 
-func NewDiscordNotifier(dg *discordgo.Session, channels []string) *DiscordNotifier {
-	return &DiscordNotifier{dg: dg, channels: channels}
+// WithThreadChannels starts a discussion for each approved ball in channels: a post in forum channels, or a thread on
+// the ball's message in text channels.
+func WithThreadChannels(channels []string) DiscordNotifierOption {
+	return threadChannelsOption(channels)
+}
+
+type directMessagesOption struct{}
+
+func (directMessagesOption) apply(n *DiscordNotifier) {
+	n.directMessages = true
 }
 
+// WithDirectMessages sends users subscribed to approved balls with the /ball subscribe command a direct message,
+// requires WithChannelStore for the subscriptions.
+func WithDirectMessages() DiscordNotifierOption {
+	return directMessagesOption{}
+}
+
+const defaultBatchSize = 3
+
+func NewDiscordNotifier(dg *discordgo.Session, channels []string, opts ...DiscordNotifierOption) *DiscordNotifier {
+	n := &DiscordNotifier{
+		dg:          dg,
+		channels:    channels,
+		batchSize:   defaultBatchSize,
+		channelInfo: make(map[string]*discordgo.Channel),
+		dmChannels:  make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt.apply(n)
+	}
+	if n.batchSize < 1 {
+		n.batchSize = defaultBatchSize
+	}
+	if n.renderer == nil {
+		n.renderer = defaultRenderer
+	}
+	if n.logger == nil {
+		n.logger = slog.Default()
+	}
+
+	return n
+}
+
+// Notify sends the approved balls to every channel, returning a *DeliveryError if any channel wasn't delivered to.
 func (n *DiscordNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	report, err := n.Deliver(ctx, approvedBalls)
+	if err != nil {
+		return err
+	}
+
+	return report.Err()
+}
+
+// Deliver sends the approved balls to every channel and reports the outcome for each. The error is only non-nil
+// when the notification can't be rendered.
+func (n *DiscordNotifier) Deliver(ctx context.Context, approvedBalls []Ball) (DeliveryReport, error) {
 	if len(approvedBalls) == 0 {
-		return nil
+		return DeliveryReport{}, nil
 	}
 
+	messages, err := n.renderer.RenderBalls(ctx, approvedBalls)
+	if err != nil {
+		return DeliveryReport{}, err
+	}
+
+	footer, timestamp := runFooter(ctx)
+
 	embeds := make([]*discordgo.MessageEmbed, 0, len(approvedBalls))
-	for _, b := range approvedBalls {
-		embeds = append(embeds, &discordgo.MessageEmbed{
-			Type:  discordgo.EmbedTypeImage,
-			Title: fmt.Sprintf("%s %s", b.Brand, b.Name),
-			Image: &discordgo.MessageEmbedImage{
-				URL: b.ImageURL.String(),
-			},
-		})
-	}
-
-	batches := batchSlice(embeds, 3)
-
-	for _, id := range n.channels {
-		for _, batch := range batches {
-			if _, err := n.dg.ChannelMessageSendEmbeds(id, batch, discordgo.WithContext(ctx)); err != nil {
-				return fmt.Errorf("sending embeds: %w", err)
+	for i, b := range approvedBalls {
+		embed := ballEmbed(b, n.styles.Style(b.Brand))
+		embed.Title = truncate(messages[i].Title, maxEmbedTitle)
+		embed.Description = truncate(messages[i].Description, maxEmbedDescription)
+		embed.Footer = footer
+		embed.Timestamp = timestamp
+		embeds = append(embeds, embed)
+	}
+
+	batches := batchSlice(embeds, n.batchSize)
+	ballBatches := batchSlice(approvedBalls, n.batchSize)
+	roles := n.channelRoles(ctx)
+
+	send := func(channelID string, batch int, opts ...discordgo.RequestOption) error {
+		content, allowed := roleMentions(roles(channelID), ballBatches[batch])
+		msg := &discordgo.MessageSend{Content: content, Embeds: batches[batch], AllowedMentions: allowed}
+		if _, err := n.dg.ChannelMessageSendComplex(channelID, msg, opts...); err != nil {
+			return fmt.Errorf("sending embeds: %w", err)
+		}
+		return nil
+	}
+
+	report := n.deliver(ctx, n.channels, len(batches), send)
+	if len(n.threads) > 0 {
+		threads := n.deliverThreads(ctx, approvedBalls, embeds, roles)
+		report.Channels = append(report.Channels, threads.Channels...)
+	}
+	report.Users = n.deliverDirect(ctx, approvedBalls, embeds)
+
+	return report, nil
+}
+
+// ballEmbed returns an embed describing b in its brand's style, linking to the ball on the brand's site. The USBC list
+// only has the name, approval date and image of a ball, so there are no specs such as the coverstock or RG to show.
+func ballEmbed(b Ball, style BrandStyle) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Type:  discordgo.EmbedTypeRich,
+		Title: fmt.Sprintf("%s %s", b.Brand, b.Name),
+		URL:   style.BallURL(b),
+		Color: style.Color,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Approved", Value: b.ApprovalDate.Format("January 2, 2006"), Inline: true},
+			{Name: "Manufacturer", Value: string(b.Brand.Manufacturer()), Inline: true},
+		},
+	}
+	if b.ImageURL != nil {
+		embed.Image = &discordgo.MessageEmbedImage{URL: b.ImageURL.String()}
+	}
+	if style.LogoURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: style.LogoURL}
+	}
+
+	return embed
+}
+
+// runFooter returns the footer and timestamp of embeds announcing the balls found by the run in ctx, or now when
+// there isn't one.
+func runFooter(ctx context.Context) (*discordgo.MessageEmbedFooter, string) {
+	at := time.Now()
+	if run, ok := RunFromContext(ctx); ok && !run.StartedAt.IsZero() {
+		at = run.StartedAt
+	}
+
+	return &discordgo.MessageEmbedFooter{Text: "USBC approved ball list"}, at.Format(time.RFC3339)
+}
+
+// Limits discord places on messages.
+const (
+	maxEmbedsPerMessage = 10
+	maxEmbedTitle       = 256
+	maxEmbedDescription = 4096
+)
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+
+	return s
+}
+
+// NotifyDigest sends digest to the configured channels as a summary with an embed per brand.
+func (n *DiscordNotifier) NotifyDigest(ctx context.Context, digest Digest) error {
+	if len(digest.Balls) == 0 {
+		return nil
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for _, m := range digest.Groups {
+		for _, g := range m.Brands {
+			messages, err := n.renderer.RenderBalls(ctx, g.Balls)
+			if err != nil {
+				return err
 			}
+			embeds = append(embeds, brandDigestEmbed(m.Manufacturer, g, messages, n.styles.Style(g.Brand)))
+		}
+	}
+
+	content := fmt.Sprintf("**%s**: %s", digestTitle(digest), ballCount(len(digest.Balls)))
+	batches := batchSlice(embeds, maxEmbedsPerMessage)
+	roles := n.channelRoles(ctx)
+
+	send := func(channelID string, batch int, opts ...discordgo.RequestOption) error {
+		msg := &discordgo.MessageSend{
+			Embeds:          batches[batch],
+			AllowedMentions: &discordgo.MessageAllowedMentions{Parse: []discordgo.AllowedMentionType{}},
 		}
+		if batch == 0 {
+			mentions, allowed := roleMentions(roles(channelID), digest.Balls)
+			msg.Content = strings.TrimSpace(content + "\n" + mentions)
+			msg.AllowedMentions = allowed
+		}
+		if _, err := n.dg.ChannelMessageSendComplex(channelID, msg, opts...); err != nil {
+			return fmt.Errorf("sending digest: %w", err)
+		}
+		return nil
+	}
+
+	report := n.deliver(ctx, n.channels, len(batches), send)
+
+	return report.Err()
+}
+
+func brandDigestEmbed(m Manufacturer, g BrandGroup, messages []Message, style BrandStyle) *discordgo.MessageEmbed {
+	lines := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		lines = append(lines, msg.DigestLine)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s (%d)", g.Brand, len(g.Balls)),
+		Description: truncate(strings.Join(lines, "\n"), maxEmbedDescription),
+		URL:         style.Website,
+		Color:       style.Color,
+	}
+	switch img := g.Balls[0].ImageURL; {
+	case style.LogoURL != "":
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: style.LogoURL}
+	case img != nil && img.String() != "":
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: img.String()}
+	}
+	if string(m) != string(g.Brand) {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: string(m)}
+	}
+
+	return embed
+}
+
+// digestTitle returns the heading of digest, e.g. "Daily digest".
+func digestTitle(digest Digest) string {
+	if digest.Name == "" {
+		return "Digest"
+	}
+
+	return strings.ToUpper(digest.Name[:1]) + digest.Name[1:] + " digest"
+}
+
+func ballCount(n int) string {
+	if n == 1 {
+		return "1 newly approved ball"
+	}
+
+	return fmt.Sprintf("%d newly approved balls", n)
+}
+
+// HealthCheck verifies the bot token by retrieving the bot's own user.
+func (n *DiscordNotifier) HealthCheck(ctx context.Context) error {
+	if _, err := n.dg.User("@me", discordgo.WithContext(ctx)); err != nil {
+		return fmt.Errorf("getting bot user: %w", err)
 	}
 
 	return nil
@@ -69,17 +375,55 @@ func batchSlice[T any](sl []T, batchSize int) [][]T {
 }
 
 // LocalNotifier implements the Notifier interface and prints out newly approved balls to stdout.
-type LocalNotifier struct{}
+type LocalNotifier struct {
+	// Renderer renders the printed notifications, DefaultMessageTemplates are used when nil.
+	Renderer *Renderer
+}
 
-func (n LocalNotifier) Notify(_ context.Context, approvedBalls []Ball) error {
+func (n LocalNotifier) renderer() *Renderer {
+	if n.Renderer == nil {
+		return defaultRenderer
+	}
+
+	return n.Renderer
+}
+
+func (n LocalNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
 	if len(approvedBalls) == 0 {
 		fmt.Println("NOTIFIER: no approved balls to notify")
 		return nil
 	}
 
+	messages, err := n.renderer().RenderBalls(ctx, approvedBalls)
+	if err != nil {
+		return err
+	}
+
 	fmt.Println("NOTIFIER:")
-	for _, ball := range approvedBalls {
-		fmt.Printf("NEWLY APPROVED BALL: %s %s", ball.Brand, ball.Name)
+	for _, msg := range messages {
+		fmt.Printf("NEWLY APPROVED BALL: %s\n", msg.Title)
+		if msg.Description != "" {
+			fmt.Printf("  %s\n", strings.ReplaceAll(msg.Description, "\n", "\n  "))
+		}
+	}
+
+	return nil
+}
+
+func (n LocalNotifier) NotifyDigest(ctx context.Context, digest Digest) error {
+	fmt.Printf("NOTIFIER: %s, %s\n", digestTitle(digest), ballCount(len(digest.Balls)))
+	for _, m := range digest.Groups {
+		for _, g := range m.Brands {
+			messages, err := n.renderer().RenderBalls(ctx, g.Balls)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("  %s (%d)\n", g.Brand, len(g.Balls))
+			for _, msg := range messages {
+				fmt.Printf("    %s\n", msg.DigestLine)
+			}
+		}
 	}
 
 	return nil
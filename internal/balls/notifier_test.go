@@ -1,8 +1,13 @@
 package balls
 
 import (
+	"context"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -24,3 +29,54 @@ func Test_batchSlice(t *testing.T) {
 		}
 	})
 }
+
+func Test_ballEmbed(t *testing.T) {
+	approved := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	b := Ball{
+		Brand:        RotoGrip,
+		Name:         "Idol",
+		ApprovalDate: approved,
+		ImageURL:     &url.URL{Scheme: "https", Host: "some-url", Path: "/idol.png"},
+	}
+
+	styles := BrandStyles{RotoGrip: {LogoURL: "https://some-url/logo.png"}}
+	got := ballEmbed(b, styles.Style(RotoGrip))
+
+	want := &discordgo.MessageEmbed{
+		Type:  discordgo.EmbedTypeRich,
+		Title: "Roto Grip Idol",
+		URL:   "https://duckduckgo.com/?q=%5Csite%3Awww.rotogrip.com+Idol",
+		Color: 0x2B2B2B,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Approved", Value: "May 1, 2024", Inline: true},
+			{Name: "Manufacturer", Value: "Storm Products", Inline: true},
+		},
+		Image:     &discordgo.MessageEmbedImage{URL: "https://some-url/idol.png"},
+		Thumbnail: &discordgo.MessageEmbedThumbnail{URL: "https://some-url/logo.png"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatalf("(-got, +want):\n%s", diff)
+	}
+
+	builtIn := ballEmbed(b, BrandStyles{}.Style(RotoGrip))
+	if builtIn.Thumbnail == nil || !strings.Contains(builtIn.Thumbnail.URL, "rotogrip.com") {
+		t.Fatalf("expected the registry's logo got %+v", builtIn.Thumbnail)
+	}
+
+	unknown := ballEmbed(Ball{Brand: Brand("Unknown"), Name: "Mystery", ApprovalDate: approved}, BrandStyles{}.Style("Unknown"))
+	if unknown.Color != 0 || unknown.URL != "" || unknown.Image != nil || unknown.Thumbnail != nil {
+		t.Fatalf("expected unstyled embed without an image got %+v", unknown)
+	}
+}
+
+func Test_runFooter(t *testing.T) {
+	startedAt := time.Date(2024, time.May, 1, 10, 30, 0, 0, time.UTC)
+
+	footer, timestamp := runFooter(ContextWithRun(context.Background(), Run{ID: "1", StartedAt: startedAt}))
+	if footer == nil || footer.Text == "" {
+		t.Fatal("expected footer text")
+	}
+	if timestamp != "2024-05-01T10:30:00Z" {
+		t.Fatalf("expected run start as timestamp got %s", timestamp)
+	}
+}
@@ -0,0 +1,147 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const defaultNtfyServer = "https://ntfy.sh"
+
+// NtfyNotifier implements the Notifier interface and publishes a push notification per newly approved ball to an
+// ntfy topic.
+type NtfyNotifier struct {
+	rendering
+
+	client   *http.Client
+	server   string
+	topic    string
+	token    string
+	priority int
+}
+
+// NtfyOption configures an NtfyNotifier.
+type NtfyOption interface {
+	applyNtfy(*NtfyNotifier)
+}
+
+func (o NotifierOption) applyNtfy(n *NtfyNotifier) { o.applyRendering(&n.rendering) }
+
+type ntfyServerOption string
+
+func (o ntfyServerOption) applyNtfy(n *NtfyNotifier) {
+	n.server = strings.TrimSuffix(string(o), "/")
+}
+
+// WithNtfyServer sets the url of the ntfy server, https://ntfy.sh by default.
+func WithNtfyServer(server string) NtfyOption {
+	return ntfyServerOption(server)
+}
+
+type ntfyTokenOption string
+
+func (o ntfyTokenOption) applyNtfy(n *NtfyNotifier) {
+	n.token = string(o)
+}
+
+// WithNtfyToken sets the access token used to publish to protected topics.
+func WithNtfyToken(token string) NtfyOption {
+	return ntfyTokenOption(token)
+}
+
+type ntfyPriorityOption int
+
+func (o ntfyPriorityOption) applyNtfy(n *NtfyNotifier) {
+	n.priority = int(o)
+}
+
+// WithNtfyPriority sets the priority of notifications, from 1 (min) to 5 (max). The server's default of 3 is used
+// when it's not set.
+func WithNtfyPriority(priority int) NtfyOption {
+	return ntfyPriorityOption(priority)
+}
+
+func NewNtfyNotifier(client *http.Client, topic string, opts ...NtfyOption) *NtfyNotifier {
+	n := &NtfyNotifier{client: client, server: defaultNtfyServer, topic: topic}
+	for _, opt := range opts {
+		opt.applyNtfy(n)
+	}
+	if n.client == nil {
+		n.client = http.DefaultClient
+	}
+	if n.renderer == nil {
+		n.renderer = defaultRenderer
+	}
+
+	return n
+}
+
+// Notify publishes a notification per ball, tagged with its brand and attaching its image. Every ball is published
+// even if some fail.
+func (n *NtfyNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	if len(approvedBalls) == 0 {
+		return nil
+	}
+
+	messages, err := n.renderer.RenderBalls(ctx, approvedBalls)
+	if err != nil {
+		return err
+	}
+
+	var header http.Header
+	if n.token != "" {
+		header = http.Header{"Authorization": []string{"Bearer " + n.token}}
+	}
+
+	var errs []error
+	for i, b := range approvedBalls {
+		msg := ntfyMessage{
+			Topic:    n.topic,
+			Title:    messages[i].Title,
+			Message:  messages[i].Description,
+			Tags:     ntfyTags(b),
+			Priority: n.priority,
+			Click:    n.styles.Style(b.Brand).Website,
+		}
+		if msg.Message == "" {
+			msg.Message = ballCount(1)
+		}
+		if b.ImageURL != nil {
+			msg.Attach = b.ImageURL.String()
+		}
+
+		// Messages are published to the server's root so the topic doesn't have to be escaped into the url.
+		if err := postJSON(ctx, n.client, n.server, header, msg); err != nil {
+			errs = append(errs, fmt.Errorf("publishing %s: %w", msg.Title, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ntfyTags tags a notification with a bowling emoji, the ball's brand and its manufacturer when it differs, e.g.
+// bowling, roto_grip, storm_products.
+func ntfyTags(b Ball) []string {
+	tag := func(s string) string {
+		return strings.ReplaceAll(strings.ToLower(s), " ", "_")
+	}
+
+	tags := []string{"bowling", tag(string(b.Brand))}
+	if m := tag(string(b.Brand.Manufacturer())); m != "" && m != tags[1] {
+		tags = append(tags, m)
+	}
+
+	return tags
+}
+
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title,omitempty"`
+	Message  string   `json:"message"`
+	Tags     []string `json:"tags,omitempty"`
+	Priority int      `json:"priority,omitempty"`
+	Click    string   `json:"click,omitempty"`
+	Attach   string   `json:"attach,omitempty"`
+}
@@ -0,0 +1,55 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNtfyNotifier_Notify(t *testing.T) {
+	fake := &fakeWebhook{rateLimit: true}
+	n := NewNtfyNotifier(nil, "approved-balls",
+		WithNtfyServer(newFakeWebhook(t, fake)+"/"),
+		WithNtfyToken("tk_secret"),
+		WithNtfyPriority(4),
+	)
+
+	if err := n.Notify(context.Background(), webhookTestBalls()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.payloads) != 3 {
+		t.Fatalf("expected a notification per ball got %d", len(fake.payloads))
+	}
+	if fake.header.Get("Authorization") != "Bearer tk_secret" {
+		t.Errorf("expected the access token to be sent got %q", fake.header.Get("Authorization"))
+	}
+
+	var phaze, idol ntfyMessage
+	if err := json.Unmarshal(fake.payloads[0], &phaze); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(fake.payloads[2], &idol); err != nil {
+		t.Fatal(err)
+	}
+
+	want := ntfyMessage{
+		Topic:    "approved-balls",
+		Title:    "Storm Phaze II",
+		Tags:     []string{"bowling", "storm", "storm_products"},
+		Priority: 4,
+		Click:    "https://www.stormbowling.com",
+		Attach:   "https://some-url/phaze.png",
+	}
+	if phaze.Topic != want.Topic || phaze.Title != want.Title || phaze.Priority != want.Priority ||
+		phaze.Click != want.Click || phaze.Attach != want.Attach || strings.Join(phaze.Tags, ",") != strings.Join(want.Tags, ",") {
+		t.Errorf("got %+v, want %+v", phaze, want)
+	}
+	if phaze.Message == "" {
+		t.Error("expected a message body")
+	}
+	if idol.Attach != "" || strings.Join(idol.Tags, ",") != "bowling,roto_grip,storm_products" {
+		t.Errorf("unexpected notification %+v", idol)
+	}
+}
@@ -0,0 +1,113 @@
+package balls
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRateLimitRetries is how many times a rate limited request is retried before giving up on it.
+	maxRateLimitRetries = 5
+	// maxRateLimitWait caps how long a single rate limit is waited out.
+	maxRateLimitWait = time.Minute
+)
+
+// retryRateLimits calls try until it isn't rate limited or the retries run out, returning its last error. try is
+// passed the attempt, counting from 0, and reports whether it was rate limited and how long to wait before the next.
+func retryRateLimits(ctx context.Context, try func(attempt int) (bool, time.Duration, error)) error {
+	for attempt := 0; ; attempt++ {
+		limited, wait, err := try(attempt)
+		if !limited || attempt == maxRateLimitRetries {
+			return err
+		}
+
+		timer := time.NewTimer(min(max(wait, 0), maxRateLimitWait))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// errRateLimited is the error of a rate limited request, it's only returned joined with the context's error.
+var errRateLimited = errors.New("rate limited")
+
+// apiResponse is a response read in full by doWithRetry.
+type apiResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// OK reports whether the response has a 2xx status.
+func (r apiResponse) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// doWithRetry sends the request built by newRequest, waiting out and retrying rate limits. Rate limits are waited out
+// for as long as rateLimitWait reads from the response, for APIs that say so in the body, or its Retry-After when
+// rateLimitWait is nil or returns 0. The last response is returned once it's not rate limited or the retries run out,
+// callers handle its status.
+func doWithRetry(
+	ctx context.Context,
+	client *http.Client,
+	newRequest func() (*http.Request, error),
+	rateLimitWait func(resp apiResponse) time.Duration,
+) (apiResponse, error) {
+	var result apiResponse
+	err := retryRateLimits(ctx, func(attempt int) (bool, time.Duration, error) {
+		req, err := newRequest()
+		if err != nil {
+			return false, 0, fmt.Errorf("creating request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return false, 0, fmt.Errorf("sending request: %w", err)
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+
+		result = apiResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: bytes.TrimSpace(body)}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return false, 0, nil
+		}
+
+		wait := retryAfter(resp.Header, attempt)
+		if rateLimitWait != nil {
+			if w := rateLimitWait(result); w > 0 {
+				wait = w
+			}
+		}
+		return true, wait, errRateLimited
+	})
+	if errors.Is(err, errRateLimited) && ctx.Err() == nil {
+		// The retries ran out, the caller handles the rate limited response like any other.
+		return result, nil
+	}
+
+	return result, err
+}
+
+// retryAfter returns how long to wait before retrying a rate limited request, from its Retry-After header or
+// backing off exponentially without one.
+func retryAfter(h http.Header, attempt int) time.Duration {
+	wait := time.Second << attempt
+	if v := h.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			wait = time.Duration(seconds * float64(time.Second))
+		} else if at, err := http.ParseTime(v); err == nil {
+			wait = time.Until(at)
+		}
+	}
+
+	return min(max(wait, 0), maxRateLimitWait)
+}
@@ -0,0 +1,133 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule determines when scheduled runs happen.
+type Schedule interface {
+	// Next returns the next time a run should happen after t.
+	Next(t time.Time) time.Time
+}
+
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// ParseSchedule parses either an interval duration, e.g. "1h", or a standard 5 field cron expression,
+// e.g. "0 * * * *".
+func ParseSchedule(spec string) (Schedule, error) {
+	if d, err := time.ParseDuration(spec); err == nil {
+		if d <= 0 {
+			return nil, fmt.Errorf("interval must be positive: %s", spec)
+		}
+		return intervalSchedule(d), nil
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cron expression: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// Scheduler checks for newly approved balls on a schedule from within the process, for running without an
+// external cron hitting the cron endpoint.
+type Scheduler struct {
+	logger   *slog.Logger
+	svc      Service
+	store    Store
+	schedule Schedule
+	jitter   time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewScheduler returns a new scheduler. Each run is delayed by a random duration up to jitter so that multiple
+// instances don't all contend for the run lease at once.
+func NewScheduler(logger *slog.Logger, svc Service, store Store, schedule Schedule, jitter time.Duration) *Scheduler {
+	return &Scheduler{
+		logger:   logger,
+		svc:      svc,
+		store:    store,
+		schedule: schedule,
+		jitter:   jitter,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start starts triggering runs in the background until Stop is called or ctx is cancelled. Runs are passed ctx.
+func (s *Scheduler) Start(ctx context.Context) {
+	go s.run(ctx)
+}
+
+// Stop stops triggering runs and waits for an in flight run to finish. It's safe to call more than once.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	for {
+		next := s.schedule.Next(time.Now())
+		wait := time.Until(next)
+		if s.jitter > 0 {
+			wait += rand.N(s.jitter)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+
+		case <-ctx.Done():
+			timer.Stop()
+			return
+
+		case <-timer.C:
+		}
+
+		s.trigger(ctx, next)
+	}
+}
+
+// trigger runs a check unless one has already succeeded since the scheduled time, e.g. from another instance or
+// the cron endpoint.
+func (s *Scheduler) trigger(ctx context.Context, scheduled time.Time) {
+	last, err := s.store.GetLastSuccessfulRun(ctx)
+	switch {
+	case err == nil && !last.StartedAt.Before(scheduled):
+		s.logger.InfoContext(ctx, "skipping scheduled run, a run has already succeeded since it was scheduled",
+			slog.String("run_id", last.ID))
+		return
+
+	case err != nil && !errors.Is(err, ErrNotFound):
+		s.logger.WarnContext(ctx, "error getting last successful run", slog.Any("error", err))
+	}
+
+	err = s.svc.CheckForNewlyApprovedBalls(ctx)
+	switch {
+	case errors.Is(err, ErrRunInProgress):
+		s.logger.InfoContext(ctx, "skipping scheduled run, another instance holds the run lease")
+
+	case err != nil:
+		s.logger.ErrorContext(ctx, "error checking for newly approved balls", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,141 @@
+package balls
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type serviceFunc func(ctx context.Context) error
+
+func (f serviceFunc) CheckForNewlyApprovedBalls(ctx context.Context) error {
+	return f(ctx)
+}
+
+func (f serviceFunc) SearchBalls(context.Context, string, int) ([]SearchResult, error) {
+	return nil, nil
+}
+
+func (f serviceFunc) ListBalls(context.Context, BallFilter) ([]Ball, error) {
+	return nil, nil
+}
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2024, time.May, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "interval",
+			spec: "15m",
+			want: now.Add(15 * time.Minute),
+		},
+		{
+			name: "cron expression",
+			spec: "0 * * * *",
+			want: time.Date(2024, time.May, 1, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "negative interval",
+			spec:    "-1h",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			spec:    "every hour",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSchedule(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if next := got.Next(now); !next.Equal(tt.want) {
+				t.Fatalf("Next() = %s, want %s", next, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("triggers runs", func(t *testing.T) {
+		runs := make(chan struct{}, 10)
+		svc := serviceFunc(func(ctx context.Context) error {
+			select {
+			case runs <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		store := &StoreMock{
+			GetLastSuccessfulRunFunc: func(ctx context.Context) (Run, error) {
+				return Run{}, ErrNotFound
+			},
+		}
+
+		s := NewScheduler(slog.Default(), svc, store, intervalSchedule(5*time.Millisecond), time.Millisecond)
+		s.Start(context.Background())
+
+		for i := 0; i < 2; i++ {
+			select {
+			case <-runs:
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for scheduled run")
+			}
+		}
+
+		s.Stop()
+		// Stopping again, e.g. from a deferred cleanup, is a no-op.
+		s.Stop()
+	})
+
+	t.Run("skips when a run already succeeded", func(t *testing.T) {
+		runs := make(chan struct{}, 10)
+		svc := serviceFunc(func(ctx context.Context) error {
+			select {
+			case runs <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		checks := make(chan struct{}, 10)
+		store := &StoreMock{
+			GetLastSuccessfulRunFunc: func(ctx context.Context) (Run, error) {
+				select {
+				case checks <- struct{}{}:
+				default:
+				}
+				return Run{StartedAt: time.Now().Add(time.Hour)}, nil
+			},
+		}
+
+		s := NewScheduler(slog.Default(), svc, store, intervalSchedule(5*time.Millisecond), 0)
+		s.Start(context.Background())
+
+		for i := 0; i < 2; i++ {
+			select {
+			case <-checks:
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for scheduled run")
+			}
+		}
+
+		s.Stop()
+
+		if len(runs) != 0 {
+			t.Fatalf("expected no runs got %d", len(runs))
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package balls
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SearchResult is a ball matching a search query along with how similar its name is to the query, from 0 to 1.
+type SearchResult struct {
+	Ball  Ball
+	Score float64
+}
+
+// ErrEmptyQuery is returned when searching with a query that has nothing to search for once normalized.
+var ErrEmptyQuery = errors.New("empty search query")
+
+const (
+	// minSearchScore is the min similarity of search results, the same default threshold as postgres' pg_trgm.
+	minSearchScore     = 0.3
+	defaultSearchLimit = 10
+	maxSearchLimit     = 25
+)
+
+// NormalizeName folds a ball name so that names differing only in case, punctuation or the use of roman numerals
+// compare equal, e.g. "Phaze II" and "phaze-2" both normalize to "phaze 2". Trademark symbols are dropped and accents
+// folded.
+func NormalizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\'' || r == '’':
+			return -1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		default:
+			return ' '
+		}
+	}, foldName(trademarks.Replace(name)))
+
+	words := strings.Fields(name)
+	for i, w := range words {
+		if n, ok := parseRomanNumeral(w); ok {
+			words[i] = strconv.Itoa(n)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+var romanNumerals = []struct {
+	value  int
+	symbol string
+}{
+	{10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+}
+
+// parseRomanNumeral parses the lower case roman numerals from 1 to 39, the range found in ball names, rejecting
+// anything that isn't written canonically so that ordinary words aren't mistaken for numbers.
+func parseRomanNumeral(s string) (int, bool) {
+	if s == "" || strings.Trim(s, "ivx") != "" {
+		return 0, false
+	}
+
+	n, rest := 0, s
+	for _, numeral := range romanNumerals {
+		for strings.HasPrefix(rest, numeral.symbol) {
+			n += numeral.value
+			rest = rest[len(numeral.symbol):]
+		}
+	}
+	if rest != "" || n > 39 || formatRomanNumeral(n) != s {
+		return 0, false
+	}
+
+	return n, true
+}
+
+func formatRomanNumeral(n int) string {
+	var sb strings.Builder
+	for _, numeral := range romanNumerals {
+		for n >= numeral.value {
+			sb.WriteString(numeral.symbol)
+			n -= numeral.value
+		}
+	}
+
+	return sb.String()
+}
+
+// nameTrigrams returns the sorted distinct trigrams of a normalized name. Like pg_trgm each word is padded with two
+// spaces before and one after, so short words and word beginnings carry more weight.
+func nameTrigrams(normalized string) []string {
+	seen := make(map[string]struct{})
+	for _, w := range strings.Fields(normalized) {
+		padded := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			seen[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+
+	trigrams := make([]string, 0, len(seen))
+	for t := range seen {
+		trigrams = append(trigrams, t)
+	}
+	sort.Strings(trigrams)
+
+	return trigrams
+}
+
+// trigramSimilarity is the number of trigrams shared by two names divided by the number of distinct trigrams in
+// either.
+func trigramSimilarity(shared, a, b int) float64 {
+	if a+b-shared == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(a+b-shared)
+}
+
+// countSharedTrigrams counts the trigrams in both of the sorted slices a and b.
+func countSharedTrigrams(a, b []string) int {
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return shared
+}
+
+// sortSearchResults orders results from most to least similar, breaking ties with the most recently approved.
+func sortSearchResults(results []SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Ball.ApprovalDate.After(results[j].Ball.ApprovalDate)
+	})
+}
@@ -0,0 +1,140 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/actatum/approved-ball-list/internal/sqlite"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Phaze II", want: "phaze 2"},
+		{name: "phaze-2", want: "phaze 2"},
+		{name: "PHAZE  2", want: "phaze 2"},
+		{name: "Black Widow 3.0", want: "black widow 3 0"},
+		{name: "Turbo X", want: "turbo 10"},
+		{name: "Hy-Road XIV", want: "hy road 14"},
+		{name: "Nova's Edge", want: "novas edge"},
+		{name: "Mix IIII", want: "mix iiii"},
+		{name: "Vivid", want: "vivid"},
+		{name: " !! ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.name); got != tt.want {
+				t.Fatalf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_handleSearchBalls(t *testing.T) {
+	store := NewMemoryStore()
+	err := store.AddBalls(context.Background(), []Ball{
+		{Brand: Storm, Name: "Phaze II", ImageURL: &url.URL{Scheme: "https", Host: "some-url"}, ApprovalDate: time.Now()},
+		{Brand: Storm, Name: "Phaze III", ImageURL: &url.URL{Scheme: "https", Host: "some-url"}, ApprovalDate: time.Now()},
+		{Brand: Motiv, Name: "Venom Shock", ImageURL: &url.URL{Scheme: "https", Host: "some-url"}, ApprovalDate: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHTTPHandler(slog.Default(), NewService(slog.Default(), store, nil, nil), "test")
+
+	t.Run("ranked results", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/balls/search?q=phaze+2", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d got %d", http.StatusOK, rec.Code)
+		}
+
+		var body struct {
+			Results []searchResultResponse `json:"results"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(body.Results) != 2 {
+			t.Fatalf("expected 2 results got %d", len(body.Results))
+		}
+		if body.Results[0].Name != "Phaze II" || body.Results[0].Score != 1 {
+			t.Fatalf("expected exact match first got %+v", body.Results[0])
+		}
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/balls/search?q=+-+", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("discord search command", func(t *testing.T) {
+		c := NewDiscordCommands(slog.Default(), NewService(slog.Default(), store, nil, nil))
+
+		resp := c.search(context.Background(), "venom shok")
+		if len(resp.Data.Embeds) != 1 || resp.Data.Embeds[0].Title != "Motiv Venom Shock" {
+			t.Fatalf("expected venom shock embed got %+v", resp.Data)
+		}
+
+		resp = c.search(context.Background(), "nothing like it")
+		if resp.Data.Flags != discordgo.MessageFlagsEphemeral || len(resp.Data.Embeds) != 0 {
+			t.Fatalf("expected ephemeral no results message got %+v", resp.Data)
+		}
+	})
+}
+
+func TestSQLiteStore_ReindexSearch(t *testing.T) {
+	db, err := sqlite.NewDB("sqlite://" + filepath.Join(t.TempDir(), "abl.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+	ctx := context.Background()
+
+	// Balls added before search existed aren't in the index.
+	_, err = db.ExecContext(ctx, `INSERT INTO balls (brand, name, image_url, approved_at) VALUES (?, ?, ?, ?)`,
+		Storm, "Phaze II", "https://some-url", formatSQLiteTime(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSQLiteStore(db)
+	if got, _ := s.SearchBalls(ctx, "phaze 2", 10); len(got) != 0 {
+		t.Fatalf("expected unindexed ball not to be found got %d results", len(got))
+	}
+
+	n, err := s.ReindexSearch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 ball indexed got %d", n)
+	}
+
+	got, err := s.SearchBalls(ctx, "phaze 2", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Ball.Name != "Phaze II" {
+		t.Fatalf("expected reindexed ball to be found got %+v", got)
+	}
+}
@@ -0,0 +1,95 @@
+package balls
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSocialImages is the number of images Mastodon and Bluesky allow per post.
+const maxSocialImages = 4
+
+// socialPost is one post of a thread announcing approved balls.
+type socialPost struct {
+	Text string
+	// Balls are the indexes of the balls whose images are attached to the post.
+	Balls []int
+}
+
+// composeThread splits titles into a thread of posts within limit characters, each listing at most maxImages balls
+// so every ball's image can be attached to the post mentioning it. Posts are numbered when there's more than one.
+func composeThread(heading string, titles []string, limit, maxImages int) []socialPost {
+	const bullet = "• "
+	// Room is left for numbering posts, e.g. " (2/3)".
+	budget := limit - len(" (99/99)")
+
+	var (
+		posts   []socialPost
+		current = socialPost{Text: truncate(heading, budget)}
+	)
+	for i, title := range titles {
+		length := utf8.RuneCountInString(current.Text) + 1 + utf8.RuneCountInString(bullet+title)
+		if len(current.Balls) == maxImages || (len(current.Balls) > 0 && length > budget) {
+			posts = append(posts, current)
+			current = socialPost{}
+		}
+		if current.Text != "" {
+			current.Text += "\n"
+		}
+		remaining := budget - utf8.RuneCountInString(current.Text+bullet)
+		current.Text += bullet + truncate(title, remaining)
+		current.Balls = append(current.Balls, i)
+	}
+	posts = append(posts, current)
+
+	if len(posts) > 1 {
+		for i := range posts {
+			posts[i].Text += fmt.Sprintf(" (%d/%d)", i+1, len(posts))
+		}
+	}
+
+	return posts
+}
+
+// socialAltText describes a ball's image for screen readers.
+func socialAltText(b Ball) string {
+	return fmt.Sprintf("Product photo of the %s %s bowling ball", b.Brand, b.Name)
+}
+
+// downloadImage downloads the image at u, returning its content and media type.
+func downloadImage(ctx context.Context, client *http.Client, u *url.URL, maxSize int) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("downloading image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("downloading image: unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("downloading image: %w", err)
+	}
+	if len(data) > maxSize {
+		return nil, "", fmt.Errorf("image is larger than %d bytes", maxSize)
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, "", fmt.Errorf("unexpected content type %s", mimeType)
+	}
+
+	return data, mimeType, nil
+}
@@ -0,0 +1,79 @@
+package balls
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// newImageServer serves stand-in PNG ball images at /images/{name}, missing.png isn't found and huge.png is larger
+// than any platform accepts.
+func newImageServer(t *testing.T) func(name string) *url.URL {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /images/{name}", func(w http.ResponseWriter, r *http.Request) {
+		switch name := r.PathValue("name"); name {
+		case "missing.png":
+			http.NotFound(w, r)
+		case "huge.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG\r\n\x1a\n" + strings.Repeat("0", 20<<20)))
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG\r\n\x1a\n" + name))
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return func(name string) *url.URL {
+		u, err := url.Parse(srv.URL + "/images/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+}
+
+func TestComposeThread(t *testing.T) {
+	t.Run("fits in one post", func(t *testing.T) {
+		got := composeThread("2 newly approved balls", []string{"Storm Phaze II", "Motiv Venom Shock"}, 300, 4)
+
+		if len(got) != 1 || got[0].Text != "2 newly approved balls\n• Storm Phaze II\n• Motiv Venom Shock" {
+			t.Fatalf("unexpected thread %+v", got)
+		}
+	})
+
+	t.Run("splits by images", func(t *testing.T) {
+		got := composeThread("5 newly approved balls", []string{"a", "b", "c", "d", "e"}, 300, 4)
+
+		if len(got) != 2 || len(got[0].Balls) != 4 || got[1].Text != "• e (2/2)" || got[1].Balls[0] != 4 {
+			t.Fatalf("unexpected thread %+v", got)
+		}
+	})
+
+	t.Run("splits by length", func(t *testing.T) {
+		titles := []string{strings.Repeat("é", 200), strings.Repeat("b", 200), strings.Repeat("c", 400)}
+		got := composeThread("3 newly approved balls", titles, 300, 4)
+
+		if len(got) != 3 {
+			t.Fatalf("expected 3 posts got %d", len(got))
+		}
+		for i, post := range got {
+			if n := utf8.RuneCountInString(post.Text); n > 300 {
+				t.Errorf("post %d is %d characters", i, n)
+			}
+			if len(post.Balls) != 1 || post.Balls[0] != i {
+				t.Errorf("expected post %d to mention ball %d got %v", i, i, post.Balls)
+			}
+		}
+		if !strings.HasSuffix(got[2].Text, "… (3/3)") {
+			t.Errorf("expected the long title to be truncated got %q", got[2].Text)
+		}
+	})
+}
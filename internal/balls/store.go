@@ -2,11 +2,14 @@ package balls
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,6 +19,76 @@ import (
 type Store interface {
 	AddBalls(ctx context.Context, balls []Ball) error
 	GetAllBalls(ctx context.Context, filter BallFilter) ([]Ball, error)
+	// GetRecentBalls returns up to limit balls matching filter, most recent approval first.
+	GetRecentBalls(ctx context.Context, filter BallFilter, limit int) ([]Ball, error)
+	// GetPendingBalls returns the stored balls that haven't been notified of yet, oldest approval first. Balls whose
+	// notification has already failed maxAttempts times are given up on and left out.
+	GetPendingBalls(ctx context.Context, maxAttempts int) ([]Ball, error)
+	// MarkBallsNotified records that the balls with ids were notified of at notifiedAt.
+	MarkBallsNotified(ctx context.Context, ids []int, notifiedAt time.Time) error
+	// AddNotifyAttempt records a failed attempt at notifying of the balls with ids that are still pending.
+	AddNotifyAttempt(ctx context.Context, ids []int) error
+	// AddRun records a finished run in the run history.
+	AddRun(ctx context.Context, run Run) error
+	// GetLastSuccessfulRun returns the most recently finished run without an error or ErrNotFound if there are none.
+	GetLastSuccessfulRun(ctx context.Context) (Run, error)
+	// AcquireRunLease attempts to take the lease allowing a single run at a time across all instances. It reports
+	// whether the lease was acquired, the lease expires after ttl if it's never released.
+	AcquireRunLease(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+	// ReleaseRunLease releases the lease if it's held by holder.
+	ReleaseRunLease(ctx context.Context, holder string) error
+	// SearchBalls returns up to limit balls with names similar to query, most similar first.
+	SearchBalls(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	// ReindexSearch adds any balls missing from the search index, returning how many were indexed. Balls are indexed
+	// as they're added, so this is only needed for balls added before search existed.
+	ReindexSearch(ctx context.Context) (int, error)
+	// AddBallAlias adds or replaces a manual merge of one ball into another.
+	AddBallAlias(ctx context.Context, alias BallAlias) error
+	// GetBallAliases returns every manual merge.
+	GetBallAliases(ctx context.Context) ([]BallAlias, error)
+	// AddDigestBalls adds balls to the named digest's pending balls, ignoring any it already has.
+	AddDigestBalls(ctx context.Context, digest string, balls []Ball) error
+	// TakeDigestBalls removes and returns the named digest's pending balls, oldest approval first.
+	TakeDigestBalls(ctx context.Context, digest string) ([]Ball, error)
+	// DisableChannel records that a channel can't be delivered to so it's skipped by later deliveries.
+	DisableChannel(ctx context.Context, channel DisabledChannel) error
+	// EnableChannel removes a channel from the disabled channels.
+	EnableChannel(ctx context.Context, channelID string) error
+	// GetDisabledChannels returns every disabled channel.
+	GetDisabledChannels(ctx context.Context) ([]DisabledChannel, error)
+	// AddBrandRole adds role unless the guild already has a role for the same brand or manufacturer, returning the
+	// guild's role either way.
+	AddBrandRole(ctx context.Context, role BrandRole) (BrandRole, error)
+	// RemoveBrandRole removes role if it's still the guild's role for its brand or manufacturer.
+	RemoveBrandRole(ctx context.Context, role BrandRole) error
+	// GetBrandRoles returns the roles of a guild.
+	GetBrandRoles(ctx context.Context, guildID string) ([]BrandRole, error)
+	// AddSubscription adds a user's subscription, doing nothing if the user is already subscribed to the same
+	// brand and pattern.
+	AddSubscription(ctx context.Context, sub Subscription) error
+	// RemoveSubscription removes a user's subscription to a brand or manufacturer and pattern.
+	RemoveSubscription(ctx context.Context, sub Subscription) error
+	// RemoveUserSubscriptions removes every subscription of a user.
+	RemoveUserSubscriptions(ctx context.Context, userID string) error
+	// GetSubscriptions returns every subscription, ordered by user.
+	GetSubscriptions(ctx context.Context) ([]Subscription, error)
+	// GetUserSubscriptions returns the subscriptions of a user.
+	GetUserSubscriptions(ctx context.Context, userID string) ([]Subscription, error)
+	// AddEmailSubscription adds a pending email subscription, reporting false and doing nothing if the address is
+	// already subscribed, or pending a subscription, to the same brand and pattern.
+	AddEmailSubscription(ctx context.Context, sub EmailSubscription) (bool, error)
+	// ConfirmEmailSubscription confirms the subscription with token at confirmedAt, or returns ErrNotFound if
+	// there's none. Confirming a confirmed subscription does nothing.
+	ConfirmEmailSubscription(ctx context.Context, token string, confirmedAt time.Time) error
+	// RemoveEmailSubscription removes the subscription with token or returns ErrNotFound if there's none.
+	RemoveEmailSubscription(ctx context.Context, token string) error
+	// GetEmailSubscriptions returns every confirmed email subscription, ordered by address.
+	GetEmailSubscriptions(ctx context.Context) ([]EmailSubscription, error)
+	// GetAddressEmailSubscriptions returns every subscription of an address, confirmed or pending, oldest first.
+	GetAddressEmailSubscriptions(ctx context.Context, email string) ([]EmailSubscription, error)
+	// RemovePendingEmailSubscriptions removes the subscriptions made before before that are still waiting to be
+	// confirmed, returning how many were removed.
+	RemovePendingEmailSubscriptions(ctx context.Context, before time.Time) (int, error)
 }
 
 type CRDBStore struct {
@@ -34,31 +107,96 @@ func (s *CRDBStore) AddBalls(ctx context.Context, balls []Ball) error {
 	defer tx.Rollback(ctx)
 
 	for _, ball := range balls {
+		trigrams := nameTrigrams(NormalizeName(ball.Name))
+
 		args := pgx.NamedArgs{
-			"brand":       ball.Brand,
-			"name":        ball.Name,
-			"image_url":   ball.ImageURL,
-			"approved_at": ball.ApprovalDate,
+			"brand":           ball.Brand,
+			"name":            ball.Name,
+			"image_url":       ball.ImageURL,
+			"approved_at":     ball.ApprovalDate,
+			"search_trigrams": len(trigrams),
 		}
 
 		stmt := `
-		INSERT INTO balls (brand, name, image_url, approved_at) VALUES (@brand, @name, @image_url, @approved_at)
+		INSERT INTO balls (brand, name, image_url, approved_at, search_trigrams)
+		VALUES (@brand, @name, @image_url, @approved_at, @search_trigrams)
+		RETURNING id
 		`
 
-		if _, err = tx.Exec(ctx, stmt, args); err != nil {
+		var id int
+		if err = tx.QueryRow(ctx, stmt, args).Scan(&id); err != nil {
+			if isUniqueViolation(err) {
+				return fmt.Errorf("exec: %w: %w", ErrDuplicateBall, err)
+			}
 			return fmt.Errorf("exec: %w", err)
 		}
+
+		if err = indexTrigrams(ctx, tx, id, trigrams); err != nil {
+			return err
+		}
 	}
 
 	return tx.Commit(ctx)
 }
 
+func indexTrigrams(ctx context.Context, tx pgx.Tx, id int, trigrams []string) error {
+	if len(trigrams) == 0 {
+		return nil
+	}
+
+	stmt := `INSERT INTO ball_trigrams (trigram, ball_id) SELECT unnest(@trigrams::TEXT[]), @ball_id`
+
+	if _, err := tx.Exec(ctx, stmt, pgx.NamedArgs{"trigrams": trigrams, "ball_id": id}); err != nil {
+		return fmt.Errorf("indexing trigrams: %w", err)
+	}
+
+	return nil
+}
+
 func (s *CRDBStore) GetAllBalls(ctx context.Context, filter BallFilter) ([]Ball, error) {
+	where, args := crdbBallFilter(filter)
+	stmt := `
+	SELECT
+		id,
+		brand,
+		name,
+		approved_at,
+		image_url
+	FROM balls
+	WHERE ` + where
+
+	return s.queryBalls(ctx, stmt, args)
+}
+
+func (s *CRDBStore) GetRecentBalls(ctx context.Context, filter BallFilter, limit int) ([]Ball, error) {
+	where, args := crdbBallFilter(filter)
+	args["limit"] = limit
+	stmt := `
+	SELECT
+		id,
+		brand,
+		name,
+		approved_at,
+		image_url
+	FROM balls
+	WHERE ` + where + `
+	ORDER BY approved_at DESC, id DESC
+	LIMIT @limit`
+
+	return s.queryBalls(ctx, stmt, args)
+}
+
+// crdbBallFilter returns the WHERE clause and arguments selecting the balls matching filter.
+func crdbBallFilter(filter BallFilter) (string, pgx.NamedArgs) {
 	where, args := []string{"1 = 1"}, pgx.NamedArgs{}
 	if filter.Brand != nil {
 		where = append(where, "brand = @brand")
 		args["brand"] = *filter.Brand
 	}
+	if filter.Manufacturer != nil {
+		where = append(where, "brand = ANY(@manufacturer_brands)")
+		args["manufacturer_brands"] = brandStrings(filter.Manufacturer.Brands())
+	}
 	if filter.Name != nil {
 		where = append(where, "name = @name")
 		args["name"] = *filter.Name
@@ -68,6 +206,10 @@ func (s *CRDBStore) GetAllBalls(ctx context.Context, filter BallFilter) ([]Ball,
 		args["approved_at"] = *filter.ApprovalDate
 	}
 
+	return strings.Join(where, " AND "), args
+}
+
+func (s *CRDBStore) GetPendingBalls(ctx context.Context, maxAttempts int) ([]Ball, error) {
 	stmt := `
 	SELECT
 		id,
@@ -76,7 +218,35 @@ func (s *CRDBStore) GetAllBalls(ctx context.Context, filter BallFilter) ([]Ball,
 		approved_at,
 		image_url
 	FROM balls
-	WHERE ` + strings.Join(where, " AND ")
+	WHERE notified_at IS NULL AND notify_attempts < @max_attempts
+	ORDER BY approved_at, id
+	`
+
+	return s.queryBalls(ctx, stmt, pgx.NamedArgs{"max_attempts": maxAttempts})
+}
+
+func (s *CRDBStore) MarkBallsNotified(ctx context.Context, ids []int, notifiedAt time.Time) error {
+	stmt := `UPDATE balls SET notified_at = @notified_at WHERE id = ANY(@ids)`
+
+	if _, err := s.db.Exec(ctx, stmt, pgx.NamedArgs{"ids": ids, "notified_at": notifiedAt}); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) AddNotifyAttempt(ctx context.Context, ids []int) error {
+	stmt := `UPDATE balls SET notify_attempts = notify_attempts + 1 WHERE id = ANY(@ids) AND notified_at IS NULL`
+
+	if _, err := s.db.Exec(ctx, stmt, pgx.NamedArgs{"ids": ids}); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+// queryBalls returns the balls selected by stmt, which must select the columns scanned by GetAllBalls.
+func (s *CRDBStore) queryBalls(ctx context.Context, stmt string, args pgx.NamedArgs) ([]Ball, error) {
 	rows, err := s.db.Query(ctx, stmt, args)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
@@ -111,3 +281,671 @@ func (s *CRDBStore) GetAllBalls(ctx context.Context, filter BallFilter) ([]Ball,
 
 	return balls, nil
 }
+
+func (s *CRDBStore) AddRun(ctx context.Context, run Run) error {
+	args := pgx.NamedArgs{
+		"id":          run.ID,
+		"started_at":  run.StartedAt,
+		"finished_at": run.FinishedAt,
+		"approved":    run.Approved,
+		"error":       nil,
+	}
+	if run.Err != "" {
+		args["error"] = run.Err
+	}
+
+	stmt := `
+	INSERT INTO runs (id, started_at, finished_at, approved, error) VALUES (@id, @started_at, @finished_at, @approved, @error)
+	`
+
+	if _, err := s.db.Exec(ctx, stmt, args); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) GetLastSuccessfulRun(ctx context.Context) (Run, error) {
+	stmt := `
+	SELECT
+		id,
+		started_at,
+		finished_at,
+		approved
+	FROM runs
+	WHERE error IS NULL
+	ORDER BY finished_at DESC
+	LIMIT 1
+	`
+
+	var run Run
+	err := s.db.QueryRow(ctx, stmt).Scan(&run.ID, &run.StartedAt, &run.FinishedAt, &run.Approved)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Run{}, ErrNotFound
+		}
+		return Run{}, fmt.Errorf("scan: %w", err)
+	}
+
+	return run, nil
+}
+
+func (s *CRDBStore) AcquireRunLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	args := pgx.NamedArgs{
+		"holder":     holder,
+		"now":        now,
+		"expires_at": now.Add(ttl),
+	}
+
+	stmt := `
+	INSERT INTO run_lease (id, holder, expires_at) VALUES (1, @holder, @expires_at)
+	ON CONFLICT (id) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+	WHERE run_lease.expires_at < @now
+	RETURNING holder
+	`
+
+	var got string
+	if err := s.db.QueryRow(ctx, stmt, args).Scan(&got); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("scan: %w", err)
+	}
+
+	return true, nil
+}
+
+func (s *CRDBStore) ReleaseRunLease(ctx context.Context, holder string) error {
+	stmt := `DELETE FROM run_lease WHERE id = 1 AND holder = @holder`
+
+	if _, err := s.db.Exec(ctx, stmt, pgx.NamedArgs{"holder": holder}); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) SearchBalls(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	trigrams := nameTrigrams(NormalizeName(query))
+	if len(trigrams) == 0 {
+		return nil, nil
+	}
+
+	args := pgx.NamedArgs{
+		"trigrams":  trigrams,
+		"n":         len(trigrams),
+		"min_score": minSearchScore,
+		"limit":     limit,
+	}
+
+	stmt := `
+	SELECT
+		b.id,
+		b.brand,
+		b.name,
+		b.approved_at,
+		b.image_url,
+		b.search_trigrams,
+		count(*) AS shared
+	FROM ball_trigrams t
+	JOIN balls b ON b.id = t.ball_id
+	WHERE t.trigram = ANY(@trigrams)
+	GROUP BY b.id, b.brand, b.name, b.approved_at, b.image_url, b.search_trigrams
+	HAVING count(*)::FLOAT8 / (@n + b.search_trigrams - count(*))::FLOAT8 >= @min_score
+	ORDER BY count(*)::FLOAT8 / (@n + b.search_trigrams - count(*))::FLOAT8 DESC, b.approved_at DESC
+	LIMIT @limit
+	`
+	rows, err := s.db.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var ball Ball
+		var imageURL string
+		var ballTrigrams, shared int
+		err = rows.Scan(
+			&ball.ID,
+			&ball.Brand,
+			&ball.Name,
+			&ball.ApprovalDate,
+			&imageURL,
+			&ballTrigrams,
+			&shared,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		ball.ImageURL, err = url.Parse(imageURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing image url: %w", err)
+		}
+
+		results = append(results, SearchResult{
+			Ball:  ball,
+			Score: trigramSimilarity(shared, len(trigrams), ballTrigrams),
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return results, nil
+}
+
+func (s *CRDBStore) ReindexSearch(ctx context.Context) (int, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT id, name FROM balls WHERE search_trigrams = 0`)
+	if err != nil {
+		return 0, fmt.Errorf("query: %w", err)
+	}
+
+	type unindexed struct {
+		id   int
+		name string
+	}
+	var balls []unindexed
+	for rows.Next() {
+		var b unindexed
+		if err = rows.Scan(&b.id, &b.name); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan: %w", err)
+		}
+		balls = append(balls, b)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows err: %w", err)
+	}
+
+	indexed := 0
+	for _, b := range balls {
+		trigrams := nameTrigrams(NormalizeName(b.name))
+		if len(trigrams) == 0 {
+			continue
+		}
+
+		if err = indexTrigrams(ctx, tx, b.id, trigrams); err != nil {
+			return 0, err
+		}
+
+		stmt := `UPDATE balls SET search_trigrams = @search_trigrams WHERE id = @id`
+		_, err = tx.Exec(ctx, stmt, pgx.NamedArgs{"search_trigrams": len(trigrams), "id": b.id})
+		if err != nil {
+			return 0, fmt.Errorf("exec: %w", err)
+		}
+		indexed++
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+
+	return indexed, nil
+}
+
+func (s *CRDBStore) AddBallAlias(ctx context.Context, alias BallAlias) error {
+	args := pgx.NamedArgs{
+		"brand":     alias.Brand,
+		"alias":     alias.Alias,
+		"canonical": alias.Canonical,
+	}
+
+	stmt := `
+	INSERT INTO ball_aliases (brand, alias, canonical) VALUES (@brand, @alias, @canonical)
+	ON CONFLICT (brand, alias) DO UPDATE SET canonical = excluded.canonical
+	`
+
+	if _, err := s.db.Exec(ctx, stmt, args); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) GetBallAliases(ctx context.Context) ([]BallAlias, error) {
+	rows, err := s.db.Query(ctx, `SELECT brand, alias, canonical FROM ball_aliases`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []BallAlias
+	for rows.Next() {
+		var a BallAlias
+		if err = rows.Scan(&a.Brand, &a.Alias, &a.Canonical); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		aliases = append(aliases, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return aliases, nil
+}
+
+func (s *CRDBStore) AddDigestBalls(ctx context.Context, digest string, balls []Ball) error {
+	batch := &pgx.Batch{}
+	for _, ball := range balls {
+		args := pgx.NamedArgs{
+			"digest":        digest,
+			"brand":         ball.Brand,
+			"name":          ball.Name,
+			"approved_at":   ball.ApprovalDate,
+			"image_url":     imageURLString(ball.ImageURL),
+			"canonical_key": ball.CanonicalKey,
+		}
+
+		batch.Queue(`
+		INSERT INTO digest_balls (digest, brand, name, approved_at, image_url, canonical_key)
+		VALUES (@digest, @brand, @name, @approved_at, @image_url, @canonical_key)
+		ON CONFLICT (digest, brand, name, approved_at) DO NOTHING
+		`, args)
+	}
+
+	if err := s.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) TakeDigestBalls(ctx context.Context, digest string) ([]Ball, error) {
+	stmt := `
+	DELETE FROM digest_balls WHERE digest = @digest
+	RETURNING brand, name, approved_at, image_url, canonical_key
+	`
+
+	rows, err := s.db.Query(ctx, stmt, pgx.NamedArgs{"digest": digest})
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var balls []Ball
+	for rows.Next() {
+		var (
+			ball     Ball
+			imageURL string
+		)
+		if err = rows.Scan(&ball.Brand, &ball.Name, &ball.ApprovalDate, &imageURL, &ball.CanonicalKey); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		ball.ImageURL, err = url.Parse(imageURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing image url: %w", err)
+		}
+
+		balls = append(balls, ball)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	sortDigestBalls(balls)
+
+	return balls, nil
+}
+
+func brandStrings(brands []Brand) []string {
+	s := make([]string, 0, len(brands))
+	for _, b := range brands {
+		s = append(s, string(b))
+	}
+
+	return s
+}
+
+// uniqueViolation is the sqlstate returned when an insert violates a unique constraint.
+const uniqueViolation = "23505"
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+func (s *CRDBStore) DisableChannel(ctx context.Context, channel DisabledChannel) error {
+	args := pgx.NamedArgs{
+		"channel_id":  channel.ChannelID,
+		"reason":      channel.Reason,
+		"disabled_at": channel.DisabledAt,
+	}
+
+	stmt := `
+	INSERT INTO disabled_channels (channel_id, reason, disabled_at) VALUES (@channel_id, @reason, @disabled_at)
+	ON CONFLICT (channel_id) DO UPDATE SET reason = excluded.reason, disabled_at = excluded.disabled_at
+	`
+
+	if _, err := s.db.Exec(ctx, stmt, args); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) EnableChannel(ctx context.Context, channelID string) error {
+	stmt := `DELETE FROM disabled_channels WHERE channel_id = @channel_id`
+
+	if _, err := s.db.Exec(ctx, stmt, pgx.NamedArgs{"channel_id": channelID}); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) GetDisabledChannels(ctx context.Context) ([]DisabledChannel, error) {
+	rows, err := s.db.Query(ctx, `SELECT channel_id, reason, disabled_at FROM disabled_channels`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var channels []DisabledChannel
+	for rows.Next() {
+		var c DisabledChannel
+		if err = rows.Scan(&c.ChannelID, &c.Reason, &c.DisabledAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		channels = append(channels, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return channels, nil
+}
+
+func (s *CRDBStore) AddBrandRole(ctx context.Context, role BrandRole) (BrandRole, error) {
+	args := pgx.NamedArgs{
+		"guild_id":     role.GuildID,
+		"brand":        role.Brand,
+		"manufacturer": role.Manufacturer,
+		"role_id":      role.RoleID,
+	}
+
+	stmt := `
+	INSERT INTO brand_roles (guild_id, brand, manufacturer, role_id) VALUES (@guild_id, @brand, @manufacturer, @role_id)
+	ON CONFLICT (guild_id, brand, manufacturer) DO NOTHING
+	`
+	if _, err := s.db.Exec(ctx, stmt, args); err != nil {
+		return BrandRole{}, fmt.Errorf("exec: %w", err)
+	}
+
+	stmt = `
+	SELECT role_id FROM brand_roles WHERE guild_id = @guild_id AND brand = @brand AND manufacturer = @manufacturer
+	`
+	if err := s.db.QueryRow(ctx, stmt, args).Scan(&role.RoleID); err != nil {
+		return BrandRole{}, fmt.Errorf("scan: %w", err)
+	}
+
+	return role, nil
+}
+
+func (s *CRDBStore) RemoveBrandRole(ctx context.Context, role BrandRole) error {
+	args := pgx.NamedArgs{
+		"guild_id":     role.GuildID,
+		"brand":        role.Brand,
+		"manufacturer": role.Manufacturer,
+		"role_id":      role.RoleID,
+	}
+
+	stmt := `
+	DELETE FROM brand_roles
+	WHERE guild_id = @guild_id AND brand = @brand AND manufacturer = @manufacturer AND role_id = @role_id
+	`
+	if _, err := s.db.Exec(ctx, stmt, args); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) GetBrandRoles(ctx context.Context, guildID string) ([]BrandRole, error) {
+	stmt := `SELECT guild_id, brand, manufacturer, role_id FROM brand_roles WHERE guild_id = @guild_id ORDER BY brand, manufacturer`
+
+	rows, err := s.db.Query(ctx, stmt, pgx.NamedArgs{"guild_id": guildID})
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []BrandRole
+	for rows.Next() {
+		var r BrandRole
+		if err = rows.Scan(&r.GuildID, &r.Brand, &r.Manufacturer, &r.RoleID); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		roles = append(roles, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (s *CRDBStore) AddSubscription(ctx context.Context, sub Subscription) error {
+	args := pgx.NamedArgs{
+		"user_id":      sub.UserID,
+		"brand":        sub.Brand,
+		"manufacturer": sub.Manufacturer,
+		"pattern":      sub.Pattern,
+		"created_at":   sub.CreatedAt,
+	}
+
+	stmt := `
+	INSERT INTO subscriptions (user_id, brand, manufacturer, pattern, created_at)
+	VALUES (@user_id, @brand, @manufacturer, @pattern, @created_at)
+	ON CONFLICT (user_id, brand, manufacturer, pattern) DO NOTHING
+	`
+	if _, err := s.db.Exec(ctx, stmt, args); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) RemoveSubscription(ctx context.Context, sub Subscription) error {
+	args := pgx.NamedArgs{
+		"user_id":      sub.UserID,
+		"brand":        sub.Brand,
+		"manufacturer": sub.Manufacturer,
+		"pattern":      sub.Pattern,
+	}
+
+	stmt := `
+	DELETE FROM subscriptions
+	WHERE user_id = @user_id AND brand = @brand AND manufacturer = @manufacturer AND pattern = @pattern
+	`
+	if _, err := s.db.Exec(ctx, stmt, args); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) RemoveUserSubscriptions(ctx context.Context, userID string) error {
+	stmt := `DELETE FROM subscriptions WHERE user_id = @user_id`
+	if _, err := s.db.Exec(ctx, stmt, pgx.NamedArgs{"user_id": userID}); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	stmt := `
+	SELECT user_id, brand, manufacturer, pattern, created_at FROM subscriptions
+	ORDER BY user_id, brand, manufacturer, pattern
+	`
+
+	return s.querySubscriptions(ctx, stmt, nil)
+}
+
+func (s *CRDBStore) GetUserSubscriptions(ctx context.Context, userID string) ([]Subscription, error) {
+	stmt := `
+	SELECT user_id, brand, manufacturer, pattern, created_at FROM subscriptions WHERE user_id = @user_id
+	ORDER BY brand, manufacturer, pattern
+	`
+
+	return s.querySubscriptions(ctx, stmt, pgx.NamedArgs{"user_id": userID})
+}
+
+func (s *CRDBStore) querySubscriptions(ctx context.Context, stmt string, args pgx.NamedArgs) ([]Subscription, error) {
+	rows, err := s.db.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		var sub Subscription
+		if err = rows.Scan(&sub.UserID, &sub.Brand, &sub.Manufacturer, &sub.Pattern, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (s *CRDBStore) AddEmailSubscription(ctx context.Context, sub EmailSubscription) (bool, error) {
+	args := pgx.NamedArgs{
+		"email":        sub.Email,
+		"brand":        sub.Brand,
+		"manufacturer": sub.Manufacturer,
+		"pattern":      sub.Pattern,
+		"token":        sub.Token,
+		"created_at":   sub.CreatedAt,
+	}
+
+	stmt := `
+	INSERT INTO email_subscriptions (email, brand, manufacturer, pattern, token, created_at)
+	VALUES (@email, @brand, @manufacturer, @pattern, @token, @created_at)
+	ON CONFLICT (email, brand, manufacturer, pattern) DO NOTHING
+	`
+	tag, err := s.db.Exec(ctx, stmt, args)
+	if err != nil {
+		return false, fmt.Errorf("exec: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *CRDBStore) ConfirmEmailSubscription(ctx context.Context, token string, confirmedAt time.Time) error {
+	args := pgx.NamedArgs{
+		"token":        token,
+		"confirmed_at": confirmedAt,
+	}
+
+	stmt := `UPDATE email_subscriptions SET confirmed_at = COALESCE(confirmed_at, @confirmed_at) WHERE token = @token`
+	tag, err := s.db.Exec(ctx, stmt, args)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) RemoveEmailSubscription(ctx context.Context, token string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM email_subscriptions WHERE token = @token`, pgx.NamedArgs{"token": token})
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *CRDBStore) GetEmailSubscriptions(ctx context.Context) ([]EmailSubscription, error) {
+	stmt := `
+	SELECT email, brand, manufacturer, pattern, token, created_at, confirmed_at FROM email_subscriptions
+	WHERE confirmed_at IS NOT NULL
+	ORDER BY email, brand, manufacturer, pattern
+	`
+
+	return s.queryEmailSubscriptions(ctx, stmt, nil)
+}
+
+func (s *CRDBStore) GetAddressEmailSubscriptions(ctx context.Context, email string) ([]EmailSubscription, error) {
+	stmt := `
+	SELECT email, brand, manufacturer, pattern, token, created_at, confirmed_at FROM email_subscriptions
+	WHERE email = @email
+	ORDER BY created_at
+	`
+
+	return s.queryEmailSubscriptions(ctx, stmt, pgx.NamedArgs{"email": email})
+}
+
+func (s *CRDBStore) RemovePendingEmailSubscriptions(ctx context.Context, before time.Time) (int, error) {
+	stmt := `DELETE FROM email_subscriptions WHERE confirmed_at IS NULL AND created_at < @before`
+
+	tag, err := s.db.Exec(ctx, stmt, pgx.NamedArgs{"before": before})
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (s *CRDBStore) queryEmailSubscriptions(
+	ctx context.Context,
+	stmt string,
+	args pgx.NamedArgs,
+) ([]EmailSubscription, error) {
+	rows, err := s.db.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []EmailSubscription
+	for rows.Next() {
+		var (
+			sub         EmailSubscription
+			confirmedAt *time.Time
+		)
+		err = rows.Scan(&sub.Email, &sub.Brand, &sub.Manufacturer, &sub.Pattern, &sub.Token, &sub.CreatedAt, &confirmedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		if confirmedAt != nil {
+			sub.ConfirmedAt = *confirmedAt
+		}
+		subs = append(subs, sub)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return subs, nil
+}
+
+// imageURLString returns u as stored in the image_url columns, balls without an image are stored as empty strings.
+func imageURLString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	return u.String()
+}
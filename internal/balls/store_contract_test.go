@@ -0,0 +1,706 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/actatum/approved-ball-list/internal/crdb"
+	"github.com/actatum/approved-ball-list/internal/sqlite"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// testStoreContract runs the behaviour every Store implementation must share against the stores returned by
+// newStore, each of which must be empty.
+func testStoreContract(t *testing.T, newStore func(t *testing.T) Store) {
+	t.Helper()
+
+	imageURL := &url.URL{Scheme: "http", Host: "some-url"}
+	now := time.Now()
+
+	t.Run("add and get balls", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		input := []Ball{
+			{Brand: Storm, Name: "Phaze II", ImageURL: imageURL, ApprovalDate: now},
+			{Brand: Motiv, Name: "Venom Shock", ImageURL: imageURL, ApprovalDate: now},
+		}
+
+		if err := s.AddBalls(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.GetAllBalls(ctx, BallFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertBalls(t, got, input)
+		for _, b := range got {
+			if b.ID == 0 {
+				t.Fatalf("expected store to set id")
+			}
+		}
+	})
+
+	t.Run("duplicate brand, name, and approved_at", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		seed := Ball{Brand: Hammer, Name: "Black Widow Mania", ImageURL: imageURL, ApprovalDate: now}
+		if err := s.AddBalls(ctx, []Ball{seed}); err != nil {
+			t.Fatal(err)
+		}
+
+		other := Ball{Brand: Hammer, Name: "Black Widow 3.0", ImageURL: imageURL, ApprovalDate: now}
+		err := s.AddBalls(ctx, []Ball{other, seed})
+		if !errors.Is(err, ErrDuplicateBall) {
+			t.Fatalf("expected ErrDuplicateBall got %v", err)
+		}
+
+		got, err := s.GetAllBalls(ctx, BallFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertBalls(t, got, []Ball{seed})
+	})
+
+	t.Run("same brand and name approved again", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		input := []Ball{
+			{Brand: Ebonite, Name: "Turbo X", ImageURL: imageURL, ApprovalDate: now.AddDate(-20, 0, 0)},
+			{Brand: Ebonite, Name: "Turbo X", ImageURL: imageURL, ApprovalDate: now},
+		}
+		if err := s.AddBalls(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		seed := []Ball{
+			{Brand: Hammer, Name: "Black Widow Mania", ImageURL: imageURL, ApprovalDate: now.AddDate(0, -4, 0)},
+			{Brand: Ebonite, Name: "The One Reverb", ImageURL: imageURL, ApprovalDate: now},
+			{Brand: Ebonite, Name: "Turbo X", ImageURL: imageURL, ApprovalDate: now.AddDate(-20, 0, 0)},
+			{Brand: Ebonite, Name: "Turbo X", ImageURL: imageURL, ApprovalDate: now},
+		}
+		if err := s.AddBalls(ctx, seed); err != nil {
+			t.Fatal(err)
+		}
+		brunswick, storm := BrunswickBowling, StormProducts
+
+		tests := []struct {
+			name   string
+			filter BallFilter
+			want   []Ball
+		}{
+			{
+				name:   "brand",
+				filter: BallFilter{Brand: &seed[0].Brand},
+				want:   seed[:1],
+			},
+			{
+				name:   "manufacturer",
+				filter: BallFilter{Manufacturer: &brunswick},
+				want:   seed,
+			},
+			{
+				name:   "manufacturer without balls",
+				filter: BallFilter{Manufacturer: &storm},
+				want:   nil,
+			},
+			{
+				name:   "name",
+				filter: BallFilter{Name: &seed[1].Name},
+				want:   seed[1:2],
+			},
+			{
+				name:   "approval date",
+				filter: BallFilter{ApprovalDate: &seed[0].ApprovalDate},
+				want:   seed[:1],
+			},
+			{
+				name: "all",
+				filter: BallFilter{
+					Brand:        &seed[2].Brand,
+					Name:         &seed[2].Name,
+					ApprovalDate: &seed[2].ApprovalDate,
+				},
+				want: seed[2:3],
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := s.GetAllBalls(ctx, tt.filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assertBalls(t, got, tt.want)
+			})
+		}
+
+		t.Run("recent", func(t *testing.T) {
+			got, err := s.GetRecentBalls(ctx, BallFilter{Brand: &seed[1].Brand}, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Balls approved at the same time are newest first by id.
+			want := []Ball{seed[3], seed[1]}
+			diff := cmp.Diff(got, want, cmpopts.EquateApproxTime(time.Millisecond), cmpopts.IgnoreFields(Ball{}, "ID"))
+			if diff != "" {
+				t.Fatalf("(-got, +want):\n%s", diff)
+			}
+		})
+	})
+
+	t.Run("pending balls", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		input := []Ball{
+			{Brand: Storm, Name: "Phaze II", ImageURL: imageURL, ApprovalDate: now},
+			{Brand: Motiv, Name: "Venom Shock", ImageURL: imageURL, ApprovalDate: now.Add(-time.Hour)},
+			{Brand: Hammer, Name: "Black Widow", ImageURL: imageURL, ApprovalDate: now.Add(-2 * time.Hour)},
+		}
+		if err := s.AddBalls(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+
+		pending, err := s.GetPendingBalls(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 3 || pending[0].Name != "Black Widow" || pending[2].Name != "Phaze II" {
+			t.Fatalf("expected every ball to be pending oldest approval first got %+v", pending)
+		}
+
+		if err := s.MarkBallsNotified(ctx, []int{pending[0].ID}, now); err != nil {
+			t.Fatal(err)
+		}
+		for range 2 {
+			if err := s.AddNotifyAttempt(ctx, []int{pending[0].ID, pending[1].ID}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := s.GetPendingBalls(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertBalls(t, got, input[:1])
+
+		// Notified balls stay notified however many attempts are allowed.
+		got, err = s.GetPendingBalls(ctx, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertBalls(t, got, input[:2])
+	})
+
+	t.Run("last successful run", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		if _, err := s.GetLastSuccessfulRun(ctx); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound got %v", err)
+		}
+
+		runs := []Run{
+			{ID: "1", StartedAt: now.Add(-3 * time.Hour), FinishedAt: now.Add(-3 * time.Hour), Approved: 2},
+			{ID: "2", StartedAt: now.Add(-2 * time.Hour), FinishedAt: now.Add(-2 * time.Hour), Approved: 1},
+			{ID: "3", StartedAt: now.Add(-time.Hour), FinishedAt: now.Add(-time.Hour), Err: "notifying: error"},
+		}
+		for _, r := range runs {
+			if err := s.AddRun(ctx, r); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := s.GetLastSuccessfulRun(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		diff := cmp.Diff(got, runs[1], cmpopts.EquateApproxTime(time.Millisecond))
+		if diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		seed := []Ball{
+			{Brand: Storm, Name: "Phaze II", ImageURL: imageURL, ApprovalDate: now.AddDate(-5, 0, 0)},
+			{Brand: Storm, Name: "Phaze III", ImageURL: imageURL, ApprovalDate: now.AddDate(-3, 0, 0)},
+			{Brand: Hammer, Name: "Black Widow 3.0", ImageURL: imageURL, ApprovalDate: now},
+			{Brand: Motiv, Name: "Venom Shock", ImageURL: imageURL, ApprovalDate: now},
+		}
+		if err := s.AddBalls(ctx, seed); err != nil {
+			t.Fatal(err)
+		}
+
+		if n, err := s.ReindexSearch(ctx); err != nil || n != 0 {
+			t.Fatalf("expected nothing to reindex got %d, %v", n, err)
+		}
+
+		got, err := s.SearchBalls(ctx, "PHAZE-2", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("expected 2 results got %d", len(got))
+		}
+		assertBalls(t, []Ball{got[0].Ball}, seed[:1])
+		if got[0].Score != 1 || got[1].Score >= 1 || got[1].Score < minSearchScore {
+			t.Fatalf("expected exact match ranked first got scores %f, %f", got[0].Score, got[1].Score)
+		}
+
+		got, err = s.SearchBalls(ctx, "phaze", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("expected limit of 1 result got %d", len(got))
+		}
+
+		got, err = s.SearchBalls(ctx, "quantum", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 0 {
+			t.Fatalf("expected no results got %d", len(got))
+		}
+	})
+
+	t.Run("canonical key and aliases", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		input := []Ball{
+			{Brand: Storm, Name: "Phaze II", ImageURL: imageURL, ApprovalDate: now, CanonicalKey: "phaze 2"},
+		}
+		if err := s.AddBalls(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.GetAllBalls(ctx, BallFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		input[0].CanonicalKey = ""
+		assertBalls(t, got, input)
+
+		aliases := []BallAlias{
+			{Brand: Storm, Alias: "phase 2", Canonical: "phaze"},
+			{Brand: Motiv, Alias: "venom shok", Canonical: "venom shock"},
+		}
+		for _, a := range aliases {
+			if err = s.AddBallAlias(ctx, a); err != nil {
+				t.Fatal(err)
+			}
+		}
+		aliases[0].Canonical = "phaze 2"
+		if err = s.AddBallAlias(ctx, aliases[0]); err != nil {
+			t.Fatal(err)
+		}
+
+		gotAliases, err := s.GetBallAliases(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff := cmp.Diff(gotAliases, aliases, cmpopts.SortSlices(func(a, b BallAlias) bool {
+			return a.Alias < b.Alias
+		}))
+		if diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("digest balls", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		daily := []Ball{
+			{Brand: Storm, Name: "Phaze II", ImageURL: imageURL, ApprovalDate: now, CanonicalKey: "phaze 2"},
+			{Brand: Motiv, Name: "Venom Shock", ImageURL: imageURL, ApprovalDate: now.Add(-time.Hour)},
+		}
+		if err := s.AddDigestBalls(ctx, "daily", daily); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddDigestBalls(ctx, "daily", daily[:1]); err != nil {
+			t.Fatalf("expected duplicates to be ignored got %v", err)
+		}
+		if err := s.AddDigestBalls(ctx, "weekly", daily[:1]); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.TakeDigestBalls(ctx, "daily")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertBalls(t, got, daily)
+		if got[0].Name != "Venom Shock" {
+			t.Fatalf("expected oldest approval first got %s", got[0].Name)
+		}
+
+		if got, err = s.TakeDigestBalls(ctx, "daily"); err != nil || len(got) != 0 {
+			t.Fatalf("expected taken balls to be removed got %v, %v", got, err)
+		}
+
+		got, err = s.TakeDigestBalls(ctx, "weekly")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertBalls(t, got, daily[:1])
+	})
+
+	t.Run("disabled channels", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		channels := []DisabledChannel{
+			{ChannelID: "1", Reason: "Unknown Channel", DisabledAt: now},
+			{ChannelID: "2", Reason: "Missing Access", DisabledAt: now},
+		}
+		for _, c := range channels {
+			if err := s.DisableChannel(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		channels[0].Reason = "Missing Permissions"
+		if err := s.DisableChannel(ctx, channels[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.EnableChannel(ctx, "2"); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.GetDisabledChannels(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff := cmp.Diff(got, channels[:1], cmpopts.EquateApproxTime(time.Millisecond))
+		if diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("brand roles", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		storm := BrandRole{GuildID: "1", Brand: Storm, RoleID: "10"}
+		brunswick := BrandRole{GuildID: "1", Manufacturer: BrunswickBowling, RoleID: "11"}
+		for _, r := range []BrandRole{storm, brunswick, {GuildID: "2", Brand: Storm, RoleID: "20"}} {
+			if _, err := s.AddBrandRole(ctx, r); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := s.AddBrandRole(ctx, BrandRole{GuildID: "1", Brand: Storm, RoleID: "12"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != storm {
+			t.Fatalf("expected existing role %+v got %+v", storm, got)
+		}
+
+		if err = s.RemoveBrandRole(ctx, BrandRole{GuildID: "1", Manufacturer: BrunswickBowling, RoleID: "13"}); err != nil {
+			t.Fatal(err)
+		}
+		roles, err := s.GetBrandRoles(ctx, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(roles, []BrandRole{brunswick, storm}); diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+
+		if err = s.RemoveBrandRole(ctx, brunswick); err != nil {
+			t.Fatal(err)
+		}
+		roles, err = s.GetBrandRoles(ctx, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(roles, []BrandRole{storm}); diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("subscriptions", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		subs := []Subscription{
+			{UserID: "1", Pattern: "phaze", CreatedAt: now},
+			{UserID: "1", Manufacturer: StormProducts, Pattern: "phaze", CreatedAt: now},
+			{UserID: "1", Brand: Storm, Pattern: "phaze", CreatedAt: now},
+			{UserID: "2", Pattern: "idol", CreatedAt: now},
+			{UserID: "2", Brand: Motiv, Pattern: "venom", CreatedAt: now},
+		}
+		for _, sub := range subs {
+			if err := s.AddSubscription(ctx, sub); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.AddSubscription(ctx, Subscription{UserID: "1", Pattern: "phaze", CreatedAt: now.Add(time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.GetSubscriptions(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, subs, cmpopts.EquateApproxTime(time.Millisecond)); diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+
+		if err = s.RemoveSubscription(ctx, Subscription{UserID: "1", Brand: Storm, Pattern: "phaze"}); err != nil {
+			t.Fatal(err)
+		}
+		if err = s.RemoveUserSubscriptions(ctx, "2"); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = s.GetUserSubscriptions(ctx, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, subs[:2], cmpopts.EquateApproxTime(time.Millisecond)); diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+
+		got, err = s.GetUserSubscriptions(ctx, "2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 0 {
+			t.Fatalf("expected removed user to have no subscriptions got %+v", got)
+		}
+	})
+
+	t.Run("email subscriptions", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		subs := []EmailSubscription{
+			{Email: "a@example.com", Token: "t1", CreatedAt: now},
+			{Email: "a@example.com", Manufacturer: StormProducts, Pattern: "phaze", Token: "t6", CreatedAt: now},
+			{Email: "a@example.com", Brand: Storm, Pattern: "phaze", Token: "t2", CreatedAt: now},
+			{Email: "b@example.com", Pattern: "idol", Token: "t3", CreatedAt: now},
+			{Email: "c@example.com", Token: "t4", CreatedAt: now},
+		}
+		for _, sub := range subs {
+			added, err := s.AddEmailSubscription(ctx, sub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !added {
+				t.Fatalf("expected %+v to be added", sub)
+			}
+		}
+		added, err := s.AddEmailSubscription(ctx,
+			EmailSubscription{Email: "b@example.com", Pattern: "idol", Token: "t5", CreatedAt: now.Add(time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if added {
+			t.Fatal("expected existing subscription not to be added again")
+		}
+
+		got, err := s.GetEmailSubscriptions(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 0 {
+			t.Fatalf("expected pending subscriptions to be left out got %+v", got)
+		}
+
+		for _, token := range []string{"t1", "t2", "t3", "t6"} {
+			if err = s.ConfirmEmailSubscription(ctx, token, now); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = s.ConfirmEmailSubscription(ctx, "t3", now.Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if err = s.ConfirmEmailSubscription(ctx, "t5", now); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound confirming unknown token got %v", err)
+		}
+		if err = s.RemoveEmailSubscription(ctx, "t1"); err != nil {
+			t.Fatal(err)
+		}
+		if err = s.RemoveEmailSubscription(ctx, "t1"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound removing twice got %v", err)
+		}
+
+		got, err = s.GetEmailSubscriptions(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := subs[1:4]
+		for i := range want {
+			want[i].ConfirmedAt = now
+		}
+		if diff := cmp.Diff(got, want, cmpopts.EquateApproxTime(time.Millisecond)); diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+
+		// t4 was never confirmed, pending subscriptions made before the cutoff are removed.
+		address, err := s.GetAddressEmailSubscriptions(ctx, "c@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(address) != 1 || address[0].Token != "t4" || !address[0].ConfirmedAt.IsZero() {
+			t.Fatalf("expected the pending subscription of c@example.com got %+v", address)
+		}
+		if n, err := s.RemovePendingEmailSubscriptions(ctx, now); err != nil || n != 0 {
+			t.Fatalf("expected nothing made before now to be removed got %d %v", n, err)
+		}
+		if n, err := s.RemovePendingEmailSubscriptions(ctx, now.Add(time.Minute)); err != nil || n != 1 {
+			t.Fatalf("expected the pending subscription to be removed got %d %v", n, err)
+		}
+		address, err = s.GetAddressEmailSubscriptions(ctx, "c@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(address) != 0 {
+			t.Fatalf("expected no subscriptions left got %+v", address)
+		}
+
+		address, err = s.GetAddressEmailSubscriptions(ctx, "a@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(address, want[:2], cmpopts.EquateApproxTime(time.Millisecond)); diff != "" {
+			t.Fatalf("(-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("run lease", func(t *testing.T) {
+		s := newStore(t)
+		ctx := context.Background()
+
+		acquired, err := s.AcquireRunLease(ctx, "a", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !acquired {
+			t.Fatal("expected first holder to acquire lease")
+		}
+
+		acquired, err = s.AcquireRunLease(ctx, "b", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if acquired {
+			t.Fatal("expected lease to be held")
+		}
+
+		if err = s.ReleaseRunLease(ctx, "b"); err != nil {
+			t.Fatal(err)
+		}
+		if acquired, _ = s.AcquireRunLease(ctx, "b", time.Minute); acquired {
+			t.Fatal("expected release by another holder to be ignored")
+		}
+
+		if err = s.ReleaseRunLease(ctx, "a"); err != nil {
+			t.Fatal(err)
+		}
+		if acquired, _ = s.AcquireRunLease(ctx, "b", -time.Minute); !acquired {
+			t.Fatal("expected released lease to be acquired")
+		}
+		if acquired, _ = s.AcquireRunLease(ctx, "c", time.Minute); !acquired {
+			t.Fatal("expected expired lease to be acquired")
+		}
+	})
+}
+
+func assertBalls(t *testing.T, got []Ball, want []Ball) {
+	t.Helper()
+
+	diff := cmp.Diff(got, want,
+		cmpopts.EquateApproxTime(time.Millisecond),
+		cmpopts.IgnoreFields(Ball{}, "ID"),
+		cmpopts.SortSlices(func(a, b Ball) bool {
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ApprovalDate.Before(b.ApprovalDate)
+		}),
+	)
+	if diff != "" {
+		t.Fatalf("(-got, +want):\n%s", diff)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStoreContract(t, func(t *testing.T) Store {
+		return NewMemoryStore()
+	})
+}
+
+func TestCRDBStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, cleanup := crdb.StartTestDB(t, false)
+	t.Cleanup(cleanup)
+
+	testStoreContract(t, func(t *testing.T) Store {
+		_, err := db.Exec(context.Background(), `TRUNCATE balls, ball_trigrams, ball_aliases, digest_balls, disabled_channels, brand_roles, subscriptions, email_subscriptions, runs, run_lease CASCADE`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return NewCRDBStore(db)
+	})
+}
+
+func TestCRDBStorePostgres(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, cleanup := crdb.StartTestDBWithDialect(t, crdb.Postgres, false)
+	t.Cleanup(cleanup)
+
+	testStoreContract(t, func(t *testing.T) Store {
+		_, err := db.Exec(context.Background(), `TRUNCATE balls, ball_trigrams, ball_aliases, digest_balls, disabled_channels, brand_roles, subscriptions, email_subscriptions, runs, run_lease CASCADE`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return NewCRDBStore(db)
+	})
+}
+
+func TestSQLiteStore(t *testing.T) {
+	testStoreContract(t, func(t *testing.T) Store {
+		db, err := sqlite.NewDB("sqlite://" + filepath.Join(t.TempDir(), "abl.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			db.Close()
+		})
+
+		return NewSQLiteStore(db)
+	})
+}
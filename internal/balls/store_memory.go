@@ -0,0 +1,593 @@
+package balls
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore implements the Store interface keeping everything in memory, for local development and tests.
+// It enforces the same uniqueness of brand, name and approval date as the database.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	nextID int
+	balls  []Ball
+	// pending are the failed notification attempts of the balls not yet notified of, by id.
+	pending map[int]int
+	runs    []Run
+	lease   *runLease
+	aliases []BallAlias
+	digests map[string][]Ball
+	// disabled are the disabled channels by id.
+	disabled map[string]DisabledChannel
+	roles    []BrandRole
+	subs     []Subscription
+	emails   []EmailSubscription
+}
+
+type runLease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// NewMemoryStore returns a new empty in memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nextID: 1}
+}
+
+type ballKey struct {
+	brand      Brand
+	name       string
+	approvedAt time.Time
+}
+
+// key mirrors the unique_brand_name_approved_at constraint, timestamps are compared at the database's microsecond
+// precision.
+func (b Ball) key() ballKey {
+	return ballKey{
+		brand:      b.Brand,
+		name:       b.Name,
+		approvedAt: b.ApprovalDate.Truncate(time.Microsecond).UTC(),
+	}
+}
+
+func (s *MemoryStore) AddBalls(_ context.Context, balls []Ball) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := make(map[ballKey]struct{}, len(s.balls)+len(balls))
+	for _, b := range s.balls {
+		existing[b.key()] = struct{}{}
+	}
+
+	added := make([]Ball, 0, len(balls))
+	for _, b := range balls {
+		k := b.key()
+		if _, ok := existing[k]; ok {
+			return fmt.Errorf("%w: %s %s approved %s", ErrDuplicateBall, b.Brand, b.Name, b.ApprovalDate)
+		}
+		existing[k] = struct{}{}
+
+		b.ID = s.nextID + len(added)
+		b.ApprovalDate = b.ApprovalDate.Truncate(time.Microsecond)
+		b.ImageURL = cloneURL(b.ImageURL)
+		// Keys aren't stored, the service computes them with its current alias rules.
+		b.CanonicalKey = ""
+		added = append(added, b)
+	}
+
+	if s.pending == nil {
+		s.pending = make(map[int]int)
+	}
+	for _, b := range added {
+		s.pending[b.ID] = 0
+	}
+
+	s.balls = append(s.balls, added...)
+	s.nextID += len(added)
+
+	return nil
+}
+
+func (s *MemoryStore) GetAllBalls(_ context.Context, filter BallFilter) ([]Ball, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var balls []Ball
+	for _, b := range s.balls {
+		if filter.Brand != nil && b.Brand != *filter.Brand {
+			continue
+		}
+		if filter.Manufacturer != nil && !slices.Contains(filter.Manufacturer.Brands(), b.Brand) {
+			continue
+		}
+		if filter.Name != nil && b.Name != *filter.Name {
+			continue
+		}
+		if filter.ApprovalDate != nil && !b.ApprovalDate.Equal(filter.ApprovalDate.Truncate(time.Microsecond)) {
+			continue
+		}
+
+		b.ImageURL = cloneURL(b.ImageURL)
+		balls = append(balls, b)
+	}
+
+	return balls, nil
+}
+
+func (s *MemoryStore) GetRecentBalls(ctx context.Context, filter BallFilter, limit int) ([]Ball, error) {
+	balls, err := s.GetAllBalls(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(balls, func(i, j int) bool {
+		if !balls[i].ApprovalDate.Equal(balls[j].ApprovalDate) {
+			return balls[i].ApprovalDate.After(balls[j].ApprovalDate)
+		}
+		return balls[i].ID > balls[j].ID
+	})
+
+	return balls[:min(len(balls), limit)], nil
+}
+
+func (s *MemoryStore) GetPendingBalls(_ context.Context, maxAttempts int) ([]Ball, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var balls []Ball
+	for _, b := range s.balls {
+		if attempts, ok := s.pending[b.ID]; !ok || attempts >= maxAttempts {
+			continue
+		}
+
+		b.ImageURL = cloneURL(b.ImageURL)
+		balls = append(balls, b)
+	}
+	sort.SliceStable(balls, func(i, j int) bool {
+		return balls[i].ApprovalDate.Before(balls[j].ApprovalDate)
+	})
+
+	return balls, nil
+}
+
+func (s *MemoryStore) MarkBallsNotified(_ context.Context, ids []int, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.pending, id)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) AddNotifyAttempt(_ context.Context, ids []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := s.pending[id]; ok {
+			s.pending[id]++
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) AddRun(_ context.Context, run Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.runs {
+		if r.ID == run.ID {
+			return fmt.Errorf("run %s already exists", run.ID)
+		}
+	}
+	s.runs = append(s.runs, run)
+
+	return nil
+}
+
+func (s *MemoryStore) GetLastSuccessfulRun(_ context.Context) (Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	successful := make([]Run, 0, len(s.runs))
+	for _, r := range s.runs {
+		if r.Succeeded() {
+			successful = append(successful, r)
+		}
+	}
+	if len(successful) == 0 {
+		return Run{}, ErrNotFound
+	}
+
+	sort.SliceStable(successful, func(i, j int) bool {
+		return successful[i].FinishedAt.After(successful[j].FinishedAt)
+	})
+
+	return successful[0], nil
+}
+
+func (s *MemoryStore) AcquireRunLease(_ context.Context, holder string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.lease != nil && !s.lease.expiresAt.Before(now) {
+		return false, nil
+	}
+	s.lease = &runLease{holder: holder, expiresAt: now.Add(ttl)}
+
+	return true, nil
+}
+
+func (s *MemoryStore) ReleaseRunLease(_ context.Context, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lease != nil && s.lease.holder == holder {
+		s.lease = nil
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) SearchBalls(_ context.Context, query string, limit int) ([]SearchResult, error) {
+	trigrams := nameTrigrams(NormalizeName(query))
+	if len(trigrams) == 0 {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []SearchResult
+	for _, b := range s.balls {
+		ballTrigrams := nameTrigrams(NormalizeName(b.Name))
+		score := trigramSimilarity(countSharedTrigrams(trigrams, ballTrigrams), len(trigrams), len(ballTrigrams))
+		if score < minSearchScore {
+			continue
+		}
+
+		b.ImageURL = cloneURL(b.ImageURL)
+		results = append(results, SearchResult{Ball: b, Score: score})
+	}
+
+	sortSearchResults(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// ReindexSearch is a no-op, the memory store computes the similarity of every ball when searching.
+func (s *MemoryStore) ReindexSearch(_ context.Context) (int, error) {
+	return 0, nil
+}
+
+func (s *MemoryStore) AddBallAlias(_ context.Context, alias BallAlias) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, a := range s.aliases {
+		if a.Brand == alias.Brand && a.Alias == alias.Alias {
+			s.aliases[i] = alias
+			return nil
+		}
+	}
+	s.aliases = append(s.aliases, alias)
+
+	return nil
+}
+
+func (s *MemoryStore) GetBallAliases(_ context.Context) ([]BallAlias, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]BallAlias(nil), s.aliases...), nil
+}
+
+func (s *MemoryStore) AddDigestBalls(_ context.Context, digest string, balls []Ball) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.digests == nil {
+		s.digests = make(map[string][]Ball)
+	}
+
+	existing := make(map[ballKey]struct{}, len(s.digests[digest])+len(balls))
+	for _, b := range s.digests[digest] {
+		existing[b.key()] = struct{}{}
+	}
+
+	for _, b := range balls {
+		if _, ok := existing[b.key()]; ok {
+			continue
+		}
+		existing[b.key()] = struct{}{}
+
+		b.ID = 0
+		b.ApprovalDate = b.ApprovalDate.Truncate(time.Microsecond)
+		b.ImageURL = cloneURL(b.ImageURL)
+		s.digests[digest] = append(s.digests[digest], b)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) TakeDigestBalls(_ context.Context, digest string) ([]Ball, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	balls := s.digests[digest]
+	delete(s.digests, digest)
+	sortDigestBalls(balls)
+
+	return balls, nil
+}
+
+func (s *MemoryStore) DisableChannel(_ context.Context, channel DisabledChannel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disabled == nil {
+		s.disabled = make(map[string]DisabledChannel)
+	}
+	channel.DisabledAt = channel.DisabledAt.Truncate(time.Microsecond)
+	s.disabled[channel.ChannelID] = channel
+
+	return nil
+}
+
+func (s *MemoryStore) EnableChannel(_ context.Context, channelID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.disabled, channelID)
+
+	return nil
+}
+
+func (s *MemoryStore) GetDisabledChannels(_ context.Context) ([]DisabledChannel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	channels := make([]DisabledChannel, 0, len(s.disabled))
+	for _, c := range s.disabled {
+		channels = append(channels, c)
+	}
+
+	return channels, nil
+}
+
+func (s *MemoryStore) AddBrandRole(_ context.Context, role BrandRole) (BrandRole, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.roles {
+		if r.sameTarget(role) {
+			return r, nil
+		}
+	}
+	s.roles = append(s.roles, role)
+
+	return role, nil
+}
+
+func (s *MemoryStore) RemoveBrandRole(_ context.Context, role BrandRole) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.roles = slices.DeleteFunc(s.roles, func(r BrandRole) bool {
+		return r == role
+	})
+
+	return nil
+}
+
+func (s *MemoryStore) GetBrandRoles(_ context.Context, guildID string) ([]BrandRole, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var roles []BrandRole
+	for _, r := range s.roles {
+		if r.GuildID == guildID {
+			roles = append(roles, r)
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		if roles[i].Brand != roles[j].Brand {
+			return roles[i].Brand < roles[j].Brand
+		}
+		return roles[i].Manufacturer < roles[j].Manufacturer
+	})
+
+	return roles, nil
+}
+
+func (s *MemoryStore) AddSubscription(_ context.Context, sub Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.subs {
+		if existing.sameFilter(sub) {
+			return nil
+		}
+	}
+	s.subs = append(s.subs, sub)
+
+	return nil
+}
+
+func (s *MemoryStore) RemoveSubscription(_ context.Context, sub Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subs = slices.DeleteFunc(s.subs, sub.sameFilter)
+
+	return nil
+}
+
+func (s *MemoryStore) RemoveUserSubscriptions(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subs = slices.DeleteFunc(s.subs, func(sub Subscription) bool {
+		return sub.UserID == userID
+	})
+
+	return nil
+}
+
+func (s *MemoryStore) GetSubscriptions(_ context.Context) ([]Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return sortedSubscriptions(slices.Clone(s.subs)), nil
+}
+
+func (s *MemoryStore) GetUserSubscriptions(_ context.Context, userID string) ([]Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var subs []Subscription
+	for _, sub := range s.subs {
+		if sub.UserID == userID {
+			subs = append(subs, sub)
+		}
+	}
+
+	return sortedSubscriptions(subs), nil
+}
+
+func (s *MemoryStore) AddEmailSubscription(_ context.Context, sub EmailSubscription) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.emails {
+		if existing.sameFilter(sub) {
+			return false, nil
+		}
+	}
+	sub.ConfirmedAt = time.Time{}
+	s.emails = append(s.emails, sub)
+
+	return true, nil
+}
+
+func (s *MemoryStore) ConfirmEmailSubscription(_ context.Context, token string, confirmedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, sub := range s.emails {
+		if sub.Token != token {
+			continue
+		}
+		if sub.ConfirmedAt.IsZero() {
+			s.emails[i].ConfirmedAt = confirmedAt
+		}
+		return nil
+	}
+
+	return ErrNotFound
+}
+
+func (s *MemoryStore) RemoveEmailSubscription(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.emails)
+	s.emails = slices.DeleteFunc(s.emails, func(sub EmailSubscription) bool {
+		return sub.Token == token
+	})
+	if len(s.emails) == n {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) GetEmailSubscriptions(_ context.Context) ([]EmailSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subs := slices.DeleteFunc(slices.Clone(s.emails), func(sub EmailSubscription) bool {
+		return sub.ConfirmedAt.IsZero()
+	})
+	sort.Slice(subs, func(i, j int) bool {
+		a, b := subs[i], subs[j]
+		if a.Email != b.Email {
+			return a.Email < b.Email
+		}
+		if a.Brand != b.Brand {
+			return a.Brand < b.Brand
+		}
+		if a.Manufacturer != b.Manufacturer {
+			return a.Manufacturer < b.Manufacturer
+		}
+		return a.Pattern < b.Pattern
+	})
+
+	return subs, nil
+}
+
+func (s *MemoryStore) GetAddressEmailSubscriptions(_ context.Context, email string) ([]EmailSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var subs []EmailSubscription
+	for _, sub := range s.emails {
+		if sub.Email == email {
+			subs = append(subs, sub)
+		}
+	}
+	sort.SliceStable(subs, func(i, j int) bool {
+		return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+	})
+
+	return subs, nil
+}
+
+func (s *MemoryStore) RemovePendingEmailSubscriptions(_ context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.emails)
+	s.emails = slices.DeleteFunc(s.emails, func(sub EmailSubscription) bool {
+		return sub.ConfirmedAt.IsZero() && sub.CreatedAt.Before(before)
+	})
+
+	return n - len(s.emails), nil
+}
+
+func sortedSubscriptions(subs []Subscription) []Subscription {
+	sort.Slice(subs, func(i, j int) bool {
+		a, b := subs[i], subs[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		if a.Brand != b.Brand {
+			return a.Brand < b.Brand
+		}
+		if a.Manufacturer != b.Manufacturer {
+			return a.Manufacturer < b.Manufacturer
+		}
+		return a.Pattern < b.Pattern
+	})
+
+	return subs
+}
+
+func cloneURL(u *url.URL) *url.URL {
+	if u == nil {
+		return nil
+	}
+	c := *u
+	return &c
+}
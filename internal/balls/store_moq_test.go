@@ -6,6 +6,7 @@ package balls
 import (
 	"context"
 	"sync"
+	"time"
 )
 
 // Ensure, that StoreMock does implement Store.
@@ -18,12 +19,105 @@ var _ Store = &StoreMock{}
 //
 //		// make and configure a mocked Store
 //		mockedStore := &StoreMock{
+//			AcquireRunLeaseFunc: func(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+//				panic("mock out the AcquireRunLease method")
+//			},
+//			AddBallAliasFunc: func(ctx context.Context, alias BallAlias) error {
+//				panic("mock out the AddBallAlias method")
+//			},
 //			AddBallsFunc: func(ctx context.Context, balls []Ball) error {
 //				panic("mock out the AddBalls method")
 //			},
+//			AddBrandRoleFunc: func(ctx context.Context, role BrandRole) (BrandRole, error) {
+//				panic("mock out the AddBrandRole method")
+//			},
+//			AddDigestBallsFunc: func(ctx context.Context, digest string, balls []Ball) error {
+//				panic("mock out the AddDigestBalls method")
+//			},
+//			AddEmailSubscriptionFunc: func(ctx context.Context, sub EmailSubscription) (bool, error) {
+//				panic("mock out the AddEmailSubscription method")
+//			},
+//			AddNotifyAttemptFunc: func(ctx context.Context, ids []int) error {
+//				panic("mock out the AddNotifyAttempt method")
+//			},
+//			AddRunFunc: func(ctx context.Context, run Run) error {
+//				panic("mock out the AddRun method")
+//			},
+//			AddSubscriptionFunc: func(ctx context.Context, sub Subscription) error {
+//				panic("mock out the AddSubscription method")
+//			},
+//			ConfirmEmailSubscriptionFunc: func(ctx context.Context, token string, confirmedAt time.Time) error {
+//				panic("mock out the ConfirmEmailSubscription method")
+//			},
+//			DisableChannelFunc: func(ctx context.Context, channel DisabledChannel) error {
+//				panic("mock out the DisableChannel method")
+//			},
+//			EnableChannelFunc: func(ctx context.Context, channelID string) error {
+//				panic("mock out the EnableChannel method")
+//			},
+//			GetAddressEmailSubscriptionsFunc: func(ctx context.Context, email string) ([]EmailSubscription, error) {
+//				panic("mock out the GetAddressEmailSubscriptions method")
+//			},
 //			GetAllBallsFunc: func(ctx context.Context, filter BallFilter) ([]Ball, error) {
 //				panic("mock out the GetAllBalls method")
 //			},
+//			GetBallAliasesFunc: func(ctx context.Context) ([]BallAlias, error) {
+//				panic("mock out the GetBallAliases method")
+//			},
+//			GetBrandRolesFunc: func(ctx context.Context, guildID string) ([]BrandRole, error) {
+//				panic("mock out the GetBrandRoles method")
+//			},
+//			GetDisabledChannelsFunc: func(ctx context.Context) ([]DisabledChannel, error) {
+//				panic("mock out the GetDisabledChannels method")
+//			},
+//			GetEmailSubscriptionsFunc: func(ctx context.Context) ([]EmailSubscription, error) {
+//				panic("mock out the GetEmailSubscriptions method")
+//			},
+//			GetLastSuccessfulRunFunc: func(ctx context.Context) (Run, error) {
+//				panic("mock out the GetLastSuccessfulRun method")
+//			},
+//			GetPendingBallsFunc: func(ctx context.Context, maxAttempts int) ([]Ball, error) {
+//				panic("mock out the GetPendingBalls method")
+//			},
+//			GetRecentBallsFunc: func(ctx context.Context, filter BallFilter, limit int) ([]Ball, error) {
+//				panic("mock out the GetRecentBalls method")
+//			},
+//			GetSubscriptionsFunc: func(ctx context.Context) ([]Subscription, error) {
+//				panic("mock out the GetSubscriptions method")
+//			},
+//			GetUserSubscriptionsFunc: func(ctx context.Context, userID string) ([]Subscription, error) {
+//				panic("mock out the GetUserSubscriptions method")
+//			},
+//			MarkBallsNotifiedFunc: func(ctx context.Context, ids []int, notifiedAt time.Time) error {
+//				panic("mock out the MarkBallsNotified method")
+//			},
+//			ReindexSearchFunc: func(ctx context.Context) (int, error) {
+//				panic("mock out the ReindexSearch method")
+//			},
+//			ReleaseRunLeaseFunc: func(ctx context.Context, holder string) error {
+//				panic("mock out the ReleaseRunLease method")
+//			},
+//			RemoveBrandRoleFunc: func(ctx context.Context, role BrandRole) error {
+//				panic("mock out the RemoveBrandRole method")
+//			},
+//			RemoveEmailSubscriptionFunc: func(ctx context.Context, token string) error {
+//				panic("mock out the RemoveEmailSubscription method")
+//			},
+//			RemovePendingEmailSubscriptionsFunc: func(ctx context.Context, before time.Time) (int, error) {
+//				panic("mock out the RemovePendingEmailSubscriptions method")
+//			},
+//			RemoveSubscriptionFunc: func(ctx context.Context, sub Subscription) error {
+//				panic("mock out the RemoveSubscription method")
+//			},
+//			RemoveUserSubscriptionsFunc: func(ctx context.Context, userID string) error {
+//				panic("mock out the RemoveUserSubscriptions method")
+//			},
+//			SearchBallsFunc: func(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+//				panic("mock out the SearchBalls method")
+//			},
+//			TakeDigestBallsFunc: func(ctx context.Context, digest string) ([]Ball, error) {
+//				panic("mock out the TakeDigestBalls method")
+//			},
 //		}
 //
 //		// use mockedStore in code that requires Store
@@ -31,14 +125,123 @@ var _ Store = &StoreMock{}
 //
 //	}
 type StoreMock struct {
+	// AcquireRunLeaseFunc mocks the AcquireRunLease method.
+	AcquireRunLeaseFunc func(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+
+	// AddBallAliasFunc mocks the AddBallAlias method.
+	AddBallAliasFunc func(ctx context.Context, alias BallAlias) error
+
 	// AddBallsFunc mocks the AddBalls method.
 	AddBallsFunc func(ctx context.Context, balls []Ball) error
 
+	// AddBrandRoleFunc mocks the AddBrandRole method.
+	AddBrandRoleFunc func(ctx context.Context, role BrandRole) (BrandRole, error)
+
+	// AddDigestBallsFunc mocks the AddDigestBalls method.
+	AddDigestBallsFunc func(ctx context.Context, digest string, balls []Ball) error
+
+	// AddEmailSubscriptionFunc mocks the AddEmailSubscription method.
+	AddEmailSubscriptionFunc func(ctx context.Context, sub EmailSubscription) (bool, error)
+
+	// AddNotifyAttemptFunc mocks the AddNotifyAttempt method.
+	AddNotifyAttemptFunc func(ctx context.Context, ids []int) error
+
+	// AddRunFunc mocks the AddRun method.
+	AddRunFunc func(ctx context.Context, run Run) error
+
+	// AddSubscriptionFunc mocks the AddSubscription method.
+	AddSubscriptionFunc func(ctx context.Context, sub Subscription) error
+
+	// ConfirmEmailSubscriptionFunc mocks the ConfirmEmailSubscription method.
+	ConfirmEmailSubscriptionFunc func(ctx context.Context, token string, confirmedAt time.Time) error
+
+	// DisableChannelFunc mocks the DisableChannel method.
+	DisableChannelFunc func(ctx context.Context, channel DisabledChannel) error
+
+	// EnableChannelFunc mocks the EnableChannel method.
+	EnableChannelFunc func(ctx context.Context, channelID string) error
+
+	// GetAddressEmailSubscriptionsFunc mocks the GetAddressEmailSubscriptions method.
+	GetAddressEmailSubscriptionsFunc func(ctx context.Context, email string) ([]EmailSubscription, error)
+
 	// GetAllBallsFunc mocks the GetAllBalls method.
 	GetAllBallsFunc func(ctx context.Context, filter BallFilter) ([]Ball, error)
 
+	// GetBallAliasesFunc mocks the GetBallAliases method.
+	GetBallAliasesFunc func(ctx context.Context) ([]BallAlias, error)
+
+	// GetBrandRolesFunc mocks the GetBrandRoles method.
+	GetBrandRolesFunc func(ctx context.Context, guildID string) ([]BrandRole, error)
+
+	// GetDisabledChannelsFunc mocks the GetDisabledChannels method.
+	GetDisabledChannelsFunc func(ctx context.Context) ([]DisabledChannel, error)
+
+	// GetEmailSubscriptionsFunc mocks the GetEmailSubscriptions method.
+	GetEmailSubscriptionsFunc func(ctx context.Context) ([]EmailSubscription, error)
+
+	// GetLastSuccessfulRunFunc mocks the GetLastSuccessfulRun method.
+	GetLastSuccessfulRunFunc func(ctx context.Context) (Run, error)
+
+	// GetPendingBallsFunc mocks the GetPendingBalls method.
+	GetPendingBallsFunc func(ctx context.Context, maxAttempts int) ([]Ball, error)
+
+	// GetRecentBallsFunc mocks the GetRecentBalls method.
+	GetRecentBallsFunc func(ctx context.Context, filter BallFilter, limit int) ([]Ball, error)
+
+	// GetSubscriptionsFunc mocks the GetSubscriptions method.
+	GetSubscriptionsFunc func(ctx context.Context) ([]Subscription, error)
+
+	// GetUserSubscriptionsFunc mocks the GetUserSubscriptions method.
+	GetUserSubscriptionsFunc func(ctx context.Context, userID string) ([]Subscription, error)
+
+	// MarkBallsNotifiedFunc mocks the MarkBallsNotified method.
+	MarkBallsNotifiedFunc func(ctx context.Context, ids []int, notifiedAt time.Time) error
+
+	// ReindexSearchFunc mocks the ReindexSearch method.
+	ReindexSearchFunc func(ctx context.Context) (int, error)
+
+	// ReleaseRunLeaseFunc mocks the ReleaseRunLease method.
+	ReleaseRunLeaseFunc func(ctx context.Context, holder string) error
+
+	// RemoveBrandRoleFunc mocks the RemoveBrandRole method.
+	RemoveBrandRoleFunc func(ctx context.Context, role BrandRole) error
+
+	// RemoveEmailSubscriptionFunc mocks the RemoveEmailSubscription method.
+	RemoveEmailSubscriptionFunc func(ctx context.Context, token string) error
+
+	// RemovePendingEmailSubscriptionsFunc mocks the RemovePendingEmailSubscriptions method.
+	RemovePendingEmailSubscriptionsFunc func(ctx context.Context, before time.Time) (int, error)
+
+	// RemoveSubscriptionFunc mocks the RemoveSubscription method.
+	RemoveSubscriptionFunc func(ctx context.Context, sub Subscription) error
+
+	// RemoveUserSubscriptionsFunc mocks the RemoveUserSubscriptions method.
+	RemoveUserSubscriptionsFunc func(ctx context.Context, userID string) error
+
+	// SearchBallsFunc mocks the SearchBalls method.
+	SearchBallsFunc func(ctx context.Context, query string, limit int) ([]SearchResult, error)
+
+	// TakeDigestBallsFunc mocks the TakeDigestBalls method.
+	TakeDigestBallsFunc func(ctx context.Context, digest string) ([]Ball, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// AcquireRunLease holds details about calls to the AcquireRunLease method.
+		AcquireRunLease []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Holder is the holder argument value.
+			Holder string
+			// TTL is the ttl argument value.
+			TTL time.Duration
+		}
+		// AddBallAlias holds details about calls to the AddBallAlias method.
+		AddBallAlias []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Alias is the alias argument value.
+			Alias BallAlias
+		}
 		// AddBalls holds details about calls to the AddBalls method.
 		AddBalls []struct {
 			// Ctx is the ctx argument value.
@@ -46,6 +249,80 @@ type StoreMock struct {
 			// Balls is the balls argument value.
 			Balls []Ball
 		}
+		// AddBrandRole holds details about calls to the AddBrandRole method.
+		AddBrandRole []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Role is the role argument value.
+			Role BrandRole
+		}
+		// AddDigestBalls holds details about calls to the AddDigestBalls method.
+		AddDigestBalls []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Digest is the digest argument value.
+			Digest string
+			// Balls is the balls argument value.
+			Balls []Ball
+		}
+		// AddEmailSubscription holds details about calls to the AddEmailSubscription method.
+		AddEmailSubscription []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Sub is the sub argument value.
+			Sub EmailSubscription
+		}
+		// AddNotifyAttempt holds details about calls to the AddNotifyAttempt method.
+		AddNotifyAttempt []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ids is the ids argument value.
+			Ids []int
+		}
+		// AddRun holds details about calls to the AddRun method.
+		AddRun []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Run is the run argument value.
+			Run Run
+		}
+		// AddSubscription holds details about calls to the AddSubscription method.
+		AddSubscription []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Sub is the sub argument value.
+			Sub Subscription
+		}
+		// ConfirmEmailSubscription holds details about calls to the ConfirmEmailSubscription method.
+		ConfirmEmailSubscription []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Token is the token argument value.
+			Token string
+			// ConfirmedAt is the confirmedAt argument value.
+			ConfirmedAt time.Time
+		}
+		// DisableChannel holds details about calls to the DisableChannel method.
+		DisableChannel []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Channel is the channel argument value.
+			Channel DisabledChannel
+		}
+		// EnableChannel holds details about calls to the EnableChannel method.
+		EnableChannel []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ChannelID is the channelID argument value.
+			ChannelID string
+		}
+		// GetAddressEmailSubscriptions holds details about calls to the GetAddressEmailSubscriptions method.
+		GetAddressEmailSubscriptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Email is the email argument value.
+			Email string
+		}
 		// GetAllBalls holds details about calls to the GetAllBalls method.
 		GetAllBalls []struct {
 			// Ctx is the ctx argument value.
@@ -53,9 +330,243 @@ type StoreMock struct {
 			// Filter is the filter argument value.
 			Filter BallFilter
 		}
+		// GetBallAliases holds details about calls to the GetBallAliases method.
+		GetBallAliases []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetBrandRoles holds details about calls to the GetBrandRoles method.
+		GetBrandRoles []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// GuildID is the guildID argument value.
+			GuildID string
+		}
+		// GetDisabledChannels holds details about calls to the GetDisabledChannels method.
+		GetDisabledChannels []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetEmailSubscriptions holds details about calls to the GetEmailSubscriptions method.
+		GetEmailSubscriptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetLastSuccessfulRun holds details about calls to the GetLastSuccessfulRun method.
+		GetLastSuccessfulRun []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetPendingBalls holds details about calls to the GetPendingBalls method.
+		GetPendingBalls []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// MaxAttempts is the maxAttempts argument value.
+			MaxAttempts int
+		}
+		// GetRecentBalls holds details about calls to the GetRecentBalls method.
+		GetRecentBalls []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filter is the filter argument value.
+			Filter BallFilter
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// GetSubscriptions holds details about calls to the GetSubscriptions method.
+		GetSubscriptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// GetUserSubscriptions holds details about calls to the GetUserSubscriptions method.
+		GetUserSubscriptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserID is the userID argument value.
+			UserID string
+		}
+		// MarkBallsNotified holds details about calls to the MarkBallsNotified method.
+		MarkBallsNotified []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ids is the ids argument value.
+			Ids []int
+			// NotifiedAt is the notifiedAt argument value.
+			NotifiedAt time.Time
+		}
+		// ReindexSearch holds details about calls to the ReindexSearch method.
+		ReindexSearch []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// ReleaseRunLease holds details about calls to the ReleaseRunLease method.
+		ReleaseRunLease []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Holder is the holder argument value.
+			Holder string
+		}
+		// RemoveBrandRole holds details about calls to the RemoveBrandRole method.
+		RemoveBrandRole []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Role is the role argument value.
+			Role BrandRole
+		}
+		// RemoveEmailSubscription holds details about calls to the RemoveEmailSubscription method.
+		RemoveEmailSubscription []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Token is the token argument value.
+			Token string
+		}
+		// RemovePendingEmailSubscriptions holds details about calls to the RemovePendingEmailSubscriptions method.
+		RemovePendingEmailSubscriptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Before is the before argument value.
+			Before time.Time
+		}
+		// RemoveSubscription holds details about calls to the RemoveSubscription method.
+		RemoveSubscription []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Sub is the sub argument value.
+			Sub Subscription
+		}
+		// RemoveUserSubscriptions holds details about calls to the RemoveUserSubscriptions method.
+		RemoveUserSubscriptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserID is the userID argument value.
+			UserID string
+		}
+		// SearchBalls holds details about calls to the SearchBalls method.
+		SearchBalls []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Query is the query argument value.
+			Query string
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// TakeDigestBalls holds details about calls to the TakeDigestBalls method.
+		TakeDigestBalls []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Digest is the digest argument value.
+			Digest string
+		}
+	}
+	lockAcquireRunLease                 sync.RWMutex
+	lockAddBallAlias                    sync.RWMutex
+	lockAddBalls                        sync.RWMutex
+	lockAddBrandRole                    sync.RWMutex
+	lockAddDigestBalls                  sync.RWMutex
+	lockAddEmailSubscription            sync.RWMutex
+	lockAddNotifyAttempt                sync.RWMutex
+	lockAddRun                          sync.RWMutex
+	lockAddSubscription                 sync.RWMutex
+	lockConfirmEmailSubscription        sync.RWMutex
+	lockDisableChannel                  sync.RWMutex
+	lockEnableChannel                   sync.RWMutex
+	lockGetAddressEmailSubscriptions    sync.RWMutex
+	lockGetAllBalls                     sync.RWMutex
+	lockGetBallAliases                  sync.RWMutex
+	lockGetBrandRoles                   sync.RWMutex
+	lockGetDisabledChannels             sync.RWMutex
+	lockGetEmailSubscriptions           sync.RWMutex
+	lockGetLastSuccessfulRun            sync.RWMutex
+	lockGetPendingBalls                 sync.RWMutex
+	lockGetRecentBalls                  sync.RWMutex
+	lockGetSubscriptions                sync.RWMutex
+	lockGetUserSubscriptions            sync.RWMutex
+	lockMarkBallsNotified               sync.RWMutex
+	lockReindexSearch                   sync.RWMutex
+	lockReleaseRunLease                 sync.RWMutex
+	lockRemoveBrandRole                 sync.RWMutex
+	lockRemoveEmailSubscription         sync.RWMutex
+	lockRemovePendingEmailSubscriptions sync.RWMutex
+	lockRemoveSubscription              sync.RWMutex
+	lockRemoveUserSubscriptions         sync.RWMutex
+	lockSearchBalls                     sync.RWMutex
+	lockTakeDigestBalls                 sync.RWMutex
+}
+
+// AcquireRunLease calls AcquireRunLeaseFunc.
+func (mock *StoreMock) AcquireRunLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	if mock.AcquireRunLeaseFunc == nil {
+		panic("StoreMock.AcquireRunLeaseFunc: method is nil but Store.AcquireRunLease was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Holder string
+		TTL    time.Duration
+	}{
+		Ctx:    ctx,
+		Holder: holder,
+		TTL:    ttl,
+	}
+	mock.lockAcquireRunLease.Lock()
+	mock.calls.AcquireRunLease = append(mock.calls.AcquireRunLease, callInfo)
+	mock.lockAcquireRunLease.Unlock()
+	return mock.AcquireRunLeaseFunc(ctx, holder, ttl)
+}
+
+// AcquireRunLeaseCalls gets all the calls that were made to AcquireRunLease.
+// Check the length with:
+//
+//	len(mockedStore.AcquireRunLeaseCalls())
+func (mock *StoreMock) AcquireRunLeaseCalls() []struct {
+	Ctx    context.Context
+	Holder string
+	TTL    time.Duration
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Holder string
+		TTL    time.Duration
+	}
+	mock.lockAcquireRunLease.RLock()
+	calls = mock.calls.AcquireRunLease
+	mock.lockAcquireRunLease.RUnlock()
+	return calls
+}
+
+// AddBallAlias calls AddBallAliasFunc.
+func (mock *StoreMock) AddBallAlias(ctx context.Context, alias BallAlias) error {
+	if mock.AddBallAliasFunc == nil {
+		panic("StoreMock.AddBallAliasFunc: method is nil but Store.AddBallAlias was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Alias BallAlias
+	}{
+		Ctx:   ctx,
+		Alias: alias,
+	}
+	mock.lockAddBallAlias.Lock()
+	mock.calls.AddBallAlias = append(mock.calls.AddBallAlias, callInfo)
+	mock.lockAddBallAlias.Unlock()
+	return mock.AddBallAliasFunc(ctx, alias)
+}
+
+// AddBallAliasCalls gets all the calls that were made to AddBallAlias.
+// Check the length with:
+//
+//	len(mockedStore.AddBallAliasCalls())
+func (mock *StoreMock) AddBallAliasCalls() []struct {
+	Ctx   context.Context
+	Alias BallAlias
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Alias BallAlias
 	}
-	lockAddBalls    sync.RWMutex
-	lockGetAllBalls sync.RWMutex
+	mock.lockAddBallAlias.RLock()
+	calls = mock.calls.AddBallAlias
+	mock.lockAddBallAlias.RUnlock()
+	return calls
 }
 
 // AddBalls calls AddBallsFunc.
@@ -94,38 +605,1078 @@ func (mock *StoreMock) AddBallsCalls() []struct {
 	return calls
 }
 
-// GetAllBalls calls GetAllBallsFunc.
-func (mock *StoreMock) GetAllBalls(ctx context.Context, filter BallFilter) ([]Ball, error) {
-	if mock.GetAllBallsFunc == nil {
-		panic("StoreMock.GetAllBallsFunc: method is nil but Store.GetAllBalls was just called")
+// AddBrandRole calls AddBrandRoleFunc.
+func (mock *StoreMock) AddBrandRole(ctx context.Context, role BrandRole) (BrandRole, error) {
+	if mock.AddBrandRoleFunc == nil {
+		panic("StoreMock.AddBrandRoleFunc: method is nil but Store.AddBrandRole was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Role BrandRole
+	}{
+		Ctx:  ctx,
+		Role: role,
+	}
+	mock.lockAddBrandRole.Lock()
+	mock.calls.AddBrandRole = append(mock.calls.AddBrandRole, callInfo)
+	mock.lockAddBrandRole.Unlock()
+	return mock.AddBrandRoleFunc(ctx, role)
+}
+
+// AddBrandRoleCalls gets all the calls that were made to AddBrandRole.
+// Check the length with:
+//
+//	len(mockedStore.AddBrandRoleCalls())
+func (mock *StoreMock) AddBrandRoleCalls() []struct {
+	Ctx  context.Context
+	Role BrandRole
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Role BrandRole
+	}
+	mock.lockAddBrandRole.RLock()
+	calls = mock.calls.AddBrandRole
+	mock.lockAddBrandRole.RUnlock()
+	return calls
+}
+
+// AddDigestBalls calls AddDigestBallsFunc.
+func (mock *StoreMock) AddDigestBalls(ctx context.Context, digest string, balls []Ball) error {
+	if mock.AddDigestBallsFunc == nil {
+		panic("StoreMock.AddDigestBallsFunc: method is nil but Store.AddDigestBalls was just called")
 	}
 	callInfo := struct {
 		Ctx    context.Context
-		Filter BallFilter
+		Digest string
+		Balls  []Ball
 	}{
 		Ctx:    ctx,
-		Filter: filter,
+		Digest: digest,
+		Balls:  balls,
 	}
-	mock.lockGetAllBalls.Lock()
-	mock.calls.GetAllBalls = append(mock.calls.GetAllBalls, callInfo)
-	mock.lockGetAllBalls.Unlock()
-	return mock.GetAllBallsFunc(ctx, filter)
+	mock.lockAddDigestBalls.Lock()
+	mock.calls.AddDigestBalls = append(mock.calls.AddDigestBalls, callInfo)
+	mock.lockAddDigestBalls.Unlock()
+	return mock.AddDigestBallsFunc(ctx, digest, balls)
 }
 
-// GetAllBallsCalls gets all the calls that were made to GetAllBalls.
+// AddDigestBallsCalls gets all the calls that were made to AddDigestBalls.
 // Check the length with:
 //
-//	len(mockedStore.GetAllBallsCalls())
-func (mock *StoreMock) GetAllBallsCalls() []struct {
+//	len(mockedStore.AddDigestBallsCalls())
+func (mock *StoreMock) AddDigestBallsCalls() []struct {
 	Ctx    context.Context
-	Filter BallFilter
+	Digest string
+	Balls  []Ball
 } {
 	var calls []struct {
 		Ctx    context.Context
-		Filter BallFilter
+		Digest string
+		Balls  []Ball
 	}
-	mock.lockGetAllBalls.RLock()
-	calls = mock.calls.GetAllBalls
-	mock.lockGetAllBalls.RUnlock()
+	mock.lockAddDigestBalls.RLock()
+	calls = mock.calls.AddDigestBalls
+	mock.lockAddDigestBalls.RUnlock()
+	return calls
+}
+
+// AddEmailSubscription calls AddEmailSubscriptionFunc.
+func (mock *StoreMock) AddEmailSubscription(ctx context.Context, sub EmailSubscription) (bool, error) {
+	if mock.AddEmailSubscriptionFunc == nil {
+		panic("StoreMock.AddEmailSubscriptionFunc: method is nil but Store.AddEmailSubscription was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Sub EmailSubscription
+	}{
+		Ctx: ctx,
+		Sub: sub,
+	}
+	mock.lockAddEmailSubscription.Lock()
+	mock.calls.AddEmailSubscription = append(mock.calls.AddEmailSubscription, callInfo)
+	mock.lockAddEmailSubscription.Unlock()
+	return mock.AddEmailSubscriptionFunc(ctx, sub)
+}
+
+// AddEmailSubscriptionCalls gets all the calls that were made to AddEmailSubscription.
+// Check the length with:
+//
+//	len(mockedStore.AddEmailSubscriptionCalls())
+func (mock *StoreMock) AddEmailSubscriptionCalls() []struct {
+	Ctx context.Context
+	Sub EmailSubscription
+} {
+	var calls []struct {
+		Ctx context.Context
+		Sub EmailSubscription
+	}
+	mock.lockAddEmailSubscription.RLock()
+	calls = mock.calls.AddEmailSubscription
+	mock.lockAddEmailSubscription.RUnlock()
+	return calls
+}
+
+// AddNotifyAttempt calls AddNotifyAttemptFunc.
+func (mock *StoreMock) AddNotifyAttempt(ctx context.Context, ids []int) error {
+	if mock.AddNotifyAttemptFunc == nil {
+		panic("StoreMock.AddNotifyAttemptFunc: method is nil but Store.AddNotifyAttempt was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ids []int
+	}{
+		Ctx: ctx,
+		Ids: ids,
+	}
+	mock.lockAddNotifyAttempt.Lock()
+	mock.calls.AddNotifyAttempt = append(mock.calls.AddNotifyAttempt, callInfo)
+	mock.lockAddNotifyAttempt.Unlock()
+	return mock.AddNotifyAttemptFunc(ctx, ids)
+}
+
+// AddNotifyAttemptCalls gets all the calls that were made to AddNotifyAttempt.
+// Check the length with:
+//
+//	len(mockedStore.AddNotifyAttemptCalls())
+func (mock *StoreMock) AddNotifyAttemptCalls() []struct {
+	Ctx context.Context
+	Ids []int
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ids []int
+	}
+	mock.lockAddNotifyAttempt.RLock()
+	calls = mock.calls.AddNotifyAttempt
+	mock.lockAddNotifyAttempt.RUnlock()
+	return calls
+}
+
+// AddRun calls AddRunFunc.
+func (mock *StoreMock) AddRun(ctx context.Context, run Run) error {
+	if mock.AddRunFunc == nil {
+		panic("StoreMock.AddRunFunc: method is nil but Store.AddRun was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Run Run
+	}{
+		Ctx: ctx,
+		Run: run,
+	}
+	mock.lockAddRun.Lock()
+	mock.calls.AddRun = append(mock.calls.AddRun, callInfo)
+	mock.lockAddRun.Unlock()
+	return mock.AddRunFunc(ctx, run)
+}
+
+// AddRunCalls gets all the calls that were made to AddRun.
+// Check the length with:
+//
+//	len(mockedStore.AddRunCalls())
+func (mock *StoreMock) AddRunCalls() []struct {
+	Ctx context.Context
+	Run Run
+} {
+	var calls []struct {
+		Ctx context.Context
+		Run Run
+	}
+	mock.lockAddRun.RLock()
+	calls = mock.calls.AddRun
+	mock.lockAddRun.RUnlock()
+	return calls
+}
+
+// AddSubscription calls AddSubscriptionFunc.
+func (mock *StoreMock) AddSubscription(ctx context.Context, sub Subscription) error {
+	if mock.AddSubscriptionFunc == nil {
+		panic("StoreMock.AddSubscriptionFunc: method is nil but Store.AddSubscription was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Sub Subscription
+	}{
+		Ctx: ctx,
+		Sub: sub,
+	}
+	mock.lockAddSubscription.Lock()
+	mock.calls.AddSubscription = append(mock.calls.AddSubscription, callInfo)
+	mock.lockAddSubscription.Unlock()
+	return mock.AddSubscriptionFunc(ctx, sub)
+}
+
+// AddSubscriptionCalls gets all the calls that were made to AddSubscription.
+// Check the length with:
+//
+//	len(mockedStore.AddSubscriptionCalls())
+func (mock *StoreMock) AddSubscriptionCalls() []struct {
+	Ctx context.Context
+	Sub Subscription
+} {
+	var calls []struct {
+		Ctx context.Context
+		Sub Subscription
+	}
+	mock.lockAddSubscription.RLock()
+	calls = mock.calls.AddSubscription
+	mock.lockAddSubscription.RUnlock()
+	return calls
+}
+
+// ConfirmEmailSubscription calls ConfirmEmailSubscriptionFunc.
+func (mock *StoreMock) ConfirmEmailSubscription(ctx context.Context, token string, confirmedAt time.Time) error {
+	if mock.ConfirmEmailSubscriptionFunc == nil {
+		panic("StoreMock.ConfirmEmailSubscriptionFunc: method is nil but Store.ConfirmEmailSubscription was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		Token       string
+		ConfirmedAt time.Time
+	}{
+		Ctx:         ctx,
+		Token:       token,
+		ConfirmedAt: confirmedAt,
+	}
+	mock.lockConfirmEmailSubscription.Lock()
+	mock.calls.ConfirmEmailSubscription = append(mock.calls.ConfirmEmailSubscription, callInfo)
+	mock.lockConfirmEmailSubscription.Unlock()
+	return mock.ConfirmEmailSubscriptionFunc(ctx, token, confirmedAt)
+}
+
+// ConfirmEmailSubscriptionCalls gets all the calls that were made to ConfirmEmailSubscription.
+// Check the length with:
+//
+//	len(mockedStore.ConfirmEmailSubscriptionCalls())
+func (mock *StoreMock) ConfirmEmailSubscriptionCalls() []struct {
+	Ctx         context.Context
+	Token       string
+	ConfirmedAt time.Time
+} {
+	var calls []struct {
+		Ctx         context.Context
+		Token       string
+		ConfirmedAt time.Time
+	}
+	mock.lockConfirmEmailSubscription.RLock()
+	calls = mock.calls.ConfirmEmailSubscription
+	mock.lockConfirmEmailSubscription.RUnlock()
+	return calls
+}
+
+// DisableChannel calls DisableChannelFunc.
+func (mock *StoreMock) DisableChannel(ctx context.Context, channel DisabledChannel) error {
+	if mock.DisableChannelFunc == nil {
+		panic("StoreMock.DisableChannelFunc: method is nil but Store.DisableChannel was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Channel DisabledChannel
+	}{
+		Ctx:     ctx,
+		Channel: channel,
+	}
+	mock.lockDisableChannel.Lock()
+	mock.calls.DisableChannel = append(mock.calls.DisableChannel, callInfo)
+	mock.lockDisableChannel.Unlock()
+	return mock.DisableChannelFunc(ctx, channel)
+}
+
+// DisableChannelCalls gets all the calls that were made to DisableChannel.
+// Check the length with:
+//
+//	len(mockedStore.DisableChannelCalls())
+func (mock *StoreMock) DisableChannelCalls() []struct {
+	Ctx     context.Context
+	Channel DisabledChannel
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Channel DisabledChannel
+	}
+	mock.lockDisableChannel.RLock()
+	calls = mock.calls.DisableChannel
+	mock.lockDisableChannel.RUnlock()
+	return calls
+}
+
+// EnableChannel calls EnableChannelFunc.
+func (mock *StoreMock) EnableChannel(ctx context.Context, channelID string) error {
+	if mock.EnableChannelFunc == nil {
+		panic("StoreMock.EnableChannelFunc: method is nil but Store.EnableChannel was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		ChannelID string
+	}{
+		Ctx:       ctx,
+		ChannelID: channelID,
+	}
+	mock.lockEnableChannel.Lock()
+	mock.calls.EnableChannel = append(mock.calls.EnableChannel, callInfo)
+	mock.lockEnableChannel.Unlock()
+	return mock.EnableChannelFunc(ctx, channelID)
+}
+
+// EnableChannelCalls gets all the calls that were made to EnableChannel.
+// Check the length with:
+//
+//	len(mockedStore.EnableChannelCalls())
+func (mock *StoreMock) EnableChannelCalls() []struct {
+	Ctx       context.Context
+	ChannelID string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		ChannelID string
+	}
+	mock.lockEnableChannel.RLock()
+	calls = mock.calls.EnableChannel
+	mock.lockEnableChannel.RUnlock()
+	return calls
+}
+
+// GetAddressEmailSubscriptions calls GetAddressEmailSubscriptionsFunc.
+func (mock *StoreMock) GetAddressEmailSubscriptions(ctx context.Context, email string) ([]EmailSubscription, error) {
+	if mock.GetAddressEmailSubscriptionsFunc == nil {
+		panic("StoreMock.GetAddressEmailSubscriptionsFunc: method is nil but Store.GetAddressEmailSubscriptions was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Email string
+	}{
+		Ctx:   ctx,
+		Email: email,
+	}
+	mock.lockGetAddressEmailSubscriptions.Lock()
+	mock.calls.GetAddressEmailSubscriptions = append(mock.calls.GetAddressEmailSubscriptions, callInfo)
+	mock.lockGetAddressEmailSubscriptions.Unlock()
+	return mock.GetAddressEmailSubscriptionsFunc(ctx, email)
+}
+
+// GetAddressEmailSubscriptionsCalls gets all the calls that were made to GetAddressEmailSubscriptions.
+// Check the length with:
+//
+//	len(mockedStore.GetAddressEmailSubscriptionsCalls())
+func (mock *StoreMock) GetAddressEmailSubscriptionsCalls() []struct {
+	Ctx   context.Context
+	Email string
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Email string
+	}
+	mock.lockGetAddressEmailSubscriptions.RLock()
+	calls = mock.calls.GetAddressEmailSubscriptions
+	mock.lockGetAddressEmailSubscriptions.RUnlock()
+	return calls
+}
+
+// GetAllBalls calls GetAllBallsFunc.
+func (mock *StoreMock) GetAllBalls(ctx context.Context, filter BallFilter) ([]Ball, error) {
+	if mock.GetAllBallsFunc == nil {
+		panic("StoreMock.GetAllBallsFunc: method is nil but Store.GetAllBalls was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Filter BallFilter
+	}{
+		Ctx:    ctx,
+		Filter: filter,
+	}
+	mock.lockGetAllBalls.Lock()
+	mock.calls.GetAllBalls = append(mock.calls.GetAllBalls, callInfo)
+	mock.lockGetAllBalls.Unlock()
+	return mock.GetAllBallsFunc(ctx, filter)
+}
+
+// GetAllBallsCalls gets all the calls that were made to GetAllBalls.
+// Check the length with:
+//
+//	len(mockedStore.GetAllBallsCalls())
+func (mock *StoreMock) GetAllBallsCalls() []struct {
+	Ctx    context.Context
+	Filter BallFilter
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Filter BallFilter
+	}
+	mock.lockGetAllBalls.RLock()
+	calls = mock.calls.GetAllBalls
+	mock.lockGetAllBalls.RUnlock()
+	return calls
+}
+
+// GetBallAliases calls GetBallAliasesFunc.
+func (mock *StoreMock) GetBallAliases(ctx context.Context) ([]BallAlias, error) {
+	if mock.GetBallAliasesFunc == nil {
+		panic("StoreMock.GetBallAliasesFunc: method is nil but Store.GetBallAliases was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetBallAliases.Lock()
+	mock.calls.GetBallAliases = append(mock.calls.GetBallAliases, callInfo)
+	mock.lockGetBallAliases.Unlock()
+	return mock.GetBallAliasesFunc(ctx)
+}
+
+// GetBallAliasesCalls gets all the calls that were made to GetBallAliases.
+// Check the length with:
+//
+//	len(mockedStore.GetBallAliasesCalls())
+func (mock *StoreMock) GetBallAliasesCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetBallAliases.RLock()
+	calls = mock.calls.GetBallAliases
+	mock.lockGetBallAliases.RUnlock()
+	return calls
+}
+
+// GetBrandRoles calls GetBrandRolesFunc.
+func (mock *StoreMock) GetBrandRoles(ctx context.Context, guildID string) ([]BrandRole, error) {
+	if mock.GetBrandRolesFunc == nil {
+		panic("StoreMock.GetBrandRolesFunc: method is nil but Store.GetBrandRoles was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		GuildID string
+	}{
+		Ctx:     ctx,
+		GuildID: guildID,
+	}
+	mock.lockGetBrandRoles.Lock()
+	mock.calls.GetBrandRoles = append(mock.calls.GetBrandRoles, callInfo)
+	mock.lockGetBrandRoles.Unlock()
+	return mock.GetBrandRolesFunc(ctx, guildID)
+}
+
+// GetBrandRolesCalls gets all the calls that were made to GetBrandRoles.
+// Check the length with:
+//
+//	len(mockedStore.GetBrandRolesCalls())
+func (mock *StoreMock) GetBrandRolesCalls() []struct {
+	Ctx     context.Context
+	GuildID string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		GuildID string
+	}
+	mock.lockGetBrandRoles.RLock()
+	calls = mock.calls.GetBrandRoles
+	mock.lockGetBrandRoles.RUnlock()
+	return calls
+}
+
+// GetDisabledChannels calls GetDisabledChannelsFunc.
+func (mock *StoreMock) GetDisabledChannels(ctx context.Context) ([]DisabledChannel, error) {
+	if mock.GetDisabledChannelsFunc == nil {
+		panic("StoreMock.GetDisabledChannelsFunc: method is nil but Store.GetDisabledChannels was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetDisabledChannels.Lock()
+	mock.calls.GetDisabledChannels = append(mock.calls.GetDisabledChannels, callInfo)
+	mock.lockGetDisabledChannels.Unlock()
+	return mock.GetDisabledChannelsFunc(ctx)
+}
+
+// GetDisabledChannelsCalls gets all the calls that were made to GetDisabledChannels.
+// Check the length with:
+//
+//	len(mockedStore.GetDisabledChannelsCalls())
+func (mock *StoreMock) GetDisabledChannelsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetDisabledChannels.RLock()
+	calls = mock.calls.GetDisabledChannels
+	mock.lockGetDisabledChannels.RUnlock()
+	return calls
+}
+
+// GetEmailSubscriptions calls GetEmailSubscriptionsFunc.
+func (mock *StoreMock) GetEmailSubscriptions(ctx context.Context) ([]EmailSubscription, error) {
+	if mock.GetEmailSubscriptionsFunc == nil {
+		panic("StoreMock.GetEmailSubscriptionsFunc: method is nil but Store.GetEmailSubscriptions was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetEmailSubscriptions.Lock()
+	mock.calls.GetEmailSubscriptions = append(mock.calls.GetEmailSubscriptions, callInfo)
+	mock.lockGetEmailSubscriptions.Unlock()
+	return mock.GetEmailSubscriptionsFunc(ctx)
+}
+
+// GetEmailSubscriptionsCalls gets all the calls that were made to GetEmailSubscriptions.
+// Check the length with:
+//
+//	len(mockedStore.GetEmailSubscriptionsCalls())
+func (mock *StoreMock) GetEmailSubscriptionsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetEmailSubscriptions.RLock()
+	calls = mock.calls.GetEmailSubscriptions
+	mock.lockGetEmailSubscriptions.RUnlock()
+	return calls
+}
+
+// GetLastSuccessfulRun calls GetLastSuccessfulRunFunc.
+func (mock *StoreMock) GetLastSuccessfulRun(ctx context.Context) (Run, error) {
+	if mock.GetLastSuccessfulRunFunc == nil {
+		panic("StoreMock.GetLastSuccessfulRunFunc: method is nil but Store.GetLastSuccessfulRun was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetLastSuccessfulRun.Lock()
+	mock.calls.GetLastSuccessfulRun = append(mock.calls.GetLastSuccessfulRun, callInfo)
+	mock.lockGetLastSuccessfulRun.Unlock()
+	return mock.GetLastSuccessfulRunFunc(ctx)
+}
+
+// GetLastSuccessfulRunCalls gets all the calls that were made to GetLastSuccessfulRun.
+// Check the length with:
+//
+//	len(mockedStore.GetLastSuccessfulRunCalls())
+func (mock *StoreMock) GetLastSuccessfulRunCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetLastSuccessfulRun.RLock()
+	calls = mock.calls.GetLastSuccessfulRun
+	mock.lockGetLastSuccessfulRun.RUnlock()
+	return calls
+}
+
+// GetPendingBalls calls GetPendingBallsFunc.
+func (mock *StoreMock) GetPendingBalls(ctx context.Context, maxAttempts int) ([]Ball, error) {
+	if mock.GetPendingBallsFunc == nil {
+		panic("StoreMock.GetPendingBallsFunc: method is nil but Store.GetPendingBalls was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		MaxAttempts int
+	}{
+		Ctx:         ctx,
+		MaxAttempts: maxAttempts,
+	}
+	mock.lockGetPendingBalls.Lock()
+	mock.calls.GetPendingBalls = append(mock.calls.GetPendingBalls, callInfo)
+	mock.lockGetPendingBalls.Unlock()
+	return mock.GetPendingBallsFunc(ctx, maxAttempts)
+}
+
+// GetPendingBallsCalls gets all the calls that were made to GetPendingBalls.
+// Check the length with:
+//
+//	len(mockedStore.GetPendingBallsCalls())
+func (mock *StoreMock) GetPendingBallsCalls() []struct {
+	Ctx         context.Context
+	MaxAttempts int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		MaxAttempts int
+	}
+	mock.lockGetPendingBalls.RLock()
+	calls = mock.calls.GetPendingBalls
+	mock.lockGetPendingBalls.RUnlock()
+	return calls
+}
+
+// GetRecentBalls calls GetRecentBallsFunc.
+func (mock *StoreMock) GetRecentBalls(ctx context.Context, filter BallFilter, limit int) ([]Ball, error) {
+	if mock.GetRecentBallsFunc == nil {
+		panic("StoreMock.GetRecentBallsFunc: method is nil but Store.GetRecentBalls was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Filter BallFilter
+		Limit  int
+	}{
+		Ctx:    ctx,
+		Filter: filter,
+		Limit:  limit,
+	}
+	mock.lockGetRecentBalls.Lock()
+	mock.calls.GetRecentBalls = append(mock.calls.GetRecentBalls, callInfo)
+	mock.lockGetRecentBalls.Unlock()
+	return mock.GetRecentBallsFunc(ctx, filter, limit)
+}
+
+// GetRecentBallsCalls gets all the calls that were made to GetRecentBalls.
+// Check the length with:
+//
+//	len(mockedStore.GetRecentBallsCalls())
+func (mock *StoreMock) GetRecentBallsCalls() []struct {
+	Ctx    context.Context
+	Filter BallFilter
+	Limit  int
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Filter BallFilter
+		Limit  int
+	}
+	mock.lockGetRecentBalls.RLock()
+	calls = mock.calls.GetRecentBalls
+	mock.lockGetRecentBalls.RUnlock()
+	return calls
+}
+
+// GetSubscriptions calls GetSubscriptionsFunc.
+func (mock *StoreMock) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	if mock.GetSubscriptionsFunc == nil {
+		panic("StoreMock.GetSubscriptionsFunc: method is nil but Store.GetSubscriptions was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockGetSubscriptions.Lock()
+	mock.calls.GetSubscriptions = append(mock.calls.GetSubscriptions, callInfo)
+	mock.lockGetSubscriptions.Unlock()
+	return mock.GetSubscriptionsFunc(ctx)
+}
+
+// GetSubscriptionsCalls gets all the calls that were made to GetSubscriptions.
+// Check the length with:
+//
+//	len(mockedStore.GetSubscriptionsCalls())
+func (mock *StoreMock) GetSubscriptionsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockGetSubscriptions.RLock()
+	calls = mock.calls.GetSubscriptions
+	mock.lockGetSubscriptions.RUnlock()
+	return calls
+}
+
+// GetUserSubscriptions calls GetUserSubscriptionsFunc.
+func (mock *StoreMock) GetUserSubscriptions(ctx context.Context, userID string) ([]Subscription, error) {
+	if mock.GetUserSubscriptionsFunc == nil {
+		panic("StoreMock.GetUserSubscriptionsFunc: method is nil but Store.GetUserSubscriptions was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		UserID string
+	}{
+		Ctx:    ctx,
+		UserID: userID,
+	}
+	mock.lockGetUserSubscriptions.Lock()
+	mock.calls.GetUserSubscriptions = append(mock.calls.GetUserSubscriptions, callInfo)
+	mock.lockGetUserSubscriptions.Unlock()
+	return mock.GetUserSubscriptionsFunc(ctx, userID)
+}
+
+// GetUserSubscriptionsCalls gets all the calls that were made to GetUserSubscriptions.
+// Check the length with:
+//
+//	len(mockedStore.GetUserSubscriptionsCalls())
+func (mock *StoreMock) GetUserSubscriptionsCalls() []struct {
+	Ctx    context.Context
+	UserID string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		UserID string
+	}
+	mock.lockGetUserSubscriptions.RLock()
+	calls = mock.calls.GetUserSubscriptions
+	mock.lockGetUserSubscriptions.RUnlock()
+	return calls
+}
+
+// MarkBallsNotified calls MarkBallsNotifiedFunc.
+func (mock *StoreMock) MarkBallsNotified(ctx context.Context, ids []int, notifiedAt time.Time) error {
+	if mock.MarkBallsNotifiedFunc == nil {
+		panic("StoreMock.MarkBallsNotifiedFunc: method is nil but Store.MarkBallsNotified was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Ids        []int
+		NotifiedAt time.Time
+	}{
+		Ctx:        ctx,
+		Ids:        ids,
+		NotifiedAt: notifiedAt,
+	}
+	mock.lockMarkBallsNotified.Lock()
+	mock.calls.MarkBallsNotified = append(mock.calls.MarkBallsNotified, callInfo)
+	mock.lockMarkBallsNotified.Unlock()
+	return mock.MarkBallsNotifiedFunc(ctx, ids, notifiedAt)
+}
+
+// MarkBallsNotifiedCalls gets all the calls that were made to MarkBallsNotified.
+// Check the length with:
+//
+//	len(mockedStore.MarkBallsNotifiedCalls())
+func (mock *StoreMock) MarkBallsNotifiedCalls() []struct {
+	Ctx        context.Context
+	Ids        []int
+	NotifiedAt time.Time
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Ids        []int
+		NotifiedAt time.Time
+	}
+	mock.lockMarkBallsNotified.RLock()
+	calls = mock.calls.MarkBallsNotified
+	mock.lockMarkBallsNotified.RUnlock()
+	return calls
+}
+
+// ReindexSearch calls ReindexSearchFunc.
+func (mock *StoreMock) ReindexSearch(ctx context.Context) (int, error) {
+	if mock.ReindexSearchFunc == nil {
+		panic("StoreMock.ReindexSearchFunc: method is nil but Store.ReindexSearch was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockReindexSearch.Lock()
+	mock.calls.ReindexSearch = append(mock.calls.ReindexSearch, callInfo)
+	mock.lockReindexSearch.Unlock()
+	return mock.ReindexSearchFunc(ctx)
+}
+
+// ReindexSearchCalls gets all the calls that were made to ReindexSearch.
+// Check the length with:
+//
+//	len(mockedStore.ReindexSearchCalls())
+func (mock *StoreMock) ReindexSearchCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockReindexSearch.RLock()
+	calls = mock.calls.ReindexSearch
+	mock.lockReindexSearch.RUnlock()
+	return calls
+}
+
+// ReleaseRunLease calls ReleaseRunLeaseFunc.
+func (mock *StoreMock) ReleaseRunLease(ctx context.Context, holder string) error {
+	if mock.ReleaseRunLeaseFunc == nil {
+		panic("StoreMock.ReleaseRunLeaseFunc: method is nil but Store.ReleaseRunLease was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Holder string
+	}{
+		Ctx:    ctx,
+		Holder: holder,
+	}
+	mock.lockReleaseRunLease.Lock()
+	mock.calls.ReleaseRunLease = append(mock.calls.ReleaseRunLease, callInfo)
+	mock.lockReleaseRunLease.Unlock()
+	return mock.ReleaseRunLeaseFunc(ctx, holder)
+}
+
+// ReleaseRunLeaseCalls gets all the calls that were made to ReleaseRunLease.
+// Check the length with:
+//
+//	len(mockedStore.ReleaseRunLeaseCalls())
+func (mock *StoreMock) ReleaseRunLeaseCalls() []struct {
+	Ctx    context.Context
+	Holder string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Holder string
+	}
+	mock.lockReleaseRunLease.RLock()
+	calls = mock.calls.ReleaseRunLease
+	mock.lockReleaseRunLease.RUnlock()
+	return calls
+}
+
+// RemoveBrandRole calls RemoveBrandRoleFunc.
+func (mock *StoreMock) RemoveBrandRole(ctx context.Context, role BrandRole) error {
+	if mock.RemoveBrandRoleFunc == nil {
+		panic("StoreMock.RemoveBrandRoleFunc: method is nil but Store.RemoveBrandRole was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Role BrandRole
+	}{
+		Ctx:  ctx,
+		Role: role,
+	}
+	mock.lockRemoveBrandRole.Lock()
+	mock.calls.RemoveBrandRole = append(mock.calls.RemoveBrandRole, callInfo)
+	mock.lockRemoveBrandRole.Unlock()
+	return mock.RemoveBrandRoleFunc(ctx, role)
+}
+
+// RemoveBrandRoleCalls gets all the calls that were made to RemoveBrandRole.
+// Check the length with:
+//
+//	len(mockedStore.RemoveBrandRoleCalls())
+func (mock *StoreMock) RemoveBrandRoleCalls() []struct {
+	Ctx  context.Context
+	Role BrandRole
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Role BrandRole
+	}
+	mock.lockRemoveBrandRole.RLock()
+	calls = mock.calls.RemoveBrandRole
+	mock.lockRemoveBrandRole.RUnlock()
+	return calls
+}
+
+// RemoveEmailSubscription calls RemoveEmailSubscriptionFunc.
+func (mock *StoreMock) RemoveEmailSubscription(ctx context.Context, token string) error {
+	if mock.RemoveEmailSubscriptionFunc == nil {
+		panic("StoreMock.RemoveEmailSubscriptionFunc: method is nil but Store.RemoveEmailSubscription was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Token string
+	}{
+		Ctx:   ctx,
+		Token: token,
+	}
+	mock.lockRemoveEmailSubscription.Lock()
+	mock.calls.RemoveEmailSubscription = append(mock.calls.RemoveEmailSubscription, callInfo)
+	mock.lockRemoveEmailSubscription.Unlock()
+	return mock.RemoveEmailSubscriptionFunc(ctx, token)
+}
+
+// RemoveEmailSubscriptionCalls gets all the calls that were made to RemoveEmailSubscription.
+// Check the length with:
+//
+//	len(mockedStore.RemoveEmailSubscriptionCalls())
+func (mock *StoreMock) RemoveEmailSubscriptionCalls() []struct {
+	Ctx   context.Context
+	Token string
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Token string
+	}
+	mock.lockRemoveEmailSubscription.RLock()
+	calls = mock.calls.RemoveEmailSubscription
+	mock.lockRemoveEmailSubscription.RUnlock()
+	return calls
+}
+
+// RemovePendingEmailSubscriptions calls RemovePendingEmailSubscriptionsFunc.
+func (mock *StoreMock) RemovePendingEmailSubscriptions(ctx context.Context, before time.Time) (int, error) {
+	if mock.RemovePendingEmailSubscriptionsFunc == nil {
+		panic("StoreMock.RemovePendingEmailSubscriptionsFunc: method is nil but Store.RemovePendingEmailSubscriptions was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Before time.Time
+	}{
+		Ctx:    ctx,
+		Before: before,
+	}
+	mock.lockRemovePendingEmailSubscriptions.Lock()
+	mock.calls.RemovePendingEmailSubscriptions = append(mock.calls.RemovePendingEmailSubscriptions, callInfo)
+	mock.lockRemovePendingEmailSubscriptions.Unlock()
+	return mock.RemovePendingEmailSubscriptionsFunc(ctx, before)
+}
+
+// RemovePendingEmailSubscriptionsCalls gets all the calls that were made to RemovePendingEmailSubscriptions.
+// Check the length with:
+//
+//	len(mockedStore.RemovePendingEmailSubscriptionsCalls())
+func (mock *StoreMock) RemovePendingEmailSubscriptionsCalls() []struct {
+	Ctx    context.Context
+	Before time.Time
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Before time.Time
+	}
+	mock.lockRemovePendingEmailSubscriptions.RLock()
+	calls = mock.calls.RemovePendingEmailSubscriptions
+	mock.lockRemovePendingEmailSubscriptions.RUnlock()
+	return calls
+}
+
+// RemoveSubscription calls RemoveSubscriptionFunc.
+func (mock *StoreMock) RemoveSubscription(ctx context.Context, sub Subscription) error {
+	if mock.RemoveSubscriptionFunc == nil {
+		panic("StoreMock.RemoveSubscriptionFunc: method is nil but Store.RemoveSubscription was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Sub Subscription
+	}{
+		Ctx: ctx,
+		Sub: sub,
+	}
+	mock.lockRemoveSubscription.Lock()
+	mock.calls.RemoveSubscription = append(mock.calls.RemoveSubscription, callInfo)
+	mock.lockRemoveSubscription.Unlock()
+	return mock.RemoveSubscriptionFunc(ctx, sub)
+}
+
+// RemoveSubscriptionCalls gets all the calls that were made to RemoveSubscription.
+// Check the length with:
+//
+//	len(mockedStore.RemoveSubscriptionCalls())
+func (mock *StoreMock) RemoveSubscriptionCalls() []struct {
+	Ctx context.Context
+	Sub Subscription
+} {
+	var calls []struct {
+		Ctx context.Context
+		Sub Subscription
+	}
+	mock.lockRemoveSubscription.RLock()
+	calls = mock.calls.RemoveSubscription
+	mock.lockRemoveSubscription.RUnlock()
+	return calls
+}
+
+// RemoveUserSubscriptions calls RemoveUserSubscriptionsFunc.
+func (mock *StoreMock) RemoveUserSubscriptions(ctx context.Context, userID string) error {
+	if mock.RemoveUserSubscriptionsFunc == nil {
+		panic("StoreMock.RemoveUserSubscriptionsFunc: method is nil but Store.RemoveUserSubscriptions was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		UserID string
+	}{
+		Ctx:    ctx,
+		UserID: userID,
+	}
+	mock.lockRemoveUserSubscriptions.Lock()
+	mock.calls.RemoveUserSubscriptions = append(mock.calls.RemoveUserSubscriptions, callInfo)
+	mock.lockRemoveUserSubscriptions.Unlock()
+	return mock.RemoveUserSubscriptionsFunc(ctx, userID)
+}
+
+// RemoveUserSubscriptionsCalls gets all the calls that were made to RemoveUserSubscriptions.
+// Check the length with:
+//
+//	len(mockedStore.RemoveUserSubscriptionsCalls())
+func (mock *StoreMock) RemoveUserSubscriptionsCalls() []struct {
+	Ctx    context.Context
+	UserID string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		UserID string
+	}
+	mock.lockRemoveUserSubscriptions.RLock()
+	calls = mock.calls.RemoveUserSubscriptions
+	mock.lockRemoveUserSubscriptions.RUnlock()
+	return calls
+}
+
+// SearchBalls calls SearchBallsFunc.
+func (mock *StoreMock) SearchBalls(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	if mock.SearchBallsFunc == nil {
+		panic("StoreMock.SearchBallsFunc: method is nil but Store.SearchBalls was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Query string
+		Limit int
+	}{
+		Ctx:   ctx,
+		Query: query,
+		Limit: limit,
+	}
+	mock.lockSearchBalls.Lock()
+	mock.calls.SearchBalls = append(mock.calls.SearchBalls, callInfo)
+	mock.lockSearchBalls.Unlock()
+	return mock.SearchBallsFunc(ctx, query, limit)
+}
+
+// SearchBallsCalls gets all the calls that were made to SearchBalls.
+// Check the length with:
+//
+//	len(mockedStore.SearchBallsCalls())
+func (mock *StoreMock) SearchBallsCalls() []struct {
+	Ctx   context.Context
+	Query string
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Query string
+		Limit int
+	}
+	mock.lockSearchBalls.RLock()
+	calls = mock.calls.SearchBalls
+	mock.lockSearchBalls.RUnlock()
+	return calls
+}
+
+// TakeDigestBalls calls TakeDigestBallsFunc.
+func (mock *StoreMock) TakeDigestBalls(ctx context.Context, digest string) ([]Ball, error) {
+	if mock.TakeDigestBallsFunc == nil {
+		panic("StoreMock.TakeDigestBallsFunc: method is nil but Store.TakeDigestBalls was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Digest string
+	}{
+		Ctx:    ctx,
+		Digest: digest,
+	}
+	mock.lockTakeDigestBalls.Lock()
+	mock.calls.TakeDigestBalls = append(mock.calls.TakeDigestBalls, callInfo)
+	mock.lockTakeDigestBalls.Unlock()
+	return mock.TakeDigestBallsFunc(ctx, digest)
+}
+
+// TakeDigestBallsCalls gets all the calls that were made to TakeDigestBalls.
+// Check the length with:
+//
+//	len(mockedStore.TakeDigestBallsCalls())
+func (mock *StoreMock) TakeDigestBallsCalls() []struct {
+	Ctx    context.Context
+	Digest string
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Digest string
+	}
+	mock.lockTakeDigestBalls.RLock()
+	calls = mock.calls.TakeDigestBalls
+	mock.lockTakeDigestBalls.RUnlock()
 	return calls
 }
@@ -0,0 +1,902 @@
+package balls
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteTimeLayout stores timestamps as fixed width utc text so they compare and sort correctly.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000Z"
+
+// SQLiteStore implements the Store interface backed by a sqlite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+func (s *SQLiteStore) AddBalls(ctx context.Context, balls []Ball) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt := `
+	INSERT INTO balls (brand, name, image_url, approved_at, search_trigrams) VALUES (?, ?, ?, ?, ?)
+	`
+
+	for _, ball := range balls {
+		trigrams := nameTrigrams(NormalizeName(ball.Name))
+
+		res, err := tx.ExecContext(ctx, stmt,
+			ball.Brand,
+			ball.Name,
+			imageURLString(ball.ImageURL),
+			formatSQLiteTime(ball.ApprovalDate),
+			len(trigrams),
+		)
+		if err != nil {
+			if isSQLiteUniqueViolation(err) {
+				return fmt.Errorf("exec: %w: %w", ErrDuplicateBall, err)
+			}
+			return fmt.Errorf("exec: %w", err)
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("last insert id: %w", err)
+		}
+
+		if err = indexSQLiteTrigrams(ctx, tx, id, trigrams); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func indexSQLiteTrigrams(ctx context.Context, tx *sql.Tx, id int64, trigrams []string) error {
+	stmt := `INSERT INTO ball_trigrams (trigram, ball_id) VALUES (?, ?)`
+
+	for _, t := range trigrams {
+		if _, err := tx.ExecContext(ctx, stmt, t, id); err != nil {
+			return fmt.Errorf("indexing trigrams: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) GetAllBalls(ctx context.Context, filter BallFilter) ([]Ball, error) {
+	where, args := sqliteBallFilter(filter)
+	stmt := `
+	SELECT
+		id,
+		brand,
+		name,
+		approved_at,
+		image_url
+	FROM balls
+	WHERE ` + where + `
+	ORDER BY id`
+	return s.queryBalls(ctx, stmt, args...)
+}
+
+func (s *SQLiteStore) GetRecentBalls(ctx context.Context, filter BallFilter, limit int) ([]Ball, error) {
+	where, args := sqliteBallFilter(filter)
+	stmt := `
+	SELECT
+		id,
+		brand,
+		name,
+		approved_at,
+		image_url
+	FROM balls
+	WHERE ` + where + `
+	ORDER BY approved_at DESC, id DESC
+	LIMIT ?`
+	return s.queryBalls(ctx, stmt, append(args, limit)...)
+}
+
+// sqliteBallFilter returns the WHERE clause and arguments selecting the balls matching filter.
+func sqliteBallFilter(filter BallFilter) (string, []any) {
+	where, args := []string{"1 = 1"}, []any{}
+	if filter.Brand != nil {
+		where = append(where, "brand = ?")
+		args = append(args, *filter.Brand)
+	}
+	if filter.Manufacturer != nil {
+		brands := filter.Manufacturer.Brands()
+		if len(brands) == 0 {
+			where = append(where, "0 = 1")
+		} else {
+			where = append(where, "brand IN ("+sqlitePlaceholders(len(brands))+")")
+			for _, b := range brands {
+				args = append(args, b)
+			}
+		}
+	}
+	if filter.Name != nil {
+		where = append(where, "name = ?")
+		args = append(args, *filter.Name)
+	}
+	if filter.ApprovalDate != nil {
+		where = append(where, "approved_at = ?")
+		args = append(args, formatSQLiteTime(*filter.ApprovalDate))
+	}
+
+	return strings.Join(where, " AND "), args
+}
+
+func (s *SQLiteStore) GetPendingBalls(ctx context.Context, maxAttempts int) ([]Ball, error) {
+	stmt := `
+	SELECT
+		id,
+		brand,
+		name,
+		approved_at,
+		image_url
+	FROM balls
+	WHERE notified_at IS NULL AND notify_attempts < ?
+	ORDER BY approved_at, id
+	`
+
+	return s.queryBalls(ctx, stmt, maxAttempts)
+}
+
+func (s *SQLiteStore) MarkBallsNotified(ctx context.Context, ids []int, notifiedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	stmt := `UPDATE balls SET notified_at = ? WHERE id IN (` + sqlitePlaceholders(len(ids)) + `)`
+
+	args := append([]any{formatSQLiteTime(notifiedAt)}, intArgs(ids)...)
+	if _, err := s.db.ExecContext(ctx, stmt, args...); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) AddNotifyAttempt(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	stmt := `
+	UPDATE balls SET notify_attempts = notify_attempts + 1
+	WHERE id IN (` + sqlitePlaceholders(len(ids)) + `) AND notified_at IS NULL
+	`
+
+	if _, err := s.db.ExecContext(ctx, stmt, intArgs(ids)...); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+// queryBalls returns the balls selected by stmt, which must select the columns scanned by GetAllBalls.
+func (s *SQLiteStore) queryBalls(ctx context.Context, stmt string, args ...any) ([]Ball, error) {
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var balls []Ball
+	for rows.Next() {
+		var ball Ball
+		var approvedAt, imageURL string
+		err = rows.Scan(
+			&ball.ID,
+			&ball.Brand,
+			&ball.Name,
+			&approvedAt,
+			&imageURL,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		ball.ApprovalDate, err = parseSQLiteTime(approvedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		ball.ImageURL, err = url.Parse(imageURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing image url: %w", err)
+		}
+
+		balls = append(balls, ball)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return balls, nil
+}
+
+func (s *SQLiteStore) AddRun(ctx context.Context, run Run) error {
+	var runErr any
+	if run.Err != "" {
+		runErr = run.Err
+	}
+
+	stmt := `INSERT INTO runs (id, started_at, finished_at, approved, error) VALUES (?, ?, ?, ?, ?)`
+
+	_, err := s.db.ExecContext(ctx, stmt,
+		run.ID,
+		formatSQLiteTime(run.StartedAt),
+		formatSQLiteTime(run.FinishedAt),
+		run.Approved,
+		runErr,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) GetLastSuccessfulRun(ctx context.Context) (Run, error) {
+	stmt := `
+	SELECT
+		id,
+		started_at,
+		finished_at,
+		approved
+	FROM runs
+	WHERE error IS NULL
+	ORDER BY finished_at DESC
+	LIMIT 1
+	`
+
+	var run Run
+	var startedAt, finishedAt string
+	err := s.db.QueryRowContext(ctx, stmt).Scan(&run.ID, &startedAt, &finishedAt, &run.Approved)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Run{}, ErrNotFound
+		}
+		return Run{}, fmt.Errorf("scan: %w", err)
+	}
+
+	if run.StartedAt, err = parseSQLiteTime(startedAt); err != nil {
+		return Run{}, err
+	}
+	if run.FinishedAt, err = parseSQLiteTime(finishedAt); err != nil {
+		return Run{}, err
+	}
+
+	return run, nil
+}
+
+func (s *SQLiteStore) AcquireRunLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	stmt := `
+	INSERT INTO run_lease (id, holder, expires_at) VALUES (1, ?, ?)
+	ON CONFLICT (id) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+	WHERE run_lease.expires_at < ?
+	RETURNING holder
+	`
+
+	var got string
+	err := s.db.QueryRowContext(ctx, stmt, holder, formatSQLiteTime(now.Add(ttl)), formatSQLiteTime(now)).Scan(&got)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("scan: %w", err)
+	}
+
+	return true, nil
+}
+
+func (s *SQLiteStore) ReleaseRunLease(ctx context.Context, holder string) error {
+	stmt := `DELETE FROM run_lease WHERE id = 1 AND holder = ?`
+
+	if _, err := s.db.ExecContext(ctx, stmt, holder); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) SearchBalls(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	trigrams := nameTrigrams(NormalizeName(query))
+	if len(trigrams) == 0 {
+		return nil, nil
+	}
+
+	in := sqlitePlaceholders(len(trigrams))
+	args := make([]any, 0, len(trigrams)+4)
+	for _, t := range trigrams {
+		args = append(args, t)
+	}
+	args = append(args, len(trigrams), minSearchScore, len(trigrams), limit)
+
+	stmt := `
+	SELECT
+		b.id,
+		b.brand,
+		b.name,
+		b.approved_at,
+		b.image_url,
+		b.search_trigrams,
+		count(*) AS shared
+	FROM ball_trigrams t
+	JOIN balls b ON b.id = t.ball_id
+	WHERE t.trigram IN (` + in + `)
+	GROUP BY b.id
+	HAVING CAST(count(*) AS REAL) / (? + b.search_trigrams - count(*)) >= ?
+	ORDER BY CAST(count(*) AS REAL) / (? + b.search_trigrams - count(*)) DESC, b.approved_at DESC
+	LIMIT ?`
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var ball Ball
+		var approvedAt, imageURL string
+		var ballTrigrams, shared int
+		err = rows.Scan(
+			&ball.ID,
+			&ball.Brand,
+			&ball.Name,
+			&approvedAt,
+			&imageURL,
+			&ballTrigrams,
+			&shared,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		ball.ApprovalDate, err = parseSQLiteTime(approvedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		ball.ImageURL, err = url.Parse(imageURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing image url: %w", err)
+		}
+
+		results = append(results, SearchResult{
+			Ball:  ball,
+			Score: trigramSimilarity(shared, len(trigrams), ballTrigrams),
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return results, nil
+}
+
+func (s *SQLiteStore) ReindexSearch(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, name FROM balls WHERE search_trigrams = 0`)
+	if err != nil {
+		return 0, fmt.Errorf("query: %w", err)
+	}
+
+	type unindexed struct {
+		id   int64
+		name string
+	}
+	var balls []unindexed
+	for rows.Next() {
+		var b unindexed
+		if err = rows.Scan(&b.id, &b.name); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan: %w", err)
+		}
+		balls = append(balls, b)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows err: %w", err)
+	}
+
+	indexed := 0
+	for _, b := range balls {
+		trigrams := nameTrigrams(NormalizeName(b.name))
+		if len(trigrams) == 0 {
+			continue
+		}
+
+		if err = indexSQLiteTrigrams(ctx, tx, b.id, trigrams); err != nil {
+			return 0, err
+		}
+
+		stmt := `UPDATE balls SET search_trigrams = ? WHERE id = ?`
+		if _, err = tx.ExecContext(ctx, stmt, len(trigrams), b.id); err != nil {
+			return 0, fmt.Errorf("exec: %w", err)
+		}
+		indexed++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+
+	return indexed, nil
+}
+
+func (s *SQLiteStore) AddBallAlias(ctx context.Context, alias BallAlias) error {
+	stmt := `
+	INSERT INTO ball_aliases (brand, alias, canonical) VALUES (?, ?, ?)
+	ON CONFLICT (brand, alias) DO UPDATE SET canonical = excluded.canonical
+	`
+
+	if _, err := s.db.ExecContext(ctx, stmt, alias.Brand, alias.Alias, alias.Canonical); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) GetBallAliases(ctx context.Context) ([]BallAlias, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT brand, alias, canonical FROM ball_aliases`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []BallAlias
+	for rows.Next() {
+		var a BallAlias
+		if err = rows.Scan(&a.Brand, &a.Alias, &a.Canonical); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		aliases = append(aliases, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return aliases, nil
+}
+
+func (s *SQLiteStore) AddDigestBalls(ctx context.Context, digest string, balls []Ball) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt := `
+	INSERT INTO digest_balls (digest, brand, name, approved_at, image_url, canonical_key) VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT (digest, brand, name, approved_at) DO NOTHING
+	`
+	for _, ball := range balls {
+		_, err = tx.ExecContext(ctx, stmt,
+			digest,
+			ball.Brand,
+			ball.Name,
+			formatSQLiteTime(ball.ApprovalDate),
+			imageURLString(ball.ImageURL),
+			ball.CanonicalKey,
+		)
+		if err != nil {
+			return fmt.Errorf("exec: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) TakeDigestBalls(ctx context.Context, digest string) ([]Ball, error) {
+	stmt := `
+	DELETE FROM digest_balls WHERE digest = ?
+	RETURNING brand, name, approved_at, image_url, canonical_key
+	`
+
+	rows, err := s.db.QueryContext(ctx, stmt, digest)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var balls []Ball
+	for rows.Next() {
+		var (
+			ball                 Ball
+			approvedAt, imageURL string
+		)
+		if err = rows.Scan(&ball.Brand, &ball.Name, &approvedAt, &imageURL, &ball.CanonicalKey); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		ball.ApprovalDate, err = parseSQLiteTime(approvedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		ball.ImageURL, err = url.Parse(imageURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing image url: %w", err)
+		}
+
+		balls = append(balls, ball)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	sortDigestBalls(balls)
+
+	return balls, nil
+}
+
+func (s *SQLiteStore) DisableChannel(ctx context.Context, channel DisabledChannel) error {
+	stmt := `
+	INSERT INTO disabled_channels (channel_id, reason, disabled_at) VALUES (?, ?, ?)
+	ON CONFLICT (channel_id) DO UPDATE SET reason = excluded.reason, disabled_at = excluded.disabled_at
+	`
+
+	_, err := s.db.ExecContext(ctx, stmt, channel.ChannelID, channel.Reason, formatSQLiteTime(channel.DisabledAt))
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) EnableChannel(ctx context.Context, channelID string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM disabled_channels WHERE channel_id = ?`, channelID); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) GetDisabledChannels(ctx context.Context) ([]DisabledChannel, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT channel_id, reason, disabled_at FROM disabled_channels`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var channels []DisabledChannel
+	for rows.Next() {
+		var (
+			c          DisabledChannel
+			disabledAt string
+		)
+		if err = rows.Scan(&c.ChannelID, &c.Reason, &disabledAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		c.DisabledAt, err = parseSQLiteTime(disabledAt)
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return channels, nil
+}
+
+func (s *SQLiteStore) AddBrandRole(ctx context.Context, role BrandRole) (BrandRole, error) {
+	stmt := `
+	INSERT INTO brand_roles (guild_id, brand, manufacturer, role_id) VALUES (?, ?, ?, ?)
+	ON CONFLICT (guild_id, brand, manufacturer) DO NOTHING
+	`
+	_, err := s.db.ExecContext(ctx, stmt, role.GuildID, role.Brand, role.Manufacturer, role.RoleID)
+	if err != nil {
+		return BrandRole{}, fmt.Errorf("exec: %w", err)
+	}
+
+	stmt = `SELECT role_id FROM brand_roles WHERE guild_id = ? AND brand = ? AND manufacturer = ?`
+	err = s.db.QueryRowContext(ctx, stmt, role.GuildID, role.Brand, role.Manufacturer).Scan(&role.RoleID)
+	if err != nil {
+		return BrandRole{}, fmt.Errorf("scan: %w", err)
+	}
+
+	return role, nil
+}
+
+func (s *SQLiteStore) RemoveBrandRole(ctx context.Context, role BrandRole) error {
+	stmt := `DELETE FROM brand_roles WHERE guild_id = ? AND brand = ? AND manufacturer = ? AND role_id = ?`
+
+	_, err := s.db.ExecContext(ctx, stmt, role.GuildID, role.Brand, role.Manufacturer, role.RoleID)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) GetBrandRoles(ctx context.Context, guildID string) ([]BrandRole, error) {
+	stmt := `SELECT guild_id, brand, manufacturer, role_id FROM brand_roles WHERE guild_id = ? ORDER BY brand, manufacturer`
+
+	rows, err := s.db.QueryContext(ctx, stmt, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []BrandRole
+	for rows.Next() {
+		var r BrandRole
+		if err = rows.Scan(&r.GuildID, &r.Brand, &r.Manufacturer, &r.RoleID); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+		roles = append(roles, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (s *SQLiteStore) AddSubscription(ctx context.Context, sub Subscription) error {
+	stmt := `
+	INSERT INTO subscriptions (user_id, brand, manufacturer, pattern, created_at) VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (user_id, brand, manufacturer, pattern) DO NOTHING
+	`
+	_, err := s.db.ExecContext(ctx, stmt,
+		sub.UserID, sub.Brand, sub.Manufacturer, sub.Pattern, formatSQLiteTime(sub.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) RemoveSubscription(ctx context.Context, sub Subscription) error {
+	stmt := `DELETE FROM subscriptions WHERE user_id = ? AND brand = ? AND manufacturer = ? AND pattern = ?`
+
+	if _, err := s.db.ExecContext(ctx, stmt, sub.UserID, sub.Brand, sub.Manufacturer, sub.Pattern); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) RemoveUserSubscriptions(ctx context.Context, userID string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM subscriptions WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	stmt := `
+	SELECT user_id, brand, manufacturer, pattern, created_at FROM subscriptions
+	ORDER BY user_id, brand, manufacturer, pattern
+	`
+
+	return s.querySubscriptions(ctx, stmt)
+}
+
+func (s *SQLiteStore) GetUserSubscriptions(ctx context.Context, userID string) ([]Subscription, error) {
+	stmt := `
+	SELECT user_id, brand, manufacturer, pattern, created_at FROM subscriptions WHERE user_id = ?
+	ORDER BY brand, manufacturer, pattern
+	`
+
+	return s.querySubscriptions(ctx, stmt, userID)
+}
+
+func (s *SQLiteStore) querySubscriptions(ctx context.Context, stmt string, args ...any) ([]Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		var (
+			sub       Subscription
+			createdAt string
+		)
+		if err = rows.Scan(&sub.UserID, &sub.Brand, &sub.Manufacturer, &sub.Pattern, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		sub.CreatedAt, err = parseSQLiteTime(createdAt)
+		if err != nil {
+			return nil, err
+		}
+
+		subs = append(subs, sub)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (s *SQLiteStore) AddEmailSubscription(ctx context.Context, sub EmailSubscription) (bool, error) {
+	stmt := `
+	INSERT INTO email_subscriptions (email, brand, manufacturer, pattern, token, created_at) VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT (email, brand, manufacturer, pattern) DO NOTHING
+	`
+	res, err := s.db.ExecContext(ctx, stmt,
+		sub.Email, sub.Brand, sub.Manufacturer, sub.Pattern, sub.Token, formatSQLiteTime(sub.CreatedAt))
+	if err != nil {
+		return false, fmt.Errorf("exec: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func (s *SQLiteStore) ConfirmEmailSubscription(ctx context.Context, token string, confirmedAt time.Time) error {
+	stmt := `UPDATE email_subscriptions SET confirmed_at = COALESCE(confirmed_at, ?) WHERE token = ?`
+
+	res, err := s.db.ExecContext(ctx, stmt, formatSQLiteTime(confirmedAt), token)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return sqliteFound(res)
+}
+
+func (s *SQLiteStore) RemoveEmailSubscription(ctx context.Context, token string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM email_subscriptions WHERE token = ?`, token)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return sqliteFound(res)
+}
+
+func (s *SQLiteStore) GetEmailSubscriptions(ctx context.Context) ([]EmailSubscription, error) {
+	stmt := `
+	SELECT email, brand, manufacturer, pattern, token, created_at, confirmed_at FROM email_subscriptions
+	WHERE confirmed_at IS NOT NULL
+	ORDER BY email, brand, manufacturer, pattern
+	`
+
+	return s.queryEmailSubscriptions(ctx, stmt)
+}
+
+func (s *SQLiteStore) GetAddressEmailSubscriptions(ctx context.Context, email string) ([]EmailSubscription, error) {
+	stmt := `
+	SELECT email, brand, manufacturer, pattern, token, created_at, confirmed_at FROM email_subscriptions
+	WHERE email = ?
+	ORDER BY created_at
+	`
+
+	return s.queryEmailSubscriptions(ctx, stmt, email)
+}
+
+func (s *SQLiteStore) RemovePendingEmailSubscriptions(ctx context.Context, before time.Time) (int, error) {
+	stmt := `DELETE FROM email_subscriptions WHERE confirmed_at IS NULL AND created_at < ?`
+
+	res, err := s.db.ExecContext(ctx, stmt, formatSQLiteTime(before))
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return int(n), nil
+}
+
+func (s *SQLiteStore) queryEmailSubscriptions(ctx context.Context, stmt string, args ...any) ([]EmailSubscription, error) {
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []EmailSubscription
+	for rows.Next() {
+		var (
+			sub         EmailSubscription
+			createdAt   string
+			confirmedAt sql.NullString
+		)
+		err = rows.Scan(&sub.Email, &sub.Brand, &sub.Manufacturer, &sub.Pattern, &sub.Token, &createdAt, &confirmedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		if sub.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+			return nil, err
+		}
+		if confirmedAt.Valid {
+			if sub.ConfirmedAt, err = parseSQLiteTime(confirmedAt.String); err != nil {
+				return nil, err
+			}
+		}
+
+		subs = append(subs, sub)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows err: %w", err)
+	}
+
+	return subs, nil
+}
+
+// sqlitePlaceholders returns n comma separated placeholders, e.g. for an IN list.
+func sqlitePlaceholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func intArgs(ids []int) []any {
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	return args
+}
+
+// sqliteFound returns ErrNotFound if res affected no rows.
+func sqliteFound(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func parseSQLiteTime(s string) (time.Time, error) {
+	t, err := time.Parse(sqliteTimeLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing time: %w", err)
+	}
+
+	return t, nil
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
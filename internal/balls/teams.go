@@ -0,0 +1,122 @@
+package balls
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TeamsNotifier implements the Notifier interface and posts newly approved balls to a Microsoft Teams incoming
+// webhook as Adaptive Cards.
+type TeamsNotifier struct {
+	webhookNotifier
+}
+
+func NewTeamsNotifier(client *http.Client, webhookURL string, opts ...WebhookOption) *TeamsNotifier {
+	return &TeamsNotifier{webhookNotifier: newWebhookNotifier(client, webhookURL, opts)}
+}
+
+// Notify posts a card listing each batch of the approved balls.
+func (n *TeamsNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	return n.notify(ctx, approvedBalls, func(batch, batches int, balls []Ball, messages []Message) any {
+		card := adaptiveCard{
+			Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+			Type:    "AdaptiveCard",
+			Version: "1.4",
+			MSTeams: map[string]string{"width": "Full"},
+		}
+
+		heading := ballCount(len(approvedBalls))
+		if batches > 1 {
+			heading = fmt.Sprintf("%s (%d of %d)", heading, batch+1, batches)
+		}
+		card.Body = append(card.Body, adaptiveElement{Type: "TextBlock", Text: heading, Size: "Large", Weight: "Bolder"})
+
+		for i, b := range balls {
+			card.Body = append(card.Body, teamsBallContainer(b, messages[i], n.styles.Style(b.Brand)))
+		}
+
+		return teamsMessage{
+			Type: "message",
+			Attachments: []teamsAttachment{{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content:     card,
+			}},
+		}
+	})
+}
+
+func teamsBallContainer(b Ball, msg Message, style BrandStyle) adaptiveElement {
+	items := []adaptiveElement{
+		{Type: "TextBlock", Text: msg.Title, Size: "Medium", Weight: "Bolder", Wrap: true},
+	}
+	if msg.Description != "" {
+		items = append(items, adaptiveElement{Type: "TextBlock", Text: msg.Description, Wrap: true})
+	}
+	items = append(items, adaptiveElement{
+		Type: "FactSet",
+		Facts: []adaptiveFact{
+			{Title: "Approved", Value: b.ApprovalDate.Format("January 2, 2006")},
+			{Title: "Manufacturer", Value: string(b.Brand.Manufacturer())},
+		},
+	})
+	if b.ImageURL != nil {
+		items = append(items, adaptiveElement{
+			Type:    "Image",
+			URL:     b.ImageURL.String(),
+			AltText: fmt.Sprintf("%s %s", b.Brand, b.Name),
+			Size:    "Large",
+		})
+	}
+
+	container := adaptiveElement{Type: "Container", Items: items, Separator: true}
+	if style.Website != "" {
+		container.SelectAction = &adaptiveAction{Type: "Action.OpenUrl", Title: "Website", URL: style.Website}
+	}
+
+	return container
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string            `json:"$schema"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Body    []adaptiveElement `json:"body"`
+	MSTeams map[string]string `json:"msteams,omitempty"`
+}
+
+// adaptiveElement is the subset of the TextBlock, FactSet, Image and Container elements used by notifications.
+type adaptiveElement struct {
+	Type         string            `json:"type"`
+	Text         string            `json:"text,omitempty"`
+	Size         string            `json:"size,omitempty"`
+	Weight       string            `json:"weight,omitempty"`
+	Wrap         bool              `json:"wrap,omitempty"`
+	Facts        []adaptiveFact    `json:"facts,omitempty"`
+	URL          string            `json:"url,omitempty"`
+	AltText      string            `json:"altText,omitempty"`
+	Items        []adaptiveElement `json:"items,omitempty"`
+	Separator    bool              `json:"separator,omitempty"`
+	SelectAction *adaptiveAction   `json:"selectAction,omitempty"`
+}
+
+type adaptiveFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type adaptiveAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
@@ -0,0 +1,332 @@
+package balls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultTelegramAPIURL = "https://api.telegram.org"
+
+	// Telegram albums hold between 2 and 10 photos, a single photo is sent on its own.
+	minTelegramMediaGroup = 2
+	maxTelegramMediaGroup = 10
+	maxTelegramCaption    = 1024
+	maxTelegramText       = 4096
+)
+
+// TelegramNotifier implements the Notifier interface and sends newly approved balls to Telegram chats with the Bot
+// API, as albums of captioned photos.
+type TelegramNotifier struct {
+	rendering
+
+	client  *http.Client
+	apiURL  string
+	token   string
+	chatIDs []string
+}
+
+// TelegramOption configures a TelegramNotifier.
+type TelegramOption interface {
+	applyTelegram(*TelegramNotifier)
+}
+
+func (o NotifierOption) applyTelegram(n *TelegramNotifier) { o.applyRendering(&n.rendering) }
+
+type telegramAPIURLOption string
+
+func (o telegramAPIURLOption) applyTelegram(n *TelegramNotifier) {
+	n.apiURL = strings.TrimSuffix(string(o), "/")
+}
+
+// WithTelegramAPIURL sets the url of the Bot API, https://api.telegram.org by default.
+func WithTelegramAPIURL(apiURL string) TelegramOption {
+	return telegramAPIURLOption(apiURL)
+}
+
+// NewTelegramNotifier returns a notifier sending to chatIDs, either numeric chat IDs or @channel usernames, as the
+// bot authenticated by token.
+func NewTelegramNotifier(client *http.Client, token string, chatIDs []string, opts ...TelegramOption) *TelegramNotifier {
+	n := &TelegramNotifier{client: client, apiURL: defaultTelegramAPIURL, token: token, chatIDs: chatIDs}
+	for _, opt := range opts {
+		opt.applyTelegram(n)
+	}
+	if n.client == nil {
+		n.client = http.DefaultClient
+	}
+	if n.renderer == nil {
+		n.renderer = defaultRenderer
+	}
+
+	return n
+}
+
+// Notify sends each chat a message listing the approved balls without images followed by albums of the ones with
+// images. A chat that fails doesn't stop the others being sent to.
+func (n *TelegramNotifier) Notify(ctx context.Context, approvedBalls []Ball) error {
+	if len(approvedBalls) == 0 {
+		return nil
+	}
+
+	messages, err := n.renderer.RenderBalls(ctx, approvedBalls)
+	if err != nil {
+		return err
+	}
+
+	var (
+		photos []telegramInputMedia
+		texts  = []string{fmt.Sprintf("<b>%s</b>", ballCount(len(approvedBalls)))}
+	)
+	for i, b := range approvedBalls {
+		caption := telegramCaption(messages[i], n.styles.Style(b.Brand))
+		if b.ImageURL == nil || b.ImageURL.String() == "" {
+			texts = append(texts, caption)
+			continue
+		}
+		photos = append(photos, telegramInputMedia{
+			Type:      "photo",
+			Media:     b.ImageURL.String(),
+			Caption:   caption,
+			ParseMode: "HTML",
+		})
+	}
+
+	requests := make([]telegramRequest, 0)
+	for _, text := range joinTelegramTexts(texts) {
+		requests = append(requests, telegramRequest{method: "sendMessage", payload: telegramMessage{
+			Text:               text,
+			ParseMode:          "HTML",
+			LinkPreviewOptions: &telegramLinkPreviewOptions{IsDisabled: true},
+		}})
+	}
+	for _, group := range telegramMediaGroups(photos) {
+		if len(group) == 1 {
+			requests = append(requests, telegramRequest{method: "sendPhoto", payload: telegramPhoto{
+				Photo:     group[0].Media,
+				Caption:   group[0].Caption,
+				ParseMode: group[0].ParseMode,
+			}})
+			continue
+		}
+		requests = append(requests, telegramRequest{method: "sendMediaGroup", payload: telegramMediaGroup{Media: group}})
+	}
+
+	var errs []error
+	for _, chatID := range n.chatIDs {
+		for i, req := range requests {
+			if err := n.call(ctx, req.method, req.withChatID(chatID)); err != nil {
+				errs = append(errs, fmt.Errorf("chat %s: %s %d of %d: %w", chatID, req.method, i+1, len(requests), err))
+				break
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// telegramCaption formats a ball's message as Telegram HTML, its title linking to the brand's website, truncated
+// to fit a photo caption.
+func telegramCaption(msg Message, style BrandStyle) string {
+	title := truncate(msg.Title, maxTelegramCaption)
+	caption := "<b>" + html.EscapeString(title) + "</b>"
+	if style.Website != "" {
+		caption = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(style.Website), caption)
+	}
+
+	// Telegram counts the length of captions after parsing their entities.
+	if remaining := maxTelegramCaption - utf8.RuneCountInString(title) - 1; msg.Description != "" && remaining > 1 {
+		caption += "\n" + html.EscapeString(truncate(msg.Description, remaining))
+	}
+
+	return caption
+}
+
+// joinTelegramTexts joins texts into as few messages as fit Telegram's limit. Each text is expected to fit on its
+// own.
+func joinTelegramTexts(texts []string) []string {
+	var (
+		messages []string
+		current  string
+	)
+	for _, text := range texts {
+		if current != "" && telegramTextLen(current)+2+telegramTextLen(text) > maxTelegramText {
+			messages = append(messages, current)
+			current = ""
+		}
+		if current != "" {
+			current += "\n\n"
+		}
+		current += text
+	}
+
+	return append(messages, current)
+}
+
+// telegramTextLen approximates the length Telegram counts for an HTML message by counting the text outside of tags,
+// overestimating escaped characters.
+func telegramTextLen(s string) int {
+	var (
+		n     int
+		inTag bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case !inTag && r != '&':
+			n++
+		}
+	}
+
+	return n
+}
+
+// telegramMediaGroups splits photos into albums of evenly sized groups within Telegram's limits, so a trailing
+// photo doesn't end up alone when it could share an album.
+func telegramMediaGroups(photos []telegramInputMedia) [][]telegramInputMedia {
+	if len(photos) == 0 {
+		return nil
+	}
+
+	groups := (len(photos) + maxTelegramMediaGroup - 1) / maxTelegramMediaGroup
+	size := (len(photos) + groups - 1) / groups
+	if size < minTelegramMediaGroup {
+		size = 1
+	}
+
+	return batchSlice(photos, size)
+}
+
+type telegramRequest struct {
+	method  string
+	payload interface{ withChatID(string) any }
+}
+
+func (r telegramRequest) withChatID(chatID string) any {
+	return r.payload.withChatID(chatID)
+}
+
+type telegramMessage struct {
+	ChatID             string                      `json:"chat_id"`
+	Text               string                      `json:"text"`
+	ParseMode          string                      `json:"parse_mode,omitempty"`
+	LinkPreviewOptions *telegramLinkPreviewOptions `json:"link_preview_options,omitempty"`
+}
+
+func (m telegramMessage) withChatID(chatID string) any {
+	m.ChatID = chatID
+	return m
+}
+
+type telegramLinkPreviewOptions struct {
+	IsDisabled bool `json:"is_disabled"`
+}
+
+type telegramPhoto struct {
+	ChatID    string `json:"chat_id"`
+	Photo     string `json:"photo"`
+	Caption   string `json:"caption,omitempty"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+func (p telegramPhoto) withChatID(chatID string) any {
+	p.ChatID = chatID
+	return p
+}
+
+type telegramMediaGroup struct {
+	ChatID string               `json:"chat_id"`
+	Media  []telegramInputMedia `json:"media"`
+}
+
+func (g telegramMediaGroup) withChatID(chatID string) any {
+	g.ChatID = chatID
+	return g
+}
+
+type telegramInputMedia struct {
+	Type      string `json:"type"`
+	Media     string `json:"media"`
+	Caption   string `json:"caption,omitempty"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+// telegramResponse is the envelope of every Bot API response.
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// TelegramError is returned when the Bot API rejects a request.
+type TelegramError struct {
+	ErrorCode   int
+	Description string
+}
+
+func (e *TelegramError) Error() string {
+	return fmt.Sprintf("telegram error %d: %s", e.ErrorCode, e.Description)
+}
+
+// call calls the Bot API method with payload, waiting out and retrying rate limits.
+func (n *TelegramNotifier) call(ctx context.Context, method string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling payload: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/%s", n.apiURL, n.token, method)
+	resp, err := doWithRetry(ctx, n.client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, telegramRetryAfter)
+	if err != nil {
+		// The url includes the bot token so only the underlying error is returned.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("calling %s: %w", method, err)
+	}
+
+	var result telegramResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		if resp.OK() {
+			return fmt.Errorf("decoding %s response: %w", method, err)
+		}
+		result.ErrorCode, result.Description = resp.StatusCode, http.StatusText(resp.StatusCode)
+	}
+	if result.OK {
+		return nil
+	}
+
+	return &TelegramError{ErrorCode: result.ErrorCode, Description: result.Description}
+}
+
+// telegramRetryAfter returns how long a rate limited response asks to wait for.
+func telegramRetryAfter(resp apiResponse) time.Duration {
+	var result telegramResponse
+	if json.Unmarshal(resp.Body, &result) != nil {
+		return 0
+	}
+
+	return time.Duration(result.Parameters.RetryAfter) * time.Second
+}
@@ -0,0 +1,171 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const telegramTestToken = "123:secret"
+
+// fakeTelegram records the Bot API calls it's sent. Chats named @missing don't exist.
+type fakeTelegram struct {
+	rateLimit bool
+	calls     []telegramCall
+}
+
+type telegramCall struct {
+	Method string
+	ChatID string               `json:"chat_id"`
+	Text   string               `json:"text"`
+	Photo  string               `json:"photo"`
+	Media  []telegramInputMedia `json:"media"`
+}
+
+func newFakeTelegram(t *testing.T, f *fakeTelegram) string {
+	t.Helper()
+
+	srv := newRecordingServer(t, "", nil)
+	srv.handle("POST /{bot}/{method}", func(w http.ResponseWriter, r *http.Request) {
+		reply := func(status int, description string) {
+			writeJSON(w, status, map[string]any{"ok": false, "error_code": status, "description": description})
+		}
+
+		if r.PathValue("bot") != "bot"+telegramTestToken {
+			reply(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		if rateLimited(&f.rateLimit) {
+			w.Header().Set("Retry-After", "0")
+			reply(http.StatusTooManyRequests, "Too Many Requests: retry after 0")
+			return
+		}
+
+		call := telegramCall{Method: r.PathValue("method")}
+		if err := json.NewDecoder(r.Body).Decode(&call); err != nil {
+			reply(http.StatusBadRequest, err.Error())
+			return
+		}
+		if call.ChatID == "@missing" {
+			reply(http.StatusBadRequest, "Bad Request: chat not found")
+			return
+		}
+		if call.Method == "sendMediaGroup" && (len(call.Media) < 2 || len(call.Media) > 10) {
+			reply(http.StatusBadRequest, "Bad Request: wrong number of messages in the media group")
+			return
+		}
+
+		f.calls = append(f.calls, call)
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "result": map[string]any{}})
+	})
+
+	return srv.URL
+}
+
+// String summarizes the call for comparing in tests.
+func (c telegramCall) String() string {
+	switch c.Method {
+	case "sendMessage":
+		title, _, _ := strings.Cut(c.Text, "\n")
+		return fmt.Sprintf("%s %s: %s", c.Method, c.ChatID, title)
+	case "sendMediaGroup":
+		return fmt.Sprintf("%s %s: %d photos, the first %s captioned %s",
+			c.Method, c.ChatID, len(c.Media), c.Media[0].Media, c.Media[0].Caption)
+	default:
+		return fmt.Sprintf("%s %s: %s", c.Method, c.ChatID, c.Photo)
+	}
+}
+
+func TestTelegramNotifier_Notify(t *testing.T) {
+	approved := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	albumBalls := []Ball{{Brand: Motiv, Name: "Venom Shock", ApprovalDate: approved}}
+	for i := range 11 {
+		albumBalls = append(albumBalls, Ball{
+			Brand:        Storm,
+			Name:         fmt.Sprintf("Phaze %d", i+1),
+			ImageURL:     &url.URL{Scheme: "https", Host: "some-url", Path: fmt.Sprintf("/phaze-%d.png", i+1)},
+			ApprovalDate: approved,
+		})
+	}
+
+	tests := []struct {
+		name      string
+		chats     []string
+		balls     []Ball
+		rateLimit bool
+		want      []string
+		wantErr   string
+	}{
+		{
+			name:      "albums split evenly",
+			chats:     []string{"@missing", "-1001"},
+			balls:     albumBalls,
+			rateLimit: true,
+			// 11 photos are sent as albums of 6 and 5 rather than 10 and 1.
+			want: []string{
+				"sendMessage -1001: <b>12 newly approved balls</b>",
+				"sendMediaGroup -1001: 6 photos, the first https://some-url/phaze-1.png captioned " +
+					`<a href="https://www.stormbowling.com"><b>Storm Phaze 1</b></a>`,
+				"sendMediaGroup -1001: 5 photos, the first https://some-url/phaze-7.png captioned " +
+					`<a href="https://www.stormbowling.com"><b>Storm Phaze 7</b></a>`,
+			},
+			wantErr: "Bad Request: chat not found",
+		},
+		{
+			name:  "single photo",
+			chats: []string{"@approvedballs"},
+			balls: webhookTestBalls()[:1],
+			want: []string{
+				"sendMessage @approvedballs: <b>1 newly approved ball</b>",
+				"sendPhoto @approvedballs: https://some-url/phaze.png",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTelegram{rateLimit: tt.rateLimit}
+			n := NewTelegramNotifier(nil, telegramTestToken, tt.chats, WithTelegramAPIURL(newFakeTelegram(t, fake)))
+
+			err := n.Notify(context.Background(), tt.balls)
+			var telegramErr *TelegramError
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatal(err)
+			case tt.wantErr != "" && (!errors.As(err, &telegramErr) || telegramErr.Description != tt.wantErr):
+				t.Fatalf("expected %q got %v", tt.wantErr, err)
+			case err != nil && strings.Contains(err.Error(), "secret"):
+				t.Fatalf("expected error not to include the bot token got %v", err)
+			}
+
+			got := make([]string, 0, len(fake.calls))
+			for _, call := range fake.calls {
+				got = append(got, call.String())
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("(-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTelegramCaption(t *testing.T) {
+	msg := Message{Title: "Storm <Phaze> & Co", Description: strings.Repeat("a", 2000)}
+
+	got := telegramCaption(msg, BrandStyle{})
+
+	if !strings.HasPrefix(got, "<b>Storm &lt;Phaze&gt; &amp; Co</b>\n") {
+		t.Fatalf("expected escaped title got %q", got)
+	}
+	description := strings.TrimPrefix(got, "<b>Storm &lt;Phaze&gt; &amp; Co</b>\n")
+	if n := len([]rune(msg.Title)) + 1 + len([]rune(description)); n != maxTelegramCaption {
+		t.Fatalf("expected caption to be truncated to %d characters got %d", maxTelegramCaption, n)
+	}
+}
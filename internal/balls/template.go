@@ -0,0 +1,303 @@
+package balls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// MessageTemplates are the text/template sources notifications are rendered with, empty templates use the
+// corresponding DefaultMessageTemplates. Templates are executed with MessageData.
+type MessageTemplates struct {
+	// Title renders the heading of a ball's notification, e.g. a discord embed's title.
+	Title string
+	// Description renders the body of a ball's notification.
+	Description string
+	// DigestLine renders a ball's line within a digest.
+	DigestLine string
+}
+
+// Merge returns t with the non-empty templates of override replacing its own.
+func (t MessageTemplates) Merge(override MessageTemplates) MessageTemplates {
+	if override.Title != "" {
+		t.Title = override.Title
+	}
+	if override.Description != "" {
+		t.Description = override.Description
+	}
+	if override.DigestLine != "" {
+		t.DigestLine = override.DigestLine
+	}
+
+	return t
+}
+
+// DefaultMessageTemplates are used for any templates that aren't configured.
+var DefaultMessageTemplates = MessageTemplates{
+	Title:       `{{.Ball.Brand}} {{.Ball.Name}}`,
+	Description: ``,
+	DigestLine:  `{{.Ball.Name}}, approved {{date "Jan 2" .Ball.ApprovalDate}}`,
+}
+
+// MessageData is the data notification templates are executed with:
+//
+//	.Ball   the approved ball, e.g. {{.Ball.Name}}, {{.Ball.Brand}}, {{.Ball.ApprovalDate}} and {{.Ball.ImageURL}}
+//	.Brand  the registry entry of the ball's brand, {{.Brand.Brand}} and {{.Brand.Manufacturer}}
+//	.Run    the run that found the ball, {{.Run.ID}}, {{.Run.StartedAt}} and {{.Run.Approved}}, digests have no
+//	        run ID and previews have the ID preview
+//	.Index  the ball's position in the notification starting at 1
+//	.Total  the number of balls in the notification
+//
+// Templates can also call date, e.g. {{date "January 2, 2006" .Ball.ApprovalDate}}, upper and lower.
+type MessageData struct {
+	Ball  Ball
+	Brand BrandInfo
+	Run   Run
+	Index int
+	Total int
+}
+
+// Message is a ball's rendered notification.
+type Message struct {
+	Title       string
+	Description string
+	DigestLine  string
+}
+
+// Renderer renders notifications from compiled MessageTemplates.
+type Renderer struct {
+	title       *template.Template
+	description *template.Template
+	digestLine  *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"upper": func(v any) string {
+		return strings.ToUpper(fmt.Sprint(v))
+	},
+	"lower": func(v any) string {
+		return strings.ToLower(fmt.Sprint(v))
+	},
+}
+
+// NewRenderer compiles templates, falling back to DefaultMessageTemplates for any that are empty.
+func NewRenderer(templates MessageTemplates) (*Renderer, error) {
+	parse := func(name, text, fallback string) (*template.Template, error) {
+		if text == "" {
+			text = fallback
+		}
+
+		t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s template: %w", name, err)
+		}
+		for _, tmpl := range t.Templates() {
+			if err := checkRanges(tmpl.Root); err != nil {
+				return nil, fmt.Errorf("parsing %s template: %w", name, err)
+			}
+		}
+
+		return t, nil
+	}
+
+	var (
+		r   Renderer
+		err error
+	)
+	if r.title, err = parse("title", templates.Title, DefaultMessageTemplates.Title); err != nil {
+		return nil, err
+	}
+	if r.description, err = parse("description", templates.Description, DefaultMessageTemplates.Description); err != nil {
+		return nil, err
+	}
+	if r.digestLine, err = parse("digest_line", templates.DigestLine, DefaultMessageTemplates.DigestLine); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// defaultRenderer renders DefaultMessageTemplates.
+var defaultRenderer = func() *Renderer {
+	r, err := NewRenderer(DefaultMessageTemplates)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}()
+
+// Render renders the notification of data's ball.
+func (r *Renderer) Render(data MessageData) (Message, error) {
+	return r.render(context.Background(), data)
+}
+
+// render renders the notification of data's ball, stopping once ctx is done.
+func (r *Renderer) render(ctx context.Context, data MessageData) (Message, error) {
+	var (
+		msg Message
+		err error
+	)
+	if msg.Title, err = execute(ctx, r.title, data); err != nil {
+		return Message{}, err
+	}
+	if msg.Description, err = execute(ctx, r.description, data); err != nil {
+		return Message{}, err
+	}
+	if msg.DigestLine, err = execute(ctx, r.digestLine, data); err != nil {
+		return Message{}, err
+	}
+
+	return msg, nil
+}
+
+// RenderBalls renders the notification of each ball, as part of the run in ctx if there is one. Rendering stops
+// once ctx is done.
+func (r *Renderer) RenderBalls(ctx context.Context, balls []Ball) ([]Message, error) {
+	run, ok := RunFromContext(ctx)
+	if !ok {
+		run = Run{StartedAt: time.Now(), Approved: len(balls)}
+	}
+
+	messages := make([]Message, 0, len(balls))
+	for i, b := range balls {
+		brand, ok := LookupBrand(b.Brand)
+		if !ok {
+			brand = BrandInfo{Brand: b.Brand, Manufacturer: b.Brand.Manufacturer()}
+		}
+
+		msg, err := r.render(ctx, MessageData{
+			Ball:  b,
+			Brand: brand,
+			Run:   run,
+			Index: i + 1,
+			Total: len(balls),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("rendering %s %s: %w", b.Brand, b.Name, err)
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+// maxTemplateOutput bounds the size of a single rendered template, well beyond anything a notification can hold.
+const maxTemplateOutput = 64 << 10
+
+// ErrTemplateOutputTooLarge is returned when a template renders more than maxTemplateOutput bytes.
+var ErrTemplateOutputTooLarge = fmt.Errorf("template output exceeds %d bytes", maxTemplateOutput)
+
+func execute(ctx context.Context, t *template.Template, data MessageData) (string, error) {
+	w := limitedWriter{ctx: ctx, remaining: maxTemplateOutput}
+	if err := t.Execute(&w, data); err != nil {
+		return "", fmt.Errorf("executing %s template: %w", t.Name(), err)
+	}
+
+	return strings.TrimSpace(w.sb.String()), nil
+}
+
+// limitedWriter collects template output, failing writes once ctx is done or more than remaining bytes have been
+// written so a template can't run away with the process.
+type limitedWriter struct {
+	ctx       context.Context
+	sb        strings.Builder
+	remaining int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(p) > w.remaining {
+		return 0, ErrTemplateOutputTooLarge
+	}
+	w.remaining -= len(p)
+
+	return w.sb.Write(p)
+}
+
+// checkRanges rejects range actions over anything but the data, such as {{range 100000000000}}, which would loop
+// without writing anything the output limit could stop.
+func checkRanges(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkRanges(child); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		return errors.Join(checkRanges(n.List), checkRanges(n.ElseList))
+	case *parse.WithNode:
+		return errors.Join(checkRanges(n.List), checkRanges(n.ElseList))
+	case *parse.RangeNode:
+		if !rangesOverData(n.Pipe) {
+			return fmt.Errorf("range over %s: only fields of the data can be ranged over", n.Pipe)
+		}
+		return errors.Join(checkRanges(n.List), checkRanges(n.ElseList))
+	}
+
+	return nil
+}
+
+// rangesOverData reports whether pipe is a field of the data, e.g. .Ball, $.Run or a field of a variable.
+func rangesOverData(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode, *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		// Field lookups fail on numbers so only bare variables other than $ could hold one.
+		return len(arg.Ident) > 1 || arg.Ident[0] == "$"
+	}
+
+	return false
+}
+
+// SampleBalls returns balls to preview templates with.
+func SampleBalls() []Ball {
+	approved := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	return []Ball{
+		{
+			Brand:        Storm,
+			Name:         "Phaze II",
+			ApprovalDate: approved,
+			ImageURL:     &url.URL{Scheme: "https", Host: "bowl.com", Path: "/images/phaze-ii.png"},
+			CanonicalKey: "phaze 2",
+		},
+		{
+			Brand:        Motiv,
+			Name:         "Venom Shock",
+			ApprovalDate: approved,
+			ImageURL:     &url.URL{Scheme: "https", Host: "bowl.com", Path: "/images/venom-shock.png"},
+			CanonicalKey: "venom shock",
+		},
+	}
+}
+
+type runContextKey struct{}
+
+// ContextWithRun returns a copy of ctx carrying run, so notifiers can describe the run that found the balls.
+func ContextWithRun(ctx context.Context, run Run) context.Context {
+	return context.WithValue(ctx, runContextKey{}, run)
+}
+
+// RunFromContext returns the run carried by ctx, if there is one.
+func RunFromContext(ctx context.Context) (Run, bool) {
+	run, ok := ctx.Value(runContextKey{}).(Run)
+	return run, ok
+}
@@ -0,0 +1,257 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderer_RenderBalls(t *testing.T) {
+	approved := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	balls := []Ball{
+		{Brand: RotoGrip, Name: "Idol", ApprovalDate: approved},
+		{Brand: Brand("Unknown"), Name: "Mystery", ApprovalDate: approved},
+	}
+	run := Run{ID: "run-1", StartedAt: approved, Approved: 2}
+
+	tests := []struct {
+		name      string
+		templates MessageTemplates
+		ctx       context.Context
+		want      []Message
+		wantErr   bool
+	}{
+		{
+			name:      "defaults",
+			templates: MessageTemplates{},
+			ctx:       context.Background(),
+			want: []Message{
+				{Title: "Roto Grip Idol", DigestLine: "Idol, approved May 1"},
+				{Title: "Unknown Mystery", DigestLine: "Mystery, approved May 1"},
+			},
+		},
+		{
+			name: "data model",
+			templates: MessageTemplates{
+				Title:       `{{upper .Ball.Name}} by {{.Brand.Manufacturer}}`,
+				Description: `{{.Index}}/{{.Total}} found by {{.Run.ID}} on {{date "2006-01-02" .Run.StartedAt}}`,
+				DigestLine:  `{{lower .Ball.Brand}}`,
+			},
+			ctx: ContextWithRun(context.Background(), run),
+			want: []Message{
+				{Title: "IDOL by Storm Products", Description: "1/2 found by run-1 on 2024-05-01", DigestLine: "roto grip"},
+				{Title: "MYSTERY by Unknown", Description: "2/2 found by run-1 on 2024-05-01", DigestLine: "unknown"},
+			},
+		},
+		{
+			name:      "execution error",
+			templates: MessageTemplates{Title: `{{.Ball.Colour}}`},
+			ctx:       context.Background(),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRenderer(tt.templates)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := r.RenderBalls(tt.ctx, balls)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderBalls() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d messages got %d", len(tt.want), len(got))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("message %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewRenderer_invalid(t *testing.T) {
+	_, err := NewRenderer(MessageTemplates{Description: "{{.Ball.Name"})
+	if err == nil || !strings.Contains(err.Error(), "description") {
+		t.Fatalf("expected description parse error got %v", err)
+	}
+}
+
+func TestNewRenderer_ranges(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		wantErr bool
+	}{
+		{name: "field", title: `{{range .Run.Approved}}x{{end}}`},
+		{name: "root variable", title: `{{range $i, $v := $.Index}}{{$v}}{{end}}`},
+		{name: "number", title: `{{range 100000000000}}{{end}}`, wantErr: true},
+		{name: "variable", title: `{{$n := 100000000000}}{{range $n}}{{end}}`, wantErr: true},
+		{name: "function", title: `{{range len .Ball.Name}}{{end}}`, wantErr: true},
+		{name: "nested", title: `{{if .Ball}}{{with .Run}}{{range 10}}{{end}}{{end}}{{end}}`, wantErr: true},
+		{name: "defined template", title: `{{define "loop"}}{{range 10}}{{end}}{{end}}{{.Ball.Name}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRenderer(MessageTemplates{Title: tt.title})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRenderer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderer_RenderBalls_limits(t *testing.T) {
+	r, err := NewRenderer(MessageTemplates{Title: `{{range .Ball.ID}}{{.}} {{$.Ball.Name}}{{end}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("output", func(t *testing.T) {
+		_, err := r.RenderBalls(context.Background(), []Ball{{ID: 100000000, Name: "Phaze II"}})
+		if !errors.Is(err, ErrTemplateOutputTooLarge) {
+			t.Fatalf("expected %v got %v", ErrTemplateOutputTooLarge, err)
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := r.RenderBalls(ctx, []Ball{{ID: 1, Name: "Phaze II"}})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v got %v", context.Canceled, err)
+		}
+	})
+}
+
+func Test_handlePreviewTemplates(t *testing.T) {
+	store := NewMemoryStore()
+	err := store.AddBalls(context.Background(), []Ball{
+		{Brand: Motiv, Name: "Venom Shock", ImageURL: &url.URL{Scheme: "https", Host: "some-url"}, ApprovalDate: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHTTPHandler(slog.Default(), NewService(slog.Default(), store, nil, nil), "test",
+		WithMessageTemplates("", MessageTemplates{Title: "New: {{.Ball.Name}}"}),
+		WithMessageTemplates("weekly", MessageTemplates{DigestLine: "- {{.Ball.Name}}"}),
+	)
+
+	preview := func(body string) (*httptest.ResponseRecorder, []messageResponse) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/templates/preview", strings.NewReader(body)))
+
+		var resp struct {
+			Messages []messageResponse `json:"messages"`
+		}
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		return rec, resp.Messages
+	}
+
+	t.Run("configured templates against sample balls", func(t *testing.T) {
+		rec, messages := preview(`{}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d got %d", http.StatusOK, rec.Code)
+		}
+		if len(messages) != len(SampleBalls()) || messages[0].Title != "New: Phaze II" {
+			t.Fatalf("expected sample balls rendered with configured title got %+v", messages)
+		}
+	})
+
+	t.Run("overrides against real balls", func(t *testing.T) {
+		rec, messages := preview(`{"destination":"weekly","templates":{"title":"{{.Run.ID}}"},"query":"venom"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d got %d", http.StatusOK, rec.Code)
+		}
+		if len(messages) != 1 {
+			t.Fatalf("expected 1 message got %d", len(messages))
+		}
+		if messages[0].Title != "preview" || messages[0].DigestLine != "- Venom Shock" || messages[0].Ball.Name != "Venom Shock" {
+			t.Fatalf("unexpected message %+v", messages[0])
+		}
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		rec, _ := preview(`{"templates":{"title":"{{"}}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("unknown destination", func(t *testing.T) {
+		rec, _ := preview(`{"destination":"monthly"}`)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+	t.Run("unbounded range", func(t *testing.T) {
+		rec, _ := preview(`{"templates":{"title":"{{range 100000000000}}x{{end}}"}}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		rec, _ := preview(`{"templates":{"title":"` + strings.Repeat("x", maxPreviewBody) + `"}}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func Test_handlePreviewTemplates_access(t *testing.T) {
+	svc := NewService(slog.Default(), NewMemoryStore(), nil, nil)
+
+	tests := []struct {
+		name          string
+		env           string
+		opts          []HandlerOption
+		authorization string
+		want          int
+	}{
+		{name: "open outside prod", env: "local", want: http.StatusOK},
+		{name: "disabled in prod", env: "prod", want: http.StatusNotFound},
+		{name: "token in prod", env: "prod", opts: []HandlerOption{WithAdminToken("secret")}, authorization: "Bearer secret", want: http.StatusOK},
+		{name: "missing token", env: "local", opts: []HandlerOption{WithAdminToken("secret")}, want: http.StatusUnauthorized},
+		{name: "wrong token", env: "prod", opts: []HandlerOption{WithAdminToken("secret")}, authorization: "Bearer nope", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHTTPHandler(slog.Default(), svc, tt.env, tt.opts...)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/templates/preview", strings.NewReader(`{}`))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected status %d got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
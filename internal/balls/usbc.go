@@ -55,6 +55,7 @@ var monthMap = map[string]int{
 	"December":  12,
 }
 
+// usbcBall is a ball as listed by the USBC, which publishes no specs or product links.
 type usbcBall struct {
 	Brand        string `json:"brandName"`
 	Name         string `json:"name"`
@@ -64,20 +65,48 @@ type usbcBall struct {
 
 // HTTPUSBCService handles interfacing with the usbc approved ball list json api
 type HTTPUSBCService struct {
-	client *http.Client
-	logger *slog.Logger
+	client        *http.Client
+	logger        *slog.Logger
+	canonicalizer *Canonicalizer
 }
 
-// NewHTTPUSBCService returns a new usbc service that interfaces using json over http.
-func NewHTTPUSBCService(client *http.Client, logger *slog.Logger) *HTTPUSBCService {
+// USBCServiceOption configures the usbc service.
+type USBCServiceOption interface {
+	apply(*HTTPUSBCService)
+}
+
+type usbcCanonicalizerOption struct {
+	c *Canonicalizer
+}
+
+func (o usbcCanonicalizerOption) apply(s *HTTPUSBCService) {
+	s.canonicalizer = o.c
+}
+
+// WithUSBCCanonicalizer sets the canonicalizer used to key listed balls, by default no alias rules are applied.
+func WithUSBCCanonicalizer(c *Canonicalizer) USBCServiceOption {
+	return usbcCanonicalizerOption{c: c}
+}
+
+// NewHTTPUSBCService returns a new usbc service that interfaces using json over http. The client's timeout
+// defaults to 10 seconds when not set.
+func NewHTTPUSBCService(client *http.Client, logger *slog.Logger, opts ...USBCServiceOption) *HTTPUSBCService {
 	if client == nil {
 		client = &http.Client{}
 	}
-	client.Timeout = 10 * time.Second
-	return &HTTPUSBCService{
-		client: client,
-		logger: logger,
+	if client.Timeout == 0 {
+		client.Timeout = 10 * time.Second
+	}
+	s := &HTTPUSBCService{
+		client:        client,
+		logger:        logger,
+		canonicalizer: &Canonicalizer{},
 	}
+	for _, opt := range opts {
+		opt.apply(s)
+	}
+
+	return s
 }
 
 // ListBalls lists balls from the USBC approved ball list by brand.
@@ -110,7 +139,7 @@ func (s *HTTPUSBCService) ListBalls(ctx context.Context, brand Brand) ([]Ball, e
 		}
 
 		i.Brand = strings.TrimSpace(i.Brand)
-		i.Name = strings.TrimSpace(i.Name)
+		i.Name = CleanName(i.Name)
 		i.ImageURL = strings.TrimSpace(i.ImageURL)
 		i.DateApproved = strings.TrimSpace(i.DateApproved)
 
@@ -133,6 +162,7 @@ func (s *HTTPUSBCService) ListBalls(ctx context.Context, brand Brand) ([]Ball, e
 			Name:         i.Name,
 			ApprovalDate: approvedAt,
 			ImageURL:     parsedURL,
+			CanonicalKey: s.canonicalizer.Key(Brand(i.Brand), i.Name),
 		})
 	}
 
@@ -0,0 +1,121 @@
+package balls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// webhookNotifier is what the notifiers posting to chat webhooks have in common.
+type webhookNotifier struct {
+	rendering
+
+	client    *http.Client
+	url       string
+	batchSize int
+}
+
+// WebhookOption configures a webhook notifier.
+type WebhookOption interface {
+	applyWebhook(*webhookNotifier)
+}
+
+func (o NotifierOption) applyWebhook(n *webhookNotifier) { o.applyRendering(&n.rendering) }
+
+type webhookBatchSizeOption int
+
+func (o webhookBatchSizeOption) applyWebhook(n *webhookNotifier) {
+	n.batchSize = int(o)
+}
+
+// WithWebhookBatchSize sets the number of balls posted per message.
+func WithWebhookBatchSize(size int) WebhookOption {
+	return webhookBatchSizeOption(size)
+}
+
+const defaultWebhookBatchSize = 5
+
+func newWebhookNotifier(client *http.Client, url string, opts []WebhookOption) webhookNotifier {
+	n := webhookNotifier{client: client, url: url, batchSize: defaultWebhookBatchSize}
+	for _, opt := range opts {
+		opt.applyWebhook(&n)
+	}
+	if n.client == nil {
+		n.client = http.DefaultClient
+	}
+	if n.batchSize < 1 {
+		n.batchSize = defaultWebhookBatchSize
+	}
+	if n.renderer == nil {
+		n.renderer = defaultRenderer
+	}
+
+	return n
+}
+
+// notify renders the approved balls and posts the payload built by payload for each batch of them.
+func (n webhookNotifier) notify(
+	ctx context.Context,
+	approvedBalls []Ball,
+	payload func(batch, batches int, balls []Ball, messages []Message) any,
+) error {
+	if len(approvedBalls) == 0 {
+		return nil
+	}
+
+	messages, err := n.renderer.RenderBalls(ctx, approvedBalls)
+	if err != nil {
+		return err
+	}
+
+	ballBatches := batchSlice(approvedBalls, n.batchSize)
+	messageBatches := batchSlice(messages, n.batchSize)
+	for i := range ballBatches {
+		err := postJSON(ctx, n.client, n.url, nil, payload(i, len(ballBatches), ballBatches[i], messageBatches[i]))
+		if err != nil {
+			return fmt.Errorf("posting message %d of %d: %w", i+1, len(ballBatches), err)
+		}
+	}
+
+	return nil
+}
+
+// WebhookError is returned when a webhook responds with an unexpected status.
+type WebhookError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// postJSON posts payload to url as json with any additional header, waiting out and retrying rate limits.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling payload: %w", err)
+	}
+
+	resp, err := doWithRetry(ctx, client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if !resp.OK() {
+		return &WebhookError{StatusCode: resp.StatusCode, Body: string(resp.Body)}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package balls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeWebhook is a chat webhook recording the payloads posted to it, or failing every post with status when it's
+// set.
+type fakeWebhook struct {
+	rateLimit bool
+	status    int
+	header    http.Header
+	payloads  []json.RawMessage
+}
+
+func newFakeWebhook(t *testing.T, f *fakeWebhook) string {
+	t.Helper()
+
+	srv := newRecordingServer(t, "", nil)
+	srv.handle("POST /", func(w http.ResponseWriter, r *http.Request) {
+		if rateLimited(&f.rateLimit) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if f.status != 0 {
+			w.WriteHeader(f.status)
+			w.Write([]byte("webhook disabled"))
+			return
+		}
+
+		var payload json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.header = r.Header
+		f.payloads = append(f.payloads, payload)
+	})
+
+	return srv.URL
+}
+
+func webhookTestBalls() []Ball {
+	approved := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	return []Ball{
+		{Brand: Storm, Name: "Phaze II", ImageURL: &url.URL{Scheme: "https", Host: "some-url", Path: "/phaze.png"}, ApprovalDate: approved},
+		{Brand: Motiv, Name: "Venom Shock", ApprovalDate: approved},
+		{Brand: RotoGrip, Name: "Idol", ApprovalDate: approved},
+	}
+}
+
+// teamsSummary summarizes a Teams message as its heading and each ball's title, link and image.
+func teamsSummary(t *testing.T, payload json.RawMessage) []string {
+	t.Helper()
+
+	var msg teamsMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("expected an adaptive card attachment got %+v", msg.Attachments)
+	}
+
+	body := msg.Attachments[0].Content.Body
+	summary := []string{body[0].Text}
+	for _, container := range body[1:] {
+		line := container.Items[0].Text
+		if container.SelectAction != nil {
+			line += " " + container.SelectAction.URL
+		}
+		if image := container.Items[len(container.Items)-1]; image.Type == "Image" {
+			line += " " + image.URL
+		}
+		summary = append(summary, line)
+	}
+
+	return summary
+}
+
+// googleChatSummary summarizes a Google Chat message as its text and each ball's title, manufacturer and image.
+func googleChatSummary(t *testing.T, payload json.RawMessage) []string {
+	t.Helper()
+
+	var msg googleChatMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatal(err)
+	}
+
+	summary := []string{msg.Text}
+	for _, c := range msg.Cards {
+		line := c.Card.Header.Title + " " + c.Card.Header.Subtitle
+		for _, w := range c.Card.Sections[0].Widgets {
+			if w.Image != nil {
+				line += " " + w.Image.ImageURL + " " + w.Image.AltText
+			}
+		}
+		summary = append(summary, line)
+	}
+
+	return summary
+}
+
+func TestWebhookNotifiers_Notify(t *testing.T) {
+	tests := []struct {
+		name        string
+		newNotifier func(url string) Notifier
+		summarize   func(t *testing.T, payload json.RawMessage) []string
+		fake        fakeWebhook
+		want        [][]string
+		wantErr     *WebhookError
+	}{
+		{
+			name: "teams",
+			newNotifier: func(url string) Notifier {
+				return NewTeamsNotifier(nil, url, WithWebhookBatchSize(2))
+			},
+			summarize: teamsSummary,
+			fake:      fakeWebhook{rateLimit: true},
+			want: [][]string{
+				{
+					"3 newly approved balls (1 of 2)",
+					"Storm Phaze II https://www.stormbowling.com https://some-url/phaze.png",
+					"Motiv Venom Shock https://www.motivbowling.com",
+				},
+				{"3 newly approved balls (2 of 2)", "Roto Grip Idol https://www.rotogrip.com"},
+			},
+		},
+		{
+			name: "google chat",
+			newNotifier: func(url string) Notifier {
+				return NewGoogleChatNotifier(nil, url)
+			},
+			summarize: googleChatSummary,
+			want: [][]string{{
+				"*3 newly approved balls*",
+				"Storm Phaze II Storm Products https://some-url/phaze.png Storm Phaze II",
+				"Motiv Venom Shock Motiv",
+				"Roto Grip Idol Storm Products",
+			}},
+		},
+		{
+			name: "disabled",
+			newNotifier: func(url string) Notifier {
+				return NewGoogleChatNotifier(nil, url)
+			},
+			fake:    fakeWebhook{status: http.StatusNotFound},
+			wantErr: &WebhookError{StatusCode: http.StatusNotFound, Body: "webhook disabled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := tt.fake
+			n := tt.newNotifier(newFakeWebhook(t, &fake))
+
+			err := n.Notify(context.Background(), webhookTestBalls())
+			var webhookErr *WebhookError
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatal(err)
+			case tt.wantErr != nil && (!errors.As(err, &webhookErr) || *webhookErr != *tt.wantErr):
+				t.Fatalf("expected %v got %v", tt.wantErr, err)
+			}
+
+			var got [][]string
+			for _, payload := range fake.payloads {
+				got = append(got, tt.summarize(t, payload))
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Fatalf("(-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
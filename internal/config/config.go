@@ -0,0 +1,980 @@
+// Package config provides the typed configuration of the service, loaded from a yaml or toml file with
+// environment variable overrides.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/actatum/approved-ball-list/internal/balls"
+	"github.com/actatum/approved-ball-list/internal/sqlite"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "REDACTED"
+
+// Config is the configuration of the service.
+type Config struct {
+	// Env is the environment the service is running in, prod sends notifications to discord.
+	Env string `yaml:"env" toml:"env"`
+	// GCPProject is used to correlate logs with traces.
+	GCPProject string         `yaml:"gcp_project" toml:"gcp_project"`
+	HTTP       HTTPConfig     `yaml:"http" toml:"http"`
+	Database   DatabaseConfig `yaml:"database" toml:"database"`
+	Discord    DiscordConfig  `yaml:"discord" toml:"discord"`
+	USBC       USBCConfig     `yaml:"usbc" toml:"usbc"`
+	Runs       RunsConfig     `yaml:"runs" toml:"runs"`
+	Names      NamesConfig    `yaml:"names" toml:"names"`
+	Brands     BrandsConfig   `yaml:"brands" toml:"brands"`
+	// Webhooks post notifications to Teams and Google Chat, alongside discord.
+	Webhooks []WebhookConfig `yaml:"webhooks" toml:"webhooks"`
+	// Email sends notifications over smtp when a host is set.
+	Email EmailConfig `yaml:"email" toml:"email"`
+	// Telegram sends notifications with a Telegram bot when a token is set.
+	Telegram TelegramConfig `yaml:"telegram" toml:"telegram"`
+	// Matrix sends notifications to Matrix rooms when a homeserver is set.
+	Matrix MatrixConfig `yaml:"matrix" toml:"matrix"`
+	// Ntfy publishes notifications to an ntfy topic when a topic is set.
+	Ntfy NtfyConfig `yaml:"ntfy" toml:"ntfy"`
+	// Mastodon posts notifications to a Mastodon account when a server is set.
+	Mastodon MastodonConfig `yaml:"mastodon" toml:"mastodon"`
+	// Bluesky posts notifications to a Bluesky account when an identifier is set.
+	Bluesky BlueskyConfig `yaml:"bluesky" toml:"bluesky"`
+}
+
+// HTTPConfig configures the http server.
+type HTTPConfig struct {
+	Port            string        `yaml:"port" toml:"port"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// AdminToken is the bearer token required by the administrative endpoints that run checks, send digests, enable
+	// disabled channels and preview templates. Template previews are only served outside prod without one.
+	AdminToken string `yaml:"admin_token" toml:"admin_token"`
+}
+
+// Supported stores.
+const (
+	StoreCRDB   = "crdb"
+	StoreSQLite = "sqlite"
+	StoreMemory = "memory"
+)
+
+// DatabaseConfig configures the database connection.
+type DatabaseConfig struct {
+	// Store selects the store implementation, when empty it's chosen by the scheme of the url. memory keeps
+	// everything in memory for local development.
+	Store string `yaml:"store" toml:"store"`
+	// URL is the database dsn, sqlite:// urls use sqlite and any other cockroachdb or postgres.
+	URL string `yaml:"url" toml:"url"`
+	// AutoMigrate applies any pending migrations when the server starts, when disabled they must be applied with
+	// cmd/migrate.
+	AutoMigrate bool `yaml:"auto_migrate" toml:"auto_migrate"`
+}
+
+// Driver returns the store implementation to use.
+func (c DatabaseConfig) Driver() string {
+	if c.Store != "" {
+		return c.Store
+	}
+
+	if sqlite.IsDSN(c.URL) {
+		return StoreSQLite
+	}
+
+	return StoreCRDB
+}
+
+// DiscordConfig configures the discord notifier.
+type DiscordConfig struct {
+	Token    string   `yaml:"token" toml:"token"`
+	Channels []string `yaml:"channels" toml:"channels"`
+	// Threads are channels each approved ball gets its own discussion in, a post in forum channels or a thread on
+	// the ball's message in text channels.
+	Threads []string `yaml:"threads" toml:"threads"`
+	// BatchSize is the number of embeds sent per message.
+	BatchSize int `yaml:"batch_size" toml:"batch_size"`
+	// Commands registers the bot's slash commands and connects to the discord gateway to handle them.
+	Commands bool `yaml:"commands" toml:"commands"`
+	// Digests send periodic summaries of approvals to their channels instead of posting each run's approvals.
+	Digests []DigestConfig `yaml:"digests" toml:"digests"`
+	// Templates render the notifications, see balls.MessageData for the data they're executed with.
+	Templates TemplatesConfig `yaml:"templates" toml:"templates"`
+}
+
+// TemplatesConfig configures the text/template notifications are rendered with, empty templates use the defaults.
+type TemplatesConfig struct {
+	Title       string `yaml:"title" toml:"title"`
+	Description string `yaml:"description" toml:"description"`
+	DigestLine  string `yaml:"digest_line" toml:"digest_line"`
+}
+
+// MessageTemplates returns the templates of digest, empty for the instant notifications. Digests use
+// discord.templates for any templates they don't override.
+func (c DiscordConfig) MessageTemplates(digest string) balls.MessageTemplates {
+	t := balls.MessageTemplates(c.Templates)
+	if digest == "" {
+		return t
+	}
+	for _, d := range c.Digests {
+		if d.Name == digest {
+			t = t.Merge(balls.MessageTemplates(d.Templates))
+		}
+	}
+
+	return t
+}
+
+// DigestConfig configures a digest of approvals sent to channels on a schedule.
+type DigestConfig struct {
+	// Name identifies the digest's pending approvals in the store, changing it discards them.
+	Name string `yaml:"name" toml:"name"`
+	// Schedule is an interval (e.g. 24h), cron expression or descriptor such as @daily or @weekly.
+	Schedule string   `yaml:"schedule" toml:"schedule"`
+	Channels []string `yaml:"channels" toml:"channels"`
+	// Templates override discord.templates for the digest.
+	Templates TemplatesConfig `yaml:"templates" toml:"templates"`
+}
+
+// DestinationDigestConfig sends a destination other than discord a digest of approvals on a schedule instead of each
+// run's approvals.
+type DestinationDigestConfig struct {
+	// Name identifies the digest's pending approvals in the store, the destination such as email by default.
+	// Webhooks have to be named.
+	Name string `yaml:"name" toml:"name"`
+	// Schedule is an interval (e.g. 24h), cron expression or descriptor such as @daily or @weekly, the destination
+	// is sent each run's approvals when it's empty.
+	Schedule string `yaml:"schedule" toml:"schedule"`
+}
+
+// DigestName returns the name of the digest, destination when it isn't named.
+func (c DestinationDigestConfig) DigestName(destination string) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return destination
+}
+
+// USBCConfig configures the client of the USBC approved ball list api.
+type USBCConfig struct {
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// RunsConfig configures the checks for newly approved balls.
+type RunsConfig struct {
+	// Workers is the number of brands checked concurrently.
+	Workers int `yaml:"workers" toml:"workers"`
+	// Schedule is an interval or cron expression to run checks in process, disabled when empty.
+	Schedule string `yaml:"schedule" toml:"schedule"`
+	// ScheduleJitter is the max random delay added to each scheduled run.
+	ScheduleJitter time.Duration `yaml:"schedule_jitter" toml:"schedule_jitter"`
+	// MaxAge is the max age of the last successful run before the service reports as degraded, 0 disables.
+	MaxAge time.Duration `yaml:"max_age" toml:"max_age"`
+}
+
+// NamesConfig configures how ball names are canonicalized to identify the same ball across differently spelled
+// entries.
+type NamesConfig struct {
+	// Aliases are applied in order to the canonical key of every ball.
+	Aliases []AliasRule `yaml:"aliases" toml:"aliases"`
+}
+
+// AliasRule rewrites canonical keys matching Pattern with Replacement, see balls.AliasRule.
+type AliasRule struct {
+	// Brand limits the rule to a single brand, when empty it applies to every brand.
+	Brand       string `yaml:"brand" toml:"brand"`
+	Pattern     string `yaml:"pattern" toml:"pattern"`
+	Replacement string `yaml:"replacement" toml:"replacement"`
+}
+
+// Canonicalizer returns the canonicalizer applying the configured alias rules.
+func (c NamesConfig) Canonicalizer() (*balls.Canonicalizer, error) {
+	rules := make([]balls.AliasRule, 0, len(c.Aliases))
+	for _, a := range c.Aliases {
+		rules = append(rules, balls.AliasRule{
+			Brand:       balls.Brand(a.Brand),
+			Pattern:     a.Pattern,
+			Replacement: a.Replacement,
+		})
+	}
+
+	return balls.NewCanonicalizer(rules...)
+}
+
+// BrandsConfig configures how brands are presented in notifications.
+type BrandsConfig struct {
+	// Styles override the built in styling of brands.
+	Styles []BrandStyle `yaml:"styles" toml:"styles"`
+}
+
+// BrandStyle overrides the styling of a brand, empty fields keep the built in style.
+type BrandStyle struct {
+	Brand string `yaml:"brand" toml:"brand"`
+	// Color is a hex color such as #e31837.
+	Color   string `yaml:"color" toml:"color"`
+	Website string `yaml:"website" toml:"website"`
+	LogoURL string `yaml:"logo_url" toml:"logo_url"`
+}
+
+// BrandStyles returns the configured brand style overrides.
+func (c BrandsConfig) BrandStyles() (balls.BrandStyles, error) {
+	styles := make(balls.BrandStyles, len(c.Styles))
+	for _, s := range c.Styles {
+		style := balls.BrandStyle{Website: s.Website, LogoURL: s.LogoURL}
+		if s.Color != "" {
+			color, err := parseColor(s.Color)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", s.Brand, err)
+			}
+			style.Color = color
+		}
+		styles[balls.Brand(s.Brand)] = style
+	}
+
+	return styles, nil
+}
+
+// Supported webhook types.
+const (
+	WebhookTeams      = "teams"
+	WebhookGoogleChat = "google_chat"
+)
+
+// WebhookConfig configures a chat webhook notifications are posted to.
+type WebhookConfig struct {
+	// Type is teams for a Microsoft Teams incoming webhook or google_chat for a Google Chat space webhook.
+	Type string `yaml:"type" toml:"type"`
+	// URL is the webhook url, it includes the key to post with so it's redacted when printed.
+	URL string `yaml:"url" toml:"url"`
+	// BatchSize is the number of balls posted per message, 5 by default.
+	BatchSize int `yaml:"batch_size" toml:"batch_size"`
+	// Digest sends the approvals on a schedule instead of as they're found.
+	Digest DestinationDigestConfig `yaml:"digest" toml:"digest"`
+	// Templates override discord.templates for the destination.
+	Templates TemplatesConfig `yaml:"templates" toml:"templates"`
+}
+
+// EmailConfig configures the smtp server and recipients of email notifications.
+type EmailConfig struct {
+	SMTP SMTPConfig `yaml:"smtp" toml:"smtp"`
+	// From is the sender address, such as "Approved balls <balls@example.com>".
+	From string `yaml:"from" toml:"from"`
+	// To is the list of addresses sent every approved ball.
+	To []string `yaml:"to" toml:"to"`
+	// Subscriptions sends each subscribed address the balls matching its subscriptions. Addresses subscribe over
+	// http and confirm by following an emailed link.
+	Subscriptions bool `yaml:"subscriptions" toml:"subscriptions"`
+	// PublicURL is the url the server is reachable at, which confirmation and unsubscribe links point to. Required
+	// with subscriptions.
+	PublicURL string `yaml:"public_url" toml:"public_url"`
+	// Digest sends the approvals on a schedule instead of as they're found.
+	Digest DestinationDigestConfig `yaml:"digest" toml:"digest"`
+	// Templates override discord.templates for the destination.
+	Templates TemplatesConfig `yaml:"templates" toml:"templates"`
+}
+
+// Enabled reports whether email notifications are configured.
+func (c EmailConfig) Enabled() bool {
+	return c.SMTP.Host != ""
+}
+
+// SMTPConfig configures the smtp server email is sent through.
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	// Security is starttls (the default), tls for implicit tls or none.
+	Security string `yaml:"security" toml:"security"`
+}
+
+// TelegramConfig configures the Telegram bot notifications are sent with.
+type TelegramConfig struct {
+	// Token is the bot token from @BotFather.
+	Token string `yaml:"token" toml:"token"`
+	// ChatIDs are the chats sent to, numeric IDs such as -1001234567890 or @channel usernames.
+	ChatIDs []string `yaml:"chat_ids" toml:"chat_ids"`
+	// Digest sends the approvals on a schedule instead of as they're found.
+	Digest DestinationDigestConfig `yaml:"digest" toml:"digest"`
+	// Templates override discord.templates for the destination.
+	Templates TemplatesConfig `yaml:"templates" toml:"templates"`
+}
+
+// Enabled reports whether Telegram notifications are configured.
+func (c TelegramConfig) Enabled() bool {
+	return c.Token != ""
+}
+
+// MatrixConfig configures the Matrix user notifications are sent as.
+type MatrixConfig struct {
+	// Homeserver is the url of the client-server API, such as https://matrix.example.org.
+	Homeserver  string `yaml:"homeserver" toml:"homeserver"`
+	AccessToken string `yaml:"access_token" toml:"access_token"`
+	// Rooms are the IDs of the rooms sent to, such as !abc:example.org, which the user has to have joined.
+	Rooms []string `yaml:"rooms" toml:"rooms"`
+	// Digest sends the approvals on a schedule instead of as they're found.
+	Digest DestinationDigestConfig `yaml:"digest" toml:"digest"`
+	// Templates override discord.templates for the destination.
+	Templates TemplatesConfig `yaml:"templates" toml:"templates"`
+}
+
+// Enabled reports whether Matrix notifications are configured.
+func (c MatrixConfig) Enabled() bool {
+	return c.Homeserver != ""
+}
+
+// NtfyConfig configures the ntfy topic notifications are published to.
+type NtfyConfig struct {
+	// Server is the url of the ntfy server, https://ntfy.sh by default.
+	Server string `yaml:"server" toml:"server"`
+	Topic  string `yaml:"topic" toml:"topic"`
+	// Token is an access token for publishing to protected topics.
+	Token string `yaml:"token" toml:"token"`
+	// Priority is from 1 (min) to 5 (max), the server's default of 3 when not set.
+	Priority int `yaml:"priority" toml:"priority"`
+	// Digest sends the approvals on a schedule instead of as they're found.
+	Digest DestinationDigestConfig `yaml:"digest" toml:"digest"`
+	// Templates override discord.templates for the destination.
+	Templates TemplatesConfig `yaml:"templates" toml:"templates"`
+}
+
+// Enabled reports whether ntfy notifications are configured.
+func (c NtfyConfig) Enabled() bool {
+	return c.Topic != ""
+}
+
+// MastodonConfig configures the Mastodon account notifications are posted to.
+type MastodonConfig struct {
+	// Server is the url of the account's server, such as https://mastodon.social.
+	Server string `yaml:"server" toml:"server"`
+	// AccessToken is an application's token with the write:media and write:statuses scopes.
+	AccessToken string `yaml:"access_token" toml:"access_token"`
+	// Visibility is public (the default), unlisted, private or direct.
+	Visibility string `yaml:"visibility" toml:"visibility"`
+	// Digest sends the approvals on a schedule instead of as they're found.
+	Digest DestinationDigestConfig `yaml:"digest" toml:"digest"`
+	// Templates override discord.templates for the destination.
+	Templates TemplatesConfig `yaml:"templates" toml:"templates"`
+}
+
+// Enabled reports whether Mastodon posts are configured.
+func (c MastodonConfig) Enabled() bool {
+	return c.Server != ""
+}
+
+// BlueskyConfig configures the Bluesky account notifications are posted to.
+type BlueskyConfig struct {
+	// Service is the url of the account's PDS, https://bsky.social by default.
+	Service string `yaml:"service" toml:"service"`
+	// Identifier is the account's handle or DID.
+	Identifier  string `yaml:"identifier" toml:"identifier"`
+	AppPassword string `yaml:"app_password" toml:"app_password"`
+	// Digest sends the approvals on a schedule instead of as they're found.
+	Digest DestinationDigestConfig `yaml:"digest" toml:"digest"`
+	// Templates override discord.templates for the destination.
+	Templates TemplatesConfig `yaml:"templates" toml:"templates"`
+}
+
+// Enabled reports whether Bluesky posts are configured.
+func (c BlueskyConfig) Enabled() bool {
+	return c.Identifier != ""
+}
+
+// parseColor parses a hex color such as #e31837.
+func parseColor(s string) (int, error) {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || len(hex) != 6 {
+		return 0, fmt.Errorf("color must be formatted as #rrggbb, got %q", s)
+	}
+
+	color, err := strconv.ParseInt(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("color must be formatted as #rrggbb, got %q", s)
+	}
+
+	return int(color), nil
+}
+
+// Default returns the configuration used for any settings not set by a file or the environment.
+func Default() Config {
+	return Config{
+		Env: "local",
+		HTTP: HTTPConfig{
+			Port:            "8080",
+			ReadTimeout:     time.Minute,
+			WriteTimeout:    time.Minute,
+			IdleTimeout:     time.Minute,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Database: DatabaseConfig{
+			AutoMigrate: true,
+		},
+		Discord: DiscordConfig{
+			BatchSize: 3,
+		},
+		USBC: USBCConfig{
+			Timeout: 10 * time.Second,
+		},
+		Runs: RunsConfig{
+			Workers: 7,
+			MaxAge:  2 * time.Hour,
+		},
+		Email: EmailConfig{
+			SMTP: SMTPConfig{Port: 587},
+		},
+	}
+}
+
+// Load returns the default configuration overridden by the file at path, if not empty, and then by environment
+// variables. The file format is chosen by its extension, either .yaml, .yml or .toml.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := applyEnv(&cfg, os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("parsing yaml config file: %w", err)
+		}
+
+	case ".toml":
+		if err := toml.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("parsing toml config file: %w", err)
+		}
+
+	default:
+		return fmt.Errorf("unsupported config file extension: %s", ext)
+	}
+
+	return nil
+}
+
+// applyEnv overrides cfg with any of the supported environment variables that are set.
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	str := func(key string, dst *string) {
+		if val, ok := lookup(key); ok {
+			*dst = val
+		}
+	}
+
+	str("ENV", &cfg.Env)
+	str("GOOGLE_CLOUD_PROJECT", &cfg.GCPProject)
+	str("PORT", &cfg.HTTP.Port)
+	str("ADMIN_TOKEN", &cfg.HTTP.AdminToken)
+	str("STORE", &cfg.Database.Store)
+	str("COCKROACHDB_URL", &cfg.Database.URL)
+	str("DATABASE_URL", &cfg.Database.URL)
+	str("DISCORD_TOKEN", &cfg.Discord.Token)
+	str("SCHEDULE", &cfg.Runs.Schedule)
+	str("SMTP_PASSWORD", &cfg.Email.SMTP.Password)
+	str("TELEGRAM_TOKEN", &cfg.Telegram.Token)
+	str("MATRIX_ACCESS_TOKEN", &cfg.Matrix.AccessToken)
+	str("NTFY_TOKEN", &cfg.Ntfy.Token)
+	str("MASTODON_ACCESS_TOKEN", &cfg.Mastodon.AccessToken)
+	str("BLUESKY_APP_PASSWORD", &cfg.Bluesky.AppPassword)
+
+	if val, ok := lookup("DISCORD_CHANNELS"); ok {
+		cfg.Discord.Channels = SplitList(val)
+	}
+
+	if val, ok := lookup("DISCORD_THREADS"); ok {
+		cfg.Discord.Threads = SplitList(val)
+	}
+
+	if val, ok := lookup("TELEGRAM_CHAT_IDS"); ok {
+		cfg.Telegram.ChatIDs = SplitList(val)
+	}
+
+	if val, ok := lookup("DISCORD_COMMANDS"); ok {
+		commands, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid DISCORD_COMMANDS: %w", err)
+		}
+		cfg.Discord.Commands = commands
+	}
+
+	if val, ok := lookup("AUTO_MIGRATE"); ok {
+		autoMigrate, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("invalid AUTO_MIGRATE: %w", err)
+		}
+		cfg.Database.AutoMigrate = autoMigrate
+	}
+
+	if val, ok := lookup("WORKERS"); ok {
+		workers, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid WORKERS: %w", err)
+		}
+		cfg.Runs.Workers = workers
+	}
+
+	return nil
+}
+
+// SplitList splits a comma separated list, ignoring empty items.
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// Validate checks the configuration is usable, returning all the problems found.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Env == "" {
+		errs = append(errs, errors.New("env is required"))
+	}
+
+	if port, err := strconv.Atoi(c.HTTP.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("http.port must be a port number, got %q", c.HTTP.Port))
+	}
+	for _, timeout := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"http.read_timeout", c.HTTP.ReadTimeout},
+		{"http.write_timeout", c.HTTP.WriteTimeout},
+		{"http.idle_timeout", c.HTTP.IdleTimeout},
+		{"http.shutdown_timeout", c.HTTP.ShutdownTimeout},
+		{"usbc.timeout", c.USBC.Timeout},
+	} {
+		if timeout.d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", timeout.name, timeout.d))
+		}
+	}
+
+	switch c.Database.Driver() {
+	case StoreCRDB, StoreSQLite:
+		if c.Database.URL == "" {
+			errs = append(errs, errors.New("database.url is required"))
+		}
+
+	case StoreMemory:
+
+	default:
+		errs = append(errs, fmt.Errorf("database.store must be one of %s, %s or %s, got %q",
+			StoreCRDB, StoreSQLite, StoreMemory, c.Database.Store))
+	}
+
+	if c.Env == "prod" {
+		if c.Discord.Token == "" && (len(c.Discord.Channels) > 0 || len(c.Discord.Threads) > 0 ||
+			len(c.Discord.Digests) > 0 || c.Discord.Commands) {
+			errs = append(errs, errors.New(
+				"discord.token is required in prod when there are discord channels, threads, digests or commands",
+			))
+		}
+		if len(c.Discord.Channels) == 0 && len(c.Discord.Threads) == 0 && len(c.Discord.Digests) == 0 &&
+			len(c.Webhooks) == 0 && !c.Email.Enabled() && !c.Telegram.Enabled() && !c.Matrix.Enabled() &&
+			!c.Ntfy.Enabled() && !c.Mastodon.Enabled() && !c.Bluesky.Enabled() {
+			errs = append(errs, errors.New(
+				"discord.channels requires at least one channel in prod when there are no threads, digests, webhooks, "+
+					"email, telegram, matrix, ntfy, mastodon or bluesky",
+			))
+		}
+	}
+	digestNames := make(map[string]bool, len(c.Discord.Digests))
+	for i, d := range c.Discord.Digests {
+		switch {
+		case d.Name == "":
+			errs = append(errs, fmt.Errorf("discord.digests[%d].name is required", i))
+		case digestNames[d.Name]:
+			errs = append(errs, fmt.Errorf("discord.digests[%d].name %q is used by another digest", i, d.Name))
+		}
+		digestNames[d.Name] = true
+
+		if _, err := balls.ParseSchedule(d.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("discord.digests[%d].schedule is invalid: %w", i, err))
+		}
+		if len(d.Channels) == 0 {
+			errs = append(errs, fmt.Errorf("discord.digests[%d].channels requires at least one channel", i))
+		}
+		if _, err := balls.NewRenderer(balls.MessageTemplates(d.Templates)); err != nil {
+			errs = append(errs, fmt.Errorf("discord.digests[%d].templates are invalid: %w", i, err))
+		}
+	}
+	if _, err := balls.NewRenderer(c.Discord.MessageTemplates("")); err != nil {
+		errs = append(errs, fmt.Errorf("discord.templates are invalid: %w", err))
+	}
+	if c.Discord.BatchSize < 1 || c.Discord.BatchSize > 10 {
+		errs = append(errs, fmt.Errorf("discord.batch_size must be between 1 and 10, got %d", c.Discord.BatchSize))
+	}
+
+	if c.Runs.Workers < 1 {
+		errs = append(errs, fmt.Errorf("runs.workers must be at least 1, got %d", c.Runs.Workers))
+	}
+	if c.Runs.Schedule != "" {
+		if _, err := balls.ParseSchedule(c.Runs.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("runs.schedule is invalid: %w", err))
+		}
+	}
+	if c.Runs.ScheduleJitter < 0 {
+		errs = append(errs, fmt.Errorf("runs.schedule_jitter must not be negative, got %s", c.Runs.ScheduleJitter))
+	}
+	if c.Runs.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("runs.max_age must not be negative, got %s", c.Runs.MaxAge))
+	}
+
+	for i, style := range c.Brands.Styles {
+		if _, ok := balls.LookupBrand(balls.Brand(style.Brand)); !ok {
+			errs = append(errs, fmt.Errorf("brands.styles[%d].brand %q is not a known brand", i, style.Brand))
+		}
+		if style.Color != "" {
+			if _, err := parseColor(style.Color); err != nil {
+				errs = append(errs, fmt.Errorf("brands.styles[%d].%w", i, err))
+			}
+		}
+		for _, link := range []struct{ field, url string }{
+			{field: "website", url: style.Website},
+			{field: "logo_url", url: style.LogoURL},
+		} {
+			if u, err := url.Parse(link.url); link.url != "" && (err != nil || (u.Scheme != "http" && u.Scheme != "https")) {
+				errs = append(errs, fmt.Errorf("brands.styles[%d].%s must be an http or https url, got %q", i, link.field, link.url))
+			}
+		}
+	}
+
+	for i, a := range c.Names.Aliases {
+		if _, err := regexp.Compile(a.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("names.aliases[%d].pattern is invalid: %w", i, err))
+		}
+	}
+
+	for i, w := range c.Webhooks {
+		if w.Type != WebhookTeams && w.Type != WebhookGoogleChat {
+			errs = append(errs, fmt.Errorf("webhooks[%d].type must be one of %s or %s, got %q",
+				i, WebhookTeams, WebhookGoogleChat, w.Type))
+		}
+		if u, err := url.Parse(w.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("webhooks[%d].url must be an https url", i))
+		}
+		if w.BatchSize < 0 {
+			errs = append(errs, fmt.Errorf("webhooks[%d].batch_size must not be negative, got %d", i, w.BatchSize))
+		}
+	}
+
+	if c.Email.Enabled() {
+		errs = append(errs, c.Email.validate()...)
+	}
+	if c.Telegram.Enabled() {
+		if len(c.Telegram.ChatIDs) == 0 {
+			errs = append(errs, errors.New("telegram.chat_ids requires at least one chat"))
+		}
+		for i, id := range c.Telegram.ChatIDs {
+			if _, err := strconv.ParseInt(id, 10, 64); err != nil && !strings.HasPrefix(id, "@") {
+				errs = append(errs, fmt.Errorf("telegram.chat_ids[%d] must be a numeric chat id or @username, got %q", i, id))
+			}
+		}
+	}
+	if c.Matrix.Enabled() {
+		if u, err := url.Parse(c.Matrix.Homeserver); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("matrix.homeserver must be an http or https url, got %q", c.Matrix.Homeserver))
+		}
+		if c.Matrix.AccessToken == "" {
+			errs = append(errs, errors.New("matrix.access_token is required"))
+		}
+		if len(c.Matrix.Rooms) == 0 {
+			errs = append(errs, errors.New("matrix.rooms requires at least one room"))
+		}
+		for i, room := range c.Matrix.Rooms {
+			if !strings.HasPrefix(room, "!") || !strings.Contains(room, ":") {
+				errs = append(errs, fmt.Errorf("matrix.rooms[%d] must be a room id such as !abc:example.org, got %q", i, room))
+			}
+		}
+	}
+	if c.Ntfy.Enabled() {
+		if u, err := url.Parse(c.Ntfy.Server); c.Ntfy.Server != "" &&
+			(err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+			errs = append(errs, fmt.Errorf("ntfy.server must be an http or https url, got %q", c.Ntfy.Server))
+		}
+		if c.Ntfy.Priority < 0 || c.Ntfy.Priority > 5 {
+			errs = append(errs, fmt.Errorf("ntfy.priority must be between 1 and 5, got %d", c.Ntfy.Priority))
+		}
+	}
+	if c.Mastodon.Enabled() {
+		if u, err := url.Parse(c.Mastodon.Server); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("mastodon.server must be an https url, got %q", c.Mastodon.Server))
+		}
+		if c.Mastodon.AccessToken == "" {
+			errs = append(errs, errors.New("mastodon.access_token is required"))
+		}
+		switch c.Mastodon.Visibility {
+		case "", "public", "unlisted", "private", "direct":
+		default:
+			errs = append(errs, fmt.Errorf("mastodon.visibility must be one of public, unlisted, private or direct, got %q",
+				c.Mastodon.Visibility))
+		}
+	}
+	if c.Bluesky.Enabled() {
+		if u, err := url.Parse(c.Bluesky.Service); c.Bluesky.Service != "" && (err != nil || u.Scheme != "https" || u.Host == "") {
+			errs = append(errs, fmt.Errorf("bluesky.service must be an https url, got %q", c.Bluesky.Service))
+		}
+		if c.Bluesky.AppPassword == "" {
+			errs = append(errs, errors.New("bluesky.app_password is required"))
+		}
+	}
+
+	for _, d := range c.Destinations() {
+		if _, err := balls.NewRenderer(balls.MessageTemplates(d.Templates)); err != nil {
+			errs = append(errs, fmt.Errorf("%s.templates are invalid: %w", d.Path, err))
+		}
+
+		if d.Digest.Schedule == "" {
+			continue
+		}
+		name := d.Digest.DigestName(d.Path)
+		switch {
+		case d.Digest.Name == "" && strings.HasPrefix(d.Path, "webhooks"):
+			errs = append(errs, fmt.Errorf("%s.digest.name is required", d.Path))
+		case digestNames[name]:
+			errs = append(errs, fmt.Errorf("%s.digest.name %q is used by another digest", d.Path, name))
+		}
+		digestNames[name] = true
+
+		if _, err := balls.ParseSchedule(d.Digest.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("%s.digest.schedule is invalid: %w", d.Path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Destination is the configuration every destination other than discord has.
+type Destination struct {
+	// Path is the destination's path in the config, such as email or webhooks[0].
+	Path      string
+	Digest    DestinationDigestConfig
+	Templates TemplatesConfig
+}
+
+// MessageTemplates returns the templates of the destination, discord.templates are used for any templates it
+// doesn't override.
+func (d Destination) MessageTemplates(discord DiscordConfig) balls.MessageTemplates {
+	return discord.MessageTemplates("").Merge(balls.MessageTemplates(d.Templates))
+}
+
+// Destinations returns every enabled destination other than discord.
+func (c Config) Destinations() []Destination {
+	var destinations []Destination
+	for i, w := range c.Webhooks {
+		destinations = append(destinations, Destination{
+			Path:      fmt.Sprintf("webhooks[%d]", i),
+			Digest:    w.Digest,
+			Templates: w.Templates,
+		})
+	}
+	for _, d := range []struct {
+		Destination
+		enabled bool
+	}{
+		{Destination{"email", c.Email.Digest, c.Email.Templates}, c.Email.Enabled()},
+		{Destination{"telegram", c.Telegram.Digest, c.Telegram.Templates}, c.Telegram.Enabled()},
+		{Destination{"matrix", c.Matrix.Digest, c.Matrix.Templates}, c.Matrix.Enabled()},
+		{Destination{"ntfy", c.Ntfy.Digest, c.Ntfy.Templates}, c.Ntfy.Enabled()},
+		{Destination{"mastodon", c.Mastodon.Digest, c.Mastodon.Templates}, c.Mastodon.Enabled()},
+		{Destination{"bluesky", c.Bluesky.Digest, c.Bluesky.Templates}, c.Bluesky.Enabled()},
+	} {
+		if d.enabled {
+			destinations = append(destinations, d.Destination)
+		}
+	}
+
+	return destinations
+}
+
+func (c EmailConfig) validate() []error {
+	var errs []error
+
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		errs = append(errs, fmt.Errorf("email.from must be an email address, got %q", c.From))
+	}
+	if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+		errs = append(errs, fmt.Errorf("email.smtp.port must be a port number, got %d", c.SMTP.Port))
+	}
+	switch balls.SMTPSecurity(c.SMTP.Security) {
+	case "", balls.SMTPStartTLS, balls.SMTPTLS, balls.SMTPNone:
+	default:
+		errs = append(errs, fmt.Errorf("email.smtp.security must be one of %s, %s or %s, got %q",
+			balls.SMTPStartTLS, balls.SMTPTLS, balls.SMTPNone, c.SMTP.Security))
+	}
+	for i, to := range c.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			errs = append(errs, fmt.Errorf("email.to[%d] must be an email address, got %q", i, to))
+		}
+	}
+	if len(c.To) == 0 && !c.Subscriptions {
+		errs = append(errs, errors.New("email.to requires at least one address when subscriptions are disabled"))
+	}
+	if c.Subscriptions {
+		if u, err := url.Parse(c.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("email.public_url must be an http(s) url when subscriptions are enabled, got %q",
+				c.PublicURL))
+		}
+	}
+
+	return errs
+}
+
+// Redacted returns a copy of the configuration with secrets redacted so it's safe to print.
+func (c Config) Redacted() Config {
+	if c.HTTP.AdminToken != "" {
+		c.HTTP.AdminToken = redacted
+	}
+	if c.Discord.Token != "" {
+		c.Discord.Token = redacted
+	}
+	c.Discord.Channels = append([]string(nil), c.Discord.Channels...)
+	c.Discord.Threads = append([]string(nil), c.Discord.Threads...)
+	c.Webhooks = append([]WebhookConfig(nil), c.Webhooks...)
+	for i := range c.Webhooks {
+		if c.Webhooks[i].URL != "" {
+			c.Webhooks[i].URL = redacted
+		}
+	}
+	c.Discord.Digests = append([]DigestConfig(nil), c.Discord.Digests...)
+	c.Email.To = append([]string(nil), c.Email.To...)
+	if c.Email.SMTP.Password != "" {
+		c.Email.SMTP.Password = redacted
+	}
+	c.Telegram.ChatIDs = append([]string(nil), c.Telegram.ChatIDs...)
+	if c.Telegram.Token != "" {
+		c.Telegram.Token = redacted
+	}
+	c.Matrix.Rooms = append([]string(nil), c.Matrix.Rooms...)
+	if c.Matrix.AccessToken != "" {
+		c.Matrix.AccessToken = redacted
+	}
+	if c.Ntfy.Token != "" {
+		c.Ntfy.Token = redacted
+	}
+	if c.Mastodon.AccessToken != "" {
+		c.Mastodon.AccessToken = redacted
+	}
+	if c.Bluesky.AppPassword != "" {
+		c.Bluesky.AppPassword = redacted
+	}
+
+	if c.Database.URL != "" {
+		c.Database.URL = redactDSN(c.Database.URL)
+	}
+
+	return c
+}
+
+// redactDSN masks the passwords in a database url or key/value connection string, e.g.
+// host=localhost password=secret, redacting the whole string when it can't be parsed.
+func redactDSN(dsn string) string {
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return redacted
+		}
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		q := u.Query()
+		for key := range q {
+			if isPasswordKey(key) {
+				q.Set(key, redacted)
+			}
+		}
+		u.RawQuery = q.Encode()
+
+		return u.String()
+	}
+
+	var (
+		pairs []string
+		rest  = strings.TrimSpace(dsn)
+	)
+	for rest != "" {
+		key, value, ok := strings.Cut(rest, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t\n'") {
+			return redacted
+		}
+
+		value, rest, ok = cutDSNValue(strings.TrimLeft(value, " \t\n"))
+		if !ok {
+			return redacted
+		}
+		if isPasswordKey(key) {
+			value = redacted
+		}
+		pairs = append(pairs, key+"="+value)
+		rest = strings.TrimSpace(rest)
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// cutDSNValue cuts the value at the start of s from the rest of a key/value connection string, values are either
+// single quoted with backslash escapes or end at the next whitespace.
+func cutDSNValue(s string) (value, rest string, ok bool) {
+	if !strings.HasPrefix(s, "'") {
+		if i := strings.IndexAny(s, " \t\n"); i >= 0 {
+			return s[:i], s[i:], true
+		}
+		return s, "", true
+	}
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '\'':
+			return s[:i+1], s[i+1:], true
+		}
+	}
+
+	return "", "", false
+}
+
+// isPasswordKey reports whether key is a connection parameter holding a password, e.g. password or sslpassword.
+func isPasswordKey(key string) bool {
+	return strings.Contains(strings.ToLower(key), "password")
+}
+
+// YAML returns the configuration encoded as yaml.
+func (c Config) YAML() ([]byte, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Marshal: %w", err)
+	}
+
+	return data, nil
+}
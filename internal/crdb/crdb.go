@@ -1,62 +1,158 @@
+// Package crdb provides the cockroachdb or postgres database used by the service.
 package crdb
 
 import (
 	"context"
-	"database/sql"
 	"embed"
 	"fmt"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/cockroachdb"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pkg/errors"
 )
 
-//go:embed migrations/*.sql
+//go:embed migrations/cockroachdb/*.sql migrations/postgres/*.sql
 var migrations embed.FS
 
-const migrationVersion = 3
+// MigrationVersion is the schema version expected by this build.
+const MigrationVersion = 23
 
-// NewDB returns a new pgxpool with the migrations applied to the database.
-func NewDB(dsn string) (*pgxpool.Pool, error) {
+// Dialect is the flavour of sql spoken by the database, which determines the migrations applied to it.
+type Dialect string
+
+// Supported dialects.
+const (
+	CockroachDB Dialect = "cockroachdb"
+	Postgres    Dialect = "postgres"
+)
+
+// DetectDialect returns whether the database is cockroachdb or postgres.
+func DetectDialect(ctx context.Context, db *pgxpool.Pool) (Dialect, error) {
+	var version string
+	if err := db.QueryRow(ctx, "SELECT version()").Scan(&version); err != nil {
+		return "", fmt.Errorf("reading server version: %w", err)
+	}
+
+	if strings.Contains(version, "CockroachDB") {
+		return CockroachDB, nil
+	}
+
+	return Postgres, nil
+}
+
+// Open returns a new pgxpool connected to the database without applying any migrations.
+func Open(dsn string) (*pgxpool.Pool, error) {
 	db, err := pgxpool.New(context.Background(), dsn)
 	if err != nil {
 		return nil, fmt.Errorf("pgxpool.New: %w", err)
 	}
 
 	if err := db.Ping(context.Background()); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("pinging database: %w", err)
 	}
 
-	if err = runMigrations(stdlib.OpenDBFromPool(db)); err != nil {
+	return db, nil
+}
+
+// NewDB returns a new pgxpool with the migrations for the database's dialect applied to it.
+func NewDB(dsn string) (*pgxpool.Pool, error) {
+	db, err := Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := NewMigrator(context.Background(), db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("running migrations: %w", err)
+	}
+
+	defer m.Close()
+
+	err = m.Migrate(MigrationVersion)
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		db.Close()
 		return nil, fmt.Errorf("running migrations: %w", err)
 	}
 
 	return db, nil
 }
 
-func runMigrations(db *sql.DB) error {
-	source, err := iofs.New(migrations, "migrations")
+// MigrationSource returns the embedded migrations for dialect.
+func MigrationSource(dialect Dialect) (source.Driver, error) {
+	src, err := iofs.New(migrations, "migrations/"+string(dialect))
 	if err != nil {
-		return errors.Wrap(err, "iofs.New")
+		return nil, errors.Wrap(err, "iofs.New")
 	}
 
-	driver, err := cockroachdb.WithInstance(db, &cockroachdb.Config{})
+	return src, nil
+}
+
+// NewMigrator returns a migrate instance applying the embedded migrations for the database's dialect to db.
+// Closing it releases its connection back to db without closing db.
+func NewMigrator(ctx context.Context, db *pgxpool.Pool) (*migrate.Migrate, error) {
+	dialect, err := DetectDialect(ctx, db)
 	if err != nil {
-		return errors.Wrap(err, "cockroachdb.WithInstance")
+		return nil, err
 	}
 
-	m, err := migrate.NewWithInstance("iofs", source, "cockroachdb", driver)
+	src, err := MigrationSource(dialect)
 	if err != nil {
-		return errors.New("migrate.NewWithInstance")
+		return nil, err
 	}
 
-	err = m.Migrate(migrationVersion)
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return err
+	sqlDB := stdlib.OpenDBFromPool(db)
+
+	var driver database.Driver
+	switch dialect {
+	case CockroachDB:
+		driver, err = cockroachdb.WithInstance(sqlDB, &cockroachdb.Config{})
+		if err != nil {
+			return nil, errors.Wrap(err, "cockroachdb.WithInstance")
+		}
+
+	default:
+		driver, err = pgxmigrate.WithInstance(sqlDB, &pgxmigrate.Config{})
+		if err != nil {
+			return nil, errors.Wrap(err, "pgx.WithInstance")
+		}
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, string(dialect), driver)
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate.NewWithInstance")
+	}
+
+	return m, nil
+}
+
+// VerifyMigrations checks that the database schema is at the version expected by this build and that the last
+// migration didn't fail part way through.
+func VerifyMigrations(ctx context.Context, db *pgxpool.Pool) error {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty", version)
+	}
+
+	if version != MigrationVersion {
+		return fmt.Errorf("schema version is %d, expected %d", version, MigrationVersion)
 	}
 
-	return source.Close()
+	return nil
 }
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS runs (
+    id STRING PRIMARY KEY,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    approved INT NOT NULL DEFAULT 0,
+    error STRING
+);
+
+CREATE INDEX IF NOT EXISTS runs_finished_at ON runs (finished_at DESC);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS run_lease (
+    id INT PRIMARY KEY,
+    holder STRING NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS ball_trigrams;
+
+ALTER TABLE balls DROP COLUMN IF EXISTS search_trigrams;
+
+COMMIT;
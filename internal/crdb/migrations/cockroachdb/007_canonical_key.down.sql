@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS ball_aliases;
+
+DROP INDEX IF EXISTS balls@balls_brand_canonical_key;
+
+ALTER TABLE balls DROP COLUMN IF EXISTS canonical_key;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+ALTER TABLE balls ADD COLUMN IF NOT EXISTS canonical_key STRING NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS balls_brand_canonical_key ON balls (brand, canonical_key);
+
+CREATE TABLE IF NOT EXISTS ball_aliases (
+    brand STRING NOT NULL,
+    alias STRING NOT NULL,
+    canonical STRING NOT NULL,
+    PRIMARY KEY (brand, alias)
+);
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS digest_balls (
+    digest STRING NOT NULL,
+    brand STRING NOT NULL,
+    name STRING NOT NULL,
+    approved_at TIMESTAMPTZ NOT NULL,
+    image_url STRING NOT NULL,
+    canonical_key STRING NOT NULL DEFAULT '',
+    PRIMARY KEY (digest, brand, name, approved_at)
+);
+
+COMMIT;
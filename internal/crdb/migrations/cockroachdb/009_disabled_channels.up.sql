@@ -0,0 +1,9 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS disabled_channels (
+    channel_id STRING PRIMARY KEY,
+    reason STRING NOT NULL,
+    disabled_at TIMESTAMPTZ NOT NULL
+);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS brand_roles (
+    guild_id STRING NOT NULL,
+    brand STRING NOT NULL DEFAULT '',
+    manufacturer STRING NOT NULL DEFAULT '',
+    role_id STRING NOT NULL,
+    PRIMARY KEY (guild_id, brand, manufacturer)
+);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    user_id STRING NOT NULL,
+    brand STRING NOT NULL DEFAULT '',
+    pattern STRING NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, brand, pattern)
+);
+
+COMMIT;
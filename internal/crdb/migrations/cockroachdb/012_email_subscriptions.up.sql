@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS email_subscriptions (
+    email STRING NOT NULL,
+    brand STRING NOT NULL DEFAULT '',
+    pattern STRING NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (email, brand, pattern)
+);
+
+COMMIT;
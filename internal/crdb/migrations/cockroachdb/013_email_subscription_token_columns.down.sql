@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE email_subscriptions DROP COLUMN IF EXISTS confirmed_at;
+
+ALTER TABLE email_subscriptions DROP COLUMN IF EXISTS token;
+
+COMMIT;
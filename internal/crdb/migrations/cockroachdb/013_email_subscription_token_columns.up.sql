@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE email_subscriptions ADD COLUMN IF NOT EXISTS token STRING NOT NULL DEFAULT '';
+
+ALTER TABLE email_subscriptions ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMPTZ;
+
+COMMIT;
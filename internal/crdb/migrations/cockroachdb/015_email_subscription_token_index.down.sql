@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS email_subscriptions@email_subscriptions_token CASCADE;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE balls ADD COLUMN IF NOT EXISTS notified_at TIMESTAMPTZ;
+
+ALTER TABLE balls ADD COLUMN IF NOT EXISTS notify_attempts INT NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+-- The backfilled balls are indistinguishable from balls notified of since, so they are left notified.
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Balls stored before notifications were checkpointed have already been announced.
+UPDATE balls SET notified_at = now() WHERE notified_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS manufacturer;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DELETE FROM subscriptions WHERE manufacturer != '';
+
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_pkey,
+    ADD CONSTRAINT subscriptions_pkey PRIMARY KEY (user_id, brand, pattern);
+
+COMMIT;
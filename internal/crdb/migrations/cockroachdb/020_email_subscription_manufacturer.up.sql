@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE email_subscriptions ADD COLUMN IF NOT EXISTS manufacturer STRING NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DELETE FROM email_subscriptions WHERE manufacturer != '';
+
+ALTER TABLE email_subscriptions
+    DROP CONSTRAINT email_subscriptions_pkey,
+    ADD CONSTRAINT email_subscriptions_pkey PRIMARY KEY (email, brand, pattern);
+
+COMMIT;
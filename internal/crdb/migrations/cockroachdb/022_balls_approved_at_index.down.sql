@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS balls@balls_approved_at CASCADE;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS balls_approved_at ON balls (approved_at DESC, id DESC);
+
+COMMIT;
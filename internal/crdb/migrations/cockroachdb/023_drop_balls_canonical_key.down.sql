@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE balls ADD COLUMN IF NOT EXISTS canonical_key STRING NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS balls_brand_canonical_key ON balls (brand, canonical_key);
+
+COMMIT;
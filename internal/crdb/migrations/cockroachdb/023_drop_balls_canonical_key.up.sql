@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS balls@balls_brand_canonical_key CASCADE;
+
+ALTER TABLE balls DROP COLUMN IF EXISTS canonical_key;
+
+COMMIT;
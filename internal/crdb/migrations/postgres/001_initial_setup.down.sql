@@ -0,0 +1,2 @@
+DROP TABLE balls;
+DROP SEQUENCE ball_ids;
@@ -0,0 +1,11 @@
+BEGIN;
+CREATE SCHEMA IF NOT EXISTS public;
+CREATE SEQUENCE ball_ids START 1 INCREMENT 1;
+CREATE TABLE IF NOT EXISTS balls (
+    id BIGINT PRIMARY KEY DEFAULT nextval('ball_ids'),
+    brand TEXT NOT NULL,
+    name TEXT NOT NULL,
+    image_url TEXT NOT NULL,
+    approved_at TIMESTAMPTZ NOT NULL
+);
+COMMIT;
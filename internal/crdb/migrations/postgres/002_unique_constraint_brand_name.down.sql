@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE balls
+DROP CONSTRAINT unique_brand_name;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE balls
+DROP CONSTRAINT unique_brand_name_approved_at;
+
+ALTER TABLE balls
+ADD CONSTRAINT unique_brand_name
+UNIQUE (brand, name);
+
+COMMIT;
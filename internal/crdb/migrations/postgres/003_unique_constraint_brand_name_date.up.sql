@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE balls
+DROP CONSTRAINT unique_brand_name;
+
+ALTER TABLE balls
+ADD CONSTRAINT unique_brand_name_approved_at
+UNIQUE (brand, name, approved_at);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE balls ADD COLUMN IF NOT EXISTS search_trigrams INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS ball_trigrams (
+    trigram TEXT NOT NULL,
+    ball_id BIGINT NOT NULL REFERENCES balls (id) ON DELETE CASCADE,
+    PRIMARY KEY (trigram, ball_id)
+);
+
+COMMIT;
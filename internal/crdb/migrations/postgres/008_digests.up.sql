@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS digest_balls (
+    digest TEXT NOT NULL,
+    brand TEXT NOT NULL,
+    name TEXT NOT NULL,
+    approved_at TIMESTAMPTZ NOT NULL,
+    image_url TEXT NOT NULL,
+    canonical_key TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (digest, brand, name, approved_at)
+);
+
+COMMIT;
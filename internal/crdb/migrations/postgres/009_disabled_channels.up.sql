@@ -0,0 +1,9 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS disabled_channels (
+    channel_id TEXT PRIMARY KEY,
+    reason TEXT NOT NULL,
+    disabled_at TIMESTAMPTZ NOT NULL
+);
+
+COMMIT;
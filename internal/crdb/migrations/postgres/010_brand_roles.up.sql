@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS brand_roles (
+    guild_id TEXT NOT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    manufacturer TEXT NOT NULL DEFAULT '',
+    role_id TEXT NOT NULL,
+    PRIMARY KEY (guild_id, brand, manufacturer)
+);
+
+COMMIT;
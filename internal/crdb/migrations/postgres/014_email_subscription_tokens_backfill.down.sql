@@ -0,0 +1,5 @@
+BEGIN;
+
+DELETE FROM email_subscriptions WHERE confirmed_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Subscriptions made before confirmation was required stay active, they can now be unsubscribed from by token.
+UPDATE email_subscriptions SET token = replace(gen_random_uuid()::TEXT, '-', ''), confirmed_at = created_at
+WHERE token = '';
+
+COMMIT;
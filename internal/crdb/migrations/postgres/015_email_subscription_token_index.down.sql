@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS email_subscriptions_token;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE balls DROP COLUMN IF EXISTS notify_attempts;
+
+ALTER TABLE balls DROP COLUMN IF EXISTS notified_at;
+
+COMMIT;
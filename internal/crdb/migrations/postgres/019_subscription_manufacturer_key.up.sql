@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Replacing the constraint in one statement stops the old key being kept as a unique index.
+ALTER TABLE subscriptions
+    DROP CONSTRAINT subscriptions_pkey,
+    ADD CONSTRAINT subscriptions_pkey PRIMARY KEY (user_id, brand, manufacturer, pattern);
+
+COMMIT;
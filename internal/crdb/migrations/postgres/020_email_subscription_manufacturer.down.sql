@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE email_subscriptions DROP COLUMN IF EXISTS manufacturer;
+
+COMMIT;
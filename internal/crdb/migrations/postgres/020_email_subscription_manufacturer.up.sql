@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE email_subscriptions ADD COLUMN IF NOT EXISTS manufacturer TEXT NOT NULL DEFAULT '';
+
+COMMIT;
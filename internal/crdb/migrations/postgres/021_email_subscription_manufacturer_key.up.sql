@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Replacing the constraint in one statement stops the old key being kept as a unique index.
+ALTER TABLE email_subscriptions
+    DROP CONSTRAINT email_subscriptions_pkey,
+    ADD CONSTRAINT email_subscriptions_pkey PRIMARY KEY (email, brand, manufacturer, pattern);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS balls_approved_at;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS balls_brand_canonical_key;
+
+ALTER TABLE balls DROP COLUMN IF EXISTS canonical_key;
+
+COMMIT;
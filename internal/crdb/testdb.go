@@ -16,33 +16,53 @@ import (
 func StartTestDB(tb testing.TB, logsEnabled bool) (*pgxpool.Pool, func()) {
 	tb.Helper()
 
-	crdbURL := &url.URL{
+	return StartTestDBWithDialect(tb, CockroachDB, logsEnabled)
+}
+
+// StartTestDBWithDialect starts a cockroachdb or postgres container for use in testing.
+func StartTestDBWithDialect(tb testing.TB, dialect Dialect, logsEnabled bool) (*pgxpool.Pool, func()) {
+	tb.Helper()
+
+	dbURL := &url.URL{
 		Scheme: "postgresql",
 		User:   url.User("root"),
 		Path:   "/defaultdb",
 	}
-	q := crdbURL.Query()
+	q := dbURL.Query()
 	q.Add("sslmode", "disable")
-	crdbURL.RawQuery = q.Encode()
+	dbURL.RawQuery = q.Encode()
+
+	opts := &dockertest.RunOptions{
+		Repository: "cockroachdb/cockroach",
+		Tag:        "latest",
+		Cmd:        []string{"start-single-node", "--insecure"},
+	}
+	port := "26257/tcp"
+	if dialect == Postgres {
+		dbURL.User = url.UserPassword("postgres", "postgres")
+		dbURL.Path = "/postgres"
+		opts = &dockertest.RunOptions{
+			Repository: "postgres",
+			Tag:        "16-alpine",
+			Env:        []string{"POSTGRES_PASSWORD=postgres"},
+		}
+		port = "5432/tcp"
+	}
 
 	pool, err := dockertest.NewPool("")
 	if err != nil {
 		tb.Fatalf("could not connect to docker: %v", err)
 	}
 
-	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
-		Repository: "cockroachdb/cockroach",
-		Tag:        "latest",
-		Cmd:        []string{"start-single-node", "--insecure"},
-	}, func(hc *docker.HostConfig) {
+	resource, err := pool.RunWithOptions(opts, func(hc *docker.HostConfig) {
 		hc.AutoRemove = true
 		hc.RestartPolicy = docker.NeverRestart()
 	})
 	if err != nil {
-		tb.Fatalf("could not start cockroachdb container: %v", err)
+		tb.Fatalf("could not start %s container: %v", dialect, err)
 	}
 
-	crdbURL.Host = getHostPort(resource, "26257/tcp")
+	dbURL.Host = getHostPort(resource, port)
 
 	var logWaiter docker.CloseWaiter
 	if logsEnabled {
@@ -55,18 +75,18 @@ func StartTestDB(tb testing.TB, logsEnabled bool) (*pgxpool.Pool, func()) {
 			Stream:       true,
 		})
 		if err != nil {
-			tb.Fatalf("could not connect to cockroachdb container log output: %v", err)
+			tb.Fatalf("could not connect to %s container log output: %v", dialect, err)
 		}
 	}
 
 	pool.MaxWait = 15 * time.Second
 	var db *pgxpool.Pool
 	err = pool.Retry(func() error {
-		db, err = NewDB(crdbURL.String())
+		db, err = NewDB(dbURL.String())
 		return err
 	})
 	if err != nil {
-		tb.Fatalf("could not connect to cockroachdb container: %v", err)
+		tb.Fatalf("could not connect to %s container: %v", dialect, err)
 	}
 
 	return db, func() {
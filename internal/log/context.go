@@ -0,0 +1,89 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type attrsKey struct{}
+
+type traceKey struct{}
+
+// Trace identifies the request trace and span a log line belongs to.
+type Trace struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+// ContextWithAttrs returns a copy of ctx carrying attrs which are added to every log line written with it.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := attrsFromContext(ctx)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// ContextWithTrace returns a copy of ctx carrying the given trace.
+func ContextWithTrace(ctx context.Context, trace Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// TraceFromContext returns the trace carried by ctx, if any.
+func TraceFromContext(ctx context.Context) (Trace, bool) {
+	trace, ok := ctx.Value(traceKey{}).(Trace)
+	return trace, ok
+}
+
+var (
+	// TRACE_ID/SPAN_ID;o=OPTIONS
+	cloudTraceRegexp = regexp.MustCompile(`^([a-fA-F0-9]{32})(?:/([0-9]+))?(?:;o=([01]))?$`)
+	// VERSION-TRACE_ID-SPAN_ID-FLAGS
+	traceparentRegexp = regexp.MustCompile(`^([a-f0-9]{2})-([a-f0-9]{32})-([a-f0-9]{16})-([a-f0-9]{2})$`)
+)
+
+// TraceFromRequest extracts the trace from the traceparent or X-Cloud-Trace-Context header of r.
+// The W3C traceparent header takes precedence when both are present.
+func TraceFromRequest(r *http.Request) (Trace, bool) {
+	if m := traceparentRegexp.FindStringSubmatch(strings.TrimSpace(r.Header.Get("traceparent"))); m != nil {
+		if m[2] == strings.Repeat("0", 32) || m[3] == strings.Repeat("0", 16) {
+			return Trace{}, false
+		}
+
+		flags, _ := strconv.ParseUint(m[4], 16, 8)
+
+		return Trace{
+			TraceID: m[2],
+			SpanID:  m[3],
+			Sampled: flags&1 == 1,
+		}, true
+	}
+
+	if m := cloudTraceRegexp.FindStringSubmatch(strings.TrimSpace(r.Header.Get("X-Cloud-Trace-Context"))); m != nil {
+		// The cloud trace header carries the span id in decimal, cloud logging expects it as 16 hex digits.
+		var spanID string
+		if span, err := strconv.ParseUint(m[2], 10, 64); err == nil {
+			spanID = fmt.Sprintf("%016x", span)
+		}
+
+		return Trace{
+			TraceID: strings.ToLower(m[1]),
+			SpanID:  spanID,
+			Sampled: m[3] == "1",
+		}, true
+	}
+
+	return Trace{}, false
+}
@@ -2,6 +2,7 @@ package log
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"time"
@@ -35,6 +36,7 @@ func NewLogger(out io.Writer, opts ...Option) *slog.Logger {
 	}
 
 	handler = severityHandler{Handler: handler}
+	handler = contextHandler{Handler: handler, projectID: options.projectID}
 
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
@@ -55,6 +57,50 @@ func (h severityHandler) Handle(ctx context.Context, r slog.Record) error {
 	return h.Handler.Handle(ctx, r)
 }
 
+func (h severityHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return severityHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h severityHandler) WithGroup(name string) slog.Handler {
+	return severityHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+type contextHandler struct {
+	slog.Handler
+	projectID string
+}
+
+// Handle adds the attributes and trace carried by the context to logs so that all the logs for a single request
+// or run can be filtered together in gcp.
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(attrsFromContext(ctx)...)
+
+	if trace, ok := TraceFromContext(ctx); ok {
+		traceID := trace.TraceID
+		if h.projectID != "" {
+			traceID = fmt.Sprintf("projects/%s/traces/%s", h.projectID, trace.TraceID)
+		}
+
+		r.AddAttrs(
+			slog.String("logging.googleapis.com/trace", traceID),
+			slog.Bool("logging.googleapis.com/trace_sampled", trace.Sampled),
+		)
+		if trace.SpanID != "" {
+			r.AddAttrs(slog.String("logging.googleapis.com/spanId", trace.SpanID))
+		}
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs), projectID: h.projectID}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name), projectID: h.projectID}
+}
+
 func levelToSeverity(lvl slog.Level) logging.Severity {
 	switch lvl {
 	case slog.LevelDebug:
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTraceFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    Trace
+		wantOK  bool
+	}{
+		{
+			name: "cloud trace context",
+			headers: map[string]string{
+				"X-Cloud-Trace-Context": "105445AA7843BC8BF206B12000100000/1;o=1",
+			},
+			want: Trace{
+				TraceID: "105445aa7843bc8bf206b12000100000",
+				SpanID:  "0000000000000001",
+				Sampled: true,
+			},
+			wantOK: true,
+		},
+		{
+			name: "traceparent",
+			headers: map[string]string{
+				"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			},
+			want: Trace{
+				TraceID: "0af7651916cd43dd8448eb211c80319c",
+				SpanID:  "b7ad6b7169203331",
+				Sampled: true,
+			},
+			wantOK: true,
+		},
+		{
+			name: "traceparent takes precedence",
+			headers: map[string]string{
+				"traceparent":           "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00",
+				"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000/1;o=1",
+			},
+			want: Trace{
+				TraceID: "0af7651916cd43dd8448eb211c80319c",
+				SpanID:  "b7ad6b7169203331",
+			},
+			wantOK: true,
+		},
+		{
+			name: "invalid header",
+			headers: map[string]string{
+				"traceparent": "not-a-trace",
+			},
+		},
+		{
+			name: "no headers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			got, ok := TraceFromRequest(r)
+			if ok != tt.wantOK {
+				t.Fatalf("TraceFromRequest() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			diff := cmp.Diff(got, tt.want)
+			if diff != "" {
+				t.Fatalf("(-got, +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewLogger_contextFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, WithProjectID("my-project"))
+
+	ctx := ContextWithTrace(context.Background(), Trace{
+		TraceID: "0af7651916cd43dd8448eb211c80319c",
+		SpanID:  "b7ad6b7169203331",
+		Sampled: true,
+	})
+	ctx = ContextWithAttrs(ctx, slog.String("run_id", "abc"))
+	ctx = ContextWithAttrs(ctx, slog.String("brand", "Storm"))
+
+	logger.With(slog.String("component", "test")).InfoContext(ctx, "hello")
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"logging.googleapis.com/trace":         "projects/my-project/traces/0af7651916cd43dd8448eb211c80319c",
+		"logging.googleapis.com/spanId":        "b7ad6b7169203331",
+		"logging.googleapis.com/trace_sampled": true,
+		"run_id":                               "abc",
+		"brand":                                "Storm",
+		"component":                            "test",
+		"severity":                             "Info",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("field %q = %v, want %v", k, got[k], v)
+		}
+	}
+}
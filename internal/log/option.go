@@ -7,8 +7,9 @@ type Option interface {
 }
 
 type options struct {
-	fmtLog bool
-	level  slog.Level
+	fmtLog    bool
+	level     slog.Level
+	projectID string
 }
 
 type fmtLogOption struct{}
@@ -30,3 +31,14 @@ func (o levelOption) apply(opts *options) {
 func WithLevel(lvl slog.Level) Option {
 	return levelOption(lvl)
 }
+
+type projectIDOption string
+
+func (o projectIDOption) apply(opts *options) {
+	opts.projectID = string(o)
+}
+
+// WithProjectID sets the gcp project used to qualify trace ids so cloud logging can correlate logs with traces.
+func WithProjectID(id string) Option {
+	return projectIDOption(id)
+}
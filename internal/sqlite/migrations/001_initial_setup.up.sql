@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS balls (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    brand TEXT NOT NULL,
+    name TEXT NOT NULL,
+    image_url TEXT NOT NULL,
+    approved_at TEXT NOT NULL
+);
@@ -0,0 +1 @@
+DROP INDEX unique_brand_name;
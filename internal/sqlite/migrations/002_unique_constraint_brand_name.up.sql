@@ -0,0 +1 @@
+CREATE UNIQUE INDEX unique_brand_name ON balls (brand, name);
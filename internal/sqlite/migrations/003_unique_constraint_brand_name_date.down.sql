@@ -0,0 +1,3 @@
+DROP INDEX unique_brand_name_approved_at;
+
+CREATE UNIQUE INDEX unique_brand_name ON balls (brand, name);
@@ -0,0 +1,3 @@
+DROP INDEX unique_brand_name;
+
+CREATE UNIQUE INDEX unique_brand_name_approved_at ON balls (brand, name, approved_at);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS run_lease (
+    id INTEGER PRIMARY KEY,
+    holder TEXT NOT NULL,
+    expires_at TEXT NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ball_trigrams;
+
+ALTER TABLE balls DROP COLUMN search_trigrams;
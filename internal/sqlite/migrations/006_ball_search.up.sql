@@ -0,0 +1,7 @@
+ALTER TABLE balls ADD COLUMN search_trigrams INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS ball_trigrams (
+    trigram TEXT NOT NULL,
+    ball_id INTEGER NOT NULL REFERENCES balls (id) ON DELETE CASCADE,
+    PRIMARY KEY (trigram, ball_id)
+);
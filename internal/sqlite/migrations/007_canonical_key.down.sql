@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS ball_aliases;
+
+DROP INDEX IF EXISTS balls_brand_canonical_key;
+
+ALTER TABLE balls DROP COLUMN canonical_key;
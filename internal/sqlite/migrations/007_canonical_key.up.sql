@@ -0,0 +1,10 @@
+ALTER TABLE balls ADD COLUMN canonical_key TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS balls_brand_canonical_key ON balls (brand, canonical_key);
+
+CREATE TABLE IF NOT EXISTS ball_aliases (
+    brand TEXT NOT NULL,
+    alias TEXT NOT NULL,
+    canonical TEXT NOT NULL,
+    PRIMARY KEY (brand, alias)
+);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS disabled_channels (
+    channel_id TEXT PRIMARY KEY,
+    reason TEXT NOT NULL,
+    disabled_at TEXT NOT NULL
+);
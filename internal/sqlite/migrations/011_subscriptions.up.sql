@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS subscriptions (
+    user_id TEXT NOT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    pattern TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (user_id, brand, pattern)
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS email_subscriptions (
+    email TEXT NOT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    pattern TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (email, brand, pattern)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE email_subscriptions DROP COLUMN confirmed_at;
+
+ALTER TABLE email_subscriptions DROP COLUMN token;
@@ -0,0 +1,3 @@
+ALTER TABLE email_subscriptions ADD COLUMN token TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE email_subscriptions ADD COLUMN confirmed_at TEXT;
@@ -0,0 +1 @@
+DELETE FROM email_subscriptions WHERE confirmed_at IS NULL;
@@ -0,0 +1,2 @@
+-- Subscriptions made before confirmation was required stay active, they can now be unsubscribed from by token.
+UPDATE email_subscriptions SET token = lower(hex(randomblob(16))), confirmed_at = created_at WHERE token = '';
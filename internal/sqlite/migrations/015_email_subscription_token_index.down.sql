@@ -0,0 +1 @@
+DROP INDEX IF EXISTS email_subscriptions_token;
@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS email_subscriptions_token ON email_subscriptions (token);
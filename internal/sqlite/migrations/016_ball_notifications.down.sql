@@ -0,0 +1,3 @@
+ALTER TABLE balls DROP COLUMN notify_attempts;
+
+ALTER TABLE balls DROP COLUMN notified_at;
@@ -0,0 +1,3 @@
+ALTER TABLE balls ADD COLUMN notified_at TEXT;
+
+ALTER TABLE balls ADD COLUMN notify_attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+-- The backfilled balls are indistinguishable from balls notified of since, so they are left notified.
@@ -0,0 +1,2 @@
+-- Balls stored before notifications were checkpointed have already been announced.
+UPDATE balls SET notified_at = strftime('%Y-%m-%dT%H:%M:%S.000000Z', 'now') WHERE notified_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE subscriptions ADD COLUMN manufacturer TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+CREATE TABLE subscriptions_old (
+    user_id TEXT NOT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    manufacturer TEXT NOT NULL DEFAULT '',
+    pattern TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (user_id, brand, pattern)
+);
+
+INSERT INTO subscriptions_old (user_id, brand, manufacturer, pattern, created_at)
+SELECT user_id, brand, manufacturer, pattern, created_at FROM subscriptions WHERE manufacturer = '';
+
+DROP TABLE subscriptions;
+
+ALTER TABLE subscriptions_old RENAME TO subscriptions;
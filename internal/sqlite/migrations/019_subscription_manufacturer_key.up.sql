@@ -0,0 +1,16 @@
+-- SQLite can't change a primary key, so the table is rebuilt with the new one.
+CREATE TABLE subscriptions_new (
+    user_id TEXT NOT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    manufacturer TEXT NOT NULL DEFAULT '',
+    pattern TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (user_id, brand, manufacturer, pattern)
+);
+
+INSERT INTO subscriptions_new (user_id, brand, manufacturer, pattern, created_at)
+SELECT user_id, brand, manufacturer, pattern, created_at FROM subscriptions;
+
+DROP TABLE subscriptions;
+
+ALTER TABLE subscriptions_new RENAME TO subscriptions;
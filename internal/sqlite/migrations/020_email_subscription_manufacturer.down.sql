@@ -0,0 +1 @@
+ALTER TABLE email_subscriptions DROP COLUMN manufacturer;
@@ -0,0 +1 @@
+ALTER TABLE email_subscriptions ADD COLUMN manufacturer TEXT NOT NULL DEFAULT '';
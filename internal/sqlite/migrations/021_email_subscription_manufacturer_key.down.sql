@@ -0,0 +1,20 @@
+CREATE TABLE email_subscriptions_old (
+    email TEXT NOT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    manufacturer TEXT NOT NULL DEFAULT '',
+    pattern TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    token TEXT NOT NULL DEFAULT '',
+    confirmed_at TEXT,
+    PRIMARY KEY (email, brand, pattern)
+);
+
+INSERT INTO email_subscriptions_old (email, brand, manufacturer, pattern, created_at, token, confirmed_at)
+SELECT email, brand, manufacturer, pattern, created_at, token, confirmed_at FROM email_subscriptions
+WHERE manufacturer = '';
+
+DROP TABLE email_subscriptions;
+
+ALTER TABLE email_subscriptions_old RENAME TO email_subscriptions;
+
+CREATE UNIQUE INDEX IF NOT EXISTS email_subscriptions_token ON email_subscriptions (token);
@@ -0,0 +1,20 @@
+-- SQLite can't change a primary key, so the table is rebuilt with the new one.
+CREATE TABLE email_subscriptions_new (
+    email TEXT NOT NULL,
+    brand TEXT NOT NULL DEFAULT '',
+    manufacturer TEXT NOT NULL DEFAULT '',
+    pattern TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    token TEXT NOT NULL DEFAULT '',
+    confirmed_at TEXT,
+    PRIMARY KEY (email, brand, manufacturer, pattern)
+);
+
+INSERT INTO email_subscriptions_new (email, brand, manufacturer, pattern, created_at, token, confirmed_at)
+SELECT email, brand, manufacturer, pattern, created_at, token, confirmed_at FROM email_subscriptions;
+
+DROP TABLE email_subscriptions;
+
+ALTER TABLE email_subscriptions_new RENAME TO email_subscriptions;
+
+CREATE UNIQUE INDEX IF NOT EXISTS email_subscriptions_token ON email_subscriptions (token);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS balls_approved_at;
@@ -0,0 +1,3 @@
+ALTER TABLE balls ADD COLUMN canonical_key TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS balls_brand_canonical_key ON balls (brand, canonical_key);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS balls_brand_canonical_key;
+
+ALTER TABLE balls DROP COLUMN canonical_key;
@@ -0,0 +1,143 @@
+// Package sqlite provides a sqlite database for running the service as a single binary without cockroachdb.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/pkg/errors"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// MigrationVersion is the schema version expected by this build.
+const MigrationVersion = 23
+
+// Scheme is the url scheme of sqlite dsns, e.g. sqlite://abl.db.
+const Scheme = "sqlite"
+
+// IsDSN reports whether dsn refers to a sqlite database.
+func IsDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, Scheme+":")
+}
+
+// Open returns a new sqlite database without applying any migrations. The dsn is the path to the database file
+// prefixed with sqlite:// or sqlite:, optionally followed by query parameters understood by the driver.
+func Open(dsn string) (*sql.DB, error) {
+	if !IsDSN(dsn) {
+		return nil, fmt.Errorf("not a sqlite dsn: %s", dsn)
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(dsn, Scheme+"://"), Scheme+":")
+
+	db, err := sql.Open("sqlite", name)
+	if err != nil {
+		return nil, fmt.Errorf("sql.Open: %w", err)
+	}
+	// sqlite only allows a single writer, and every connection to an in memory database is a separate database.
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("pinging database: %w", err)
+	}
+
+	for _, pragma := range []string{"PRAGMA foreign_keys = ON", "PRAGMA busy_timeout = 5000"} {
+		if _, err = db.ExecContext(ctx, pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("setting %s: %w", pragma, err)
+		}
+	}
+
+	return db, nil
+}
+
+// NewDB returns a new sqlite database with the migrations applied, see Open for the format of dsn.
+func NewDB(dsn string) (*sql.DB, error) {
+	db, err := Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = runMigrations(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("running migrations: %w", err)
+	}
+
+	return db, nil
+}
+
+func runMigrations(db *sql.DB) error {
+	m, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	err = m.Migrate(MigrationVersion)
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
+// MigrationSource returns the embedded migrations.
+func MigrationSource() (source.Driver, error) {
+	src, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return nil, errors.Wrap(err, "iofs.New")
+	}
+
+	return src, nil
+}
+
+// NewMigrator returns a migrate instance applying the embedded migrations to db. Closing it closes db.
+func NewMigrator(db *sql.DB) (*migrate.Migrate, error) {
+	src, err := MigrationSource()
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlite.WithInstance")
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "sqlite", driver)
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate.NewWithInstance")
+	}
+
+	return m, nil
+}
+
+// VerifyMigrations checks that the database schema is at the version expected by this build and that the last
+// migration didn't fail part way through.
+func VerifyMigrations(ctx context.Context, db *sql.DB) error {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("schema version %d is dirty", version)
+	}
+
+	if version != MigrationVersion {
+		return fmt.Errorf("schema version is %d, expected %d", version, MigrationVersion)
+	}
+
+	return nil
+}
@@ -24,6 +24,10 @@ resource "google_cloud_run_service" "abl" {
           name  = "COCKROACHDB_URL"
           value = var.cockroachdb_url
         }
+        env {
+          name  = "GOOGLE_CLOUD_PROJECT"
+          value = var.project
+        }
       }
     }
   }